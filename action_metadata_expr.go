@@ -0,0 +1,219 @@
+package actionlint
+
+import (
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ActionMetadataKeyAvailability returns contexts and special functions availability of the given
+// key of action metadata like "runs.steps.if". The return values are in the same format as
+// WorkflowKeyAvailability. Unlike workflows, contexts depending on the workflow like "matrix",
+// "needs", and "secrets" are not available in action metadata. Values of them must be passed to
+// the action via inputs. When the key is unknown, this function returns nil for both.
+// https://docs.github.com/en/actions/creating-actions/metadata-syntax-for-github-actions
+func ActionMetadataKeyAvailability(key string) ([]string, []string) {
+	switch key {
+	case "inputs.<input_id>.default":
+		return []string{"github"}, []string{}
+	case "outputs.<output_id>.value":
+		return []string{"env", "github", "inputs", "job", "runner", "steps", "strategy"}, []string{}
+	case "runs.steps.continue-on-error", "runs.steps.env", "runs.steps.name", "runs.steps.run", "runs.steps.with", "runs.steps.working-directory":
+		return []string{"env", "github", "inputs", "job", "runner", "steps", "strategy"}, []string{"hashfiles"}
+	case "runs.steps.if":
+		return []string{"env", "github", "inputs", "job", "runner", "steps", "strategy"}, []string{"always", "cancelled", "failure", "hashfiles", "success"}
+	default:
+		return nil, nil
+	}
+}
+
+// actionMetadataExprChecker checks expressions in action metadata in the same way as "expression"
+// rule does for workflows. Contexts available in each key are determined by
+// ActionMetadataKeyAvailability.
+type actionMetadataExprChecker struct {
+	inputs *ObjectType
+	steps  *ObjectType
+	errs   []*Error
+}
+
+// checkActionMetadataExprs checks expressions in "inputs.<input_id>.default",
+// "outputs.<output_id>.value", and each step of composite action. The root parameter must be a
+// mapping node of the action metadata.
+func checkActionMetadataExprs(root *yaml.Node) []*Error {
+	var inputs, outputs, runs *yaml.Node
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		switch k, v := root.Content[i], root.Content[i+1]; k.Value {
+		case "inputs":
+			inputs = v
+		case "outputs":
+			outputs = v
+		case "runs":
+			runs = v
+		}
+	}
+
+	c := &actionMetadataExprChecker{
+		inputs: NewEmptyStrictObjectType(),
+		steps:  NewEmptyStrictObjectType(),
+		errs:   []*Error{},
+	}
+
+	if inputs != nil && inputs.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(inputs.Content); i += 2 {
+			// Input names are case insensitive
+			c.inputs.Props[strings.ToLower(inputs.Content[i].Value)] = StringType{}
+			c.check(mappingValueOf(inputs.Content[i+1], "default"), "inputs.<input_id>.default")
+		}
+	} else {
+		c.inputs = NewEmptyObjectType()
+	}
+
+	if runs != nil {
+		if steps := mappingValueOf(runs, "steps"); steps != nil && steps.Kind == yaml.SequenceNode {
+			for _, s := range steps.Content {
+				c.checkStep(s)
+			}
+		}
+	}
+
+	if outputs != nil && outputs.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(outputs.Content); i += 2 {
+			c.check(mappingValueOf(outputs.Content[i+1], "value"), "outputs.<output_id>.value")
+		}
+	}
+
+	return c.errs
+}
+
+func (c *actionMetadataExprChecker) checkStep(step *yaml.Node) {
+	if step.Kind != yaml.MappingNode {
+		return
+	}
+
+	var id *yaml.Node
+	for i := 0; i+1 < len(step.Content); i += 2 {
+		k, v := step.Content[i], step.Content[i+1]
+		switch k.Value {
+		case "id":
+			id = v
+		case "if":
+			c.checkIf(v)
+		case "continue-on-error", "name", "run", "working-directory":
+			c.check(v, "runs.steps."+k.Value)
+		case "env", "with":
+			if v.Kind == yaml.MappingNode {
+				for i := 1; i < len(v.Content); i += 2 {
+					c.check(v.Content[i], "runs.steps."+k.Value)
+				}
+			}
+		}
+	}
+
+	// Outputs of the step are available in the following steps
+	if id == nil || id.Kind != yaml.ScalarNode {
+		return
+	}
+	if strings.Contains(id.Value, "${{") {
+		c.steps.Loose()
+		return
+	}
+	// Step ID is case insensitive. Outputs of actions run by "uses" cannot be known here
+	c.steps.Props[strings.ToLower(id.Value)] = NewStrictObjectType(map[string]ExprType{
+		"outputs":    NewMapObjectType(StringType{}),
+		"conclusion": StringType{},
+		"outcome":    StringType{},
+	})
+}
+
+// checkIf checks the "if" condition of the step. The condition is evaluated as an expression even
+// if it is not enclosed with ${{ }} in the same way as workflows.
+func (c *actionMetadataExprChecker) checkIf(n *yaml.Node) {
+	if n.Kind != yaml.ScalarNode || strings.Contains(n.Value, "${{") {
+		c.check(n, "runs.steps.if")
+		return
+	}
+	col := n.Column
+	if n.Style&(yaml.SingleQuotedStyle|yaml.DoubleQuotedStyle) != 0 {
+		col++
+	}
+	c.checkExpr(n.Value+"}}", n.Line, col, "runs.steps.if") // }} is necessary since lexer lexes it as end of tokens
+}
+
+func (c *actionMetadataExprChecker) check(n *yaml.Node, key string) {
+	if n == nil || n.Kind != yaml.ScalarNode {
+		return
+	}
+
+	s := n.Value
+	line, col := n.Line, n.Column
+	if n.Style&(yaml.SingleQuotedStyle|yaml.DoubleQuotedStyle) != 0 {
+		col++ // when the string is quoted like 'foo' or "foo", column should be incremented
+	}
+	offset := 0
+	for {
+		idx := strings.Index(s, "${{")
+		if idx == -1 {
+			return
+		}
+		start := idx + 3 // 3 means removing "${{"
+		s = s[start:]
+		offset += start
+
+		end, ok := c.checkExpr(s, line, col+offset, key)
+		if !ok || end == 0 {
+			return
+		}
+		s = s[end:]
+		offset += end
+	}
+}
+
+// checkExpr checks one expression at the head of the given source. The source must not contain the
+// leading "${{". It returns the offset after the expression and whether the expression was parsed
+// successfully.
+func (c *actionMetadataExprChecker) checkExpr(src string, line, col int, key string) (int, bool) {
+	e := parseExprSource(src)
+	if e.err != nil {
+		c.errorAt(e.err, line, col)
+		return e.offset, false
+	}
+
+	sema := NewExprSemanticsChecker(false)
+	sema.UpdateInputs(c.inputs)
+	sema.UpdateSteps(c.steps)
+	// "matrix" context is not available. Make it loose not to report its properties in addition
+	// to the error of context availability
+	sema.UpdateMatrix(NewEmptyObjectType())
+	ctx, sp := ActionMetadataKeyAvailability(key)
+	sema.SetContextAvailability(ctx)
+	sema.SetSpecialFunctionAvailability(sp)
+
+	_, errs := sema.Check(e.node)
+	for _, err := range errs {
+		c.errorAt(err, line, col)
+	}
+	return e.offset, true
+}
+
+func (c *actionMetadataExprChecker) errorAt(err *ExprError, line, col int) {
+	p := convertExprLineColToPos(err.Line, err.Column, line, col)
+	c.errs = append(c.errs, &Error{
+		Message:  err.Message,
+		Line:     p.Line,
+		Column:   p.Col,
+		Kind:     "expression",
+		Severity: SeverityError,
+	})
+}
+
+func mappingValueOf(n *yaml.Node, key string) *yaml.Node {
+	if n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package actionlint
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// actionMetadataRunsKeys is a map from values of "runs.using" to the key required in "runs" section.
+// https://docs.github.com/en/actions/creating-actions/metadata-syntax-for-github-actions#runs
+var actionMetadataRunsKeys = map[string]string{
+	"composite": "steps",
+	"docker":    "image",
+	"node12":    "main",
+	"node16":    "main",
+	"node20":    "main",
+}
+
+var actionMetadataKeys = []string{"author", "branding", "description", "inputs", "name", "outputs", "runs"}
+
+// actionMetadataStepKeys is keys available in each step of composite action. Some keys of steps in
+// workflows like "timeout-minutes" are not supported.
+// https://docs.github.com/en/actions/creating-actions/metadata-syntax-for-github-actions#runssteps
+var actionMetadataStepKeys = []string{"continue-on-error", "env", "id", "if", "name", "run", "shell", "uses", "with", "working-directory"}
+
+// lintActionMetadata checks the structure of action metadata file (action.yml). Unlike workflow
+// files, only keys at top level and "runs" section are checked.
+// https://docs.github.com/en/actions/creating-actions/metadata-syntax-for-github-actions
+func lintActionMetadata(src []byte) []*Error {
+	var n yaml.Node
+	if err := yaml.Unmarshal(src, &n); err != nil {
+		return handleYAMLError(err)
+	}
+
+	errs := []*Error{}
+	errorf := func(n *yaml.Node, format string, args ...interface{}) {
+		errs = append(errs, &Error{fmt.Sprintf(format, args...), "", n.Line, n.Column, "action-metadata", SeverityError, nil})
+	}
+
+	if len(n.Content) == 0 {
+		errs = append(errs, &Error{"action metadata is empty", "", 1, 1, "action-metadata", SeverityError, nil})
+		return errs
+	}
+	root := n.Content[0]
+	if root.Kind != yaml.MappingNode {
+		errorf(root, "action metadata must be mapping but got %s node", nodeKindName(root.Kind))
+		return errs
+	}
+
+	found := map[string]struct{}{}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		k, v := root.Content[i], root.Content[i+1]
+		found[k.Value] = struct{}{}
+		switch k.Value {
+		case "runs":
+			checkActionMetadataRuns(k, v, errorf)
+		case "author", "branding", "description", "inputs", "name", "outputs":
+		default:
+			errorf(k, "unexpected key %q for action metadata. expected one of %s", k.Value, sortedQuotes(actionMetadataKeys))
+		}
+	}
+
+	for _, k := range []string{"name", "description", "runs"} {
+		if _, ok := found[k]; !ok {
+			errorf(root, "%q section is missing in action metadata", k)
+		}
+	}
+
+	errs = append(errs, checkActionMetadataExprs(root)...)
+
+	return errs
+}
+
+func checkActionMetadataRuns(k, v *yaml.Node, errorf func(*yaml.Node, string, ...interface{})) {
+	if v.Kind != yaml.MappingNode {
+		errorf(v, "\"runs\" section must be mapping but got %s node", nodeKindName(v.Kind))
+		return
+	}
+
+	var using, steps *yaml.Node
+	keys := map[string]struct{}{}
+	for i := 0; i+1 < len(v.Content); i += 2 {
+		keys[v.Content[i].Value] = struct{}{}
+		switch v.Content[i].Value {
+		case "using":
+			using = v.Content[i+1]
+		case "steps":
+			steps = v.Content[i+1]
+		}
+	}
+
+	if using == nil {
+		errorf(k, "\"using\" is missing in \"runs\" section")
+		return
+	}
+	if strings.Contains(using.Value, "${{") {
+		return
+	}
+
+	req, ok := actionMetadataRunsKeys[strings.ToLower(using.Value)]
+	if !ok {
+		ns := make([]string, 0, len(actionMetadataRunsKeys))
+		for n := range actionMetadataRunsKeys {
+			ns = append(ns, n)
+		}
+		errorf(using, "unknown value %q at \"runs.using\". expected one of %s", using.Value, sortedQuotes(ns))
+		return
+	}
+	if _, ok := keys[req]; !ok {
+		errorf(k, "%q is required in \"runs\" section when \"using\" is %q", req, using.Value)
+	}
+	if steps != nil && req == "steps" {
+		checkActionMetadataSteps(steps, errorf)
+	}
+}
+
+// checkActionMetadataSteps checks that each step of composite action only has keys available for
+// composite action and runs either an action with "uses" or a shell command with "run" in the same
+// way as steps in workflows. "shell" and "working-directory" are only available with "run" since
+// they have no effect on running action. "shell" is required with "run" since composite actions
+// have no default shell.
+// https://docs.github.com/en/actions/creating-actions/metadata-syntax-for-github-actions#runsstepsrun
+func checkActionMetadataSteps(steps *yaml.Node, errorf func(*yaml.Node, string, ...interface{})) {
+	if steps.Kind != yaml.SequenceNode {
+		return
+	}
+	for _, step := range steps.Content {
+		if step.Kind != yaml.MappingNode {
+			continue
+		}
+		var uses, run, shell *yaml.Node
+		runOnly := []*yaml.Node{}
+		for i := 0; i+1 < len(step.Content); i += 2 {
+			switch k := step.Content[i]; k.Value {
+			case "uses":
+				uses = k
+			case "run":
+				run = k
+			case "shell", "working-directory":
+				if k.Value == "shell" {
+					shell = k
+				}
+				runOnly = append(runOnly, k)
+			case "continue-on-error", "env", "id", "if", "name", "with":
+			default:
+				errorf(k, "unexpected key %q for step of composite action. expected one of %s", k.Value, sortedQuotes(actionMetadataStepKeys))
+			}
+		}
+		switch {
+		case uses != nil && run != nil:
+			if run.Line < uses.Line || run.Line == uses.Line && run.Column < uses.Column {
+				errorf(uses, "this step is for running shell command since it contains \"run\" key, but also contains \"uses\" key which is used for running action. \"run\" key is defined at line:%d,col:%d", run.Line, run.Column)
+			} else {
+				errorf(run, "this step is for running action since it contains \"uses\" key, but also contains \"run\" key which is used for running shell command. \"uses\" key is defined at line:%d,col:%d", uses.Line, uses.Column)
+			}
+		case uses == nil && run == nil:
+			errorf(step, "step must run script with \"run\" section or run action with \"uses\" section")
+		case uses != nil:
+			for _, k := range runOnly {
+				errorf(k, "%q is not available with \"uses\". it is only available with \"run\"", k.Value)
+			}
+		case shell == nil:
+			errorf(step, "\"shell\" is required for step running script with \"run\" in composite action. unlike workflows, composite actions have no default shell")
+		}
+	}
+}
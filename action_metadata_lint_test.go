@@ -0,0 +1,187 @@
+package actionlint
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLintActionMetadata(t *testing.T) {
+	testCases := []struct {
+		what  string
+		input string
+		want  []string
+	}{
+		{
+			what:  "node action",
+			input: "name: foo\ndescription: bar\nruns:\n  using: node20\n  main: index.js\n",
+		},
+		{
+			what:  "docker action",
+			input: "name: foo\ndescription: bar\nruns:\n  using: docker\n  image: Dockerfile\n",
+		},
+		{
+			what:  "composite action with all keys",
+			input: "name: foo\nauthor: me\ndescription: bar\ninputs:\n  x:\n    description: x\noutputs:\n  y:\n    description: y\nbranding:\n  icon: check\nruns:\n  using: composite\n  steps:\n    - run: echo\n      shell: bash\n",
+		},
+		{
+			what:  "using in upper case",
+			input: "name: foo\ndescription: bar\nruns:\n  using: Node16\n  main: index.js\n",
+		},
+		{
+			what:  "missing required keys",
+			input: "author: me\n",
+			want: []string{
+				`1:1: "name" section is missing in action metadata`,
+				`1:1: "description" section is missing in action metadata`,
+				`1:1: "runs" section is missing in action metadata`,
+			},
+		},
+		{
+			what:  "unexpected key",
+			input: "name: foo\ndescription: bar\nrun:\n  using: node20\n",
+			want: []string{
+				`3:1: unexpected key "run" for action metadata. expected one of "author", "branding", "description", "inputs", "name", "outputs", "runs"`,
+				`1:1: "runs" section is missing in action metadata`,
+			},
+		},
+		{
+			what:  "missing using",
+			input: "name: foo\ndescription: bar\nruns:\n  main: index.js\n",
+			want:  []string{`3:1: "using" is missing in "runs" section`},
+		},
+		{
+			what:  "unknown using",
+			input: "name: foo\ndescription: bar\nruns:\n  using: node8\n  main: index.js\n",
+			want:  []string{`4:10: unknown value "node8" at "runs.using". expected one of "composite", "docker", "node12", "node16", "node20"`},
+		},
+		{
+			what:  "missing main",
+			input: "name: foo\ndescription: bar\nruns:\n  using: node20\n",
+			want:  []string{`3:1: "main" is required in "runs" section when "using" is "node20"`},
+		},
+		{
+			what:  "missing steps",
+			input: "name: foo\ndescription: bar\nruns:\n  using: composite\n",
+			want:  []string{`3:1: "steps" is required in "runs" section when "using" is "composite"`},
+		},
+		{
+			what:  "composite action steps",
+			input: "name: foo\ndescription: bar\nruns:\n  using: composite\n  steps:\n    - run: echo\n      shell: bash\n    - uses: actions/checkout@v4\n      with:\n        path: foo\n",
+		},
+		{
+			what:  "composite action step with both uses and run",
+			input: "name: foo\ndescription: bar\nruns:\n  using: composite\n  steps:\n    - uses: actions/checkout@v4\n      run: echo\n    - run: echo\n      shell: bash\n      uses: actions/checkout@v4\n",
+			want: []string{
+				`7:7: this step is for running action since it contains "uses" key, but also contains "run" key which is used for running shell command. "uses" key is defined at line:6,col:7`,
+				`10:7: this step is for running shell command since it contains "run" key, but also contains "uses" key which is used for running action. "run" key is defined at line:8,col:7`,
+			},
+		},
+		{
+			what:  "composite action step with shell and working-directory",
+			input: "name: foo\ndescription: bar\nruns:\n  using: composite\n  steps:\n    - run: echo\n      shell: bash\n      working-directory: ./foo\n",
+		},
+		{
+			what:  "composite action step with uses and shell or working-directory",
+			input: "name: foo\ndescription: bar\nruns:\n  using: composite\n  steps:\n    - uses: actions/checkout@v4\n      working-directory: ./foo\n    - shell: bash\n      uses: actions/checkout@v4\n",
+			want: []string{
+				`7:7: "working-directory" is not available with "uses". it is only available with "run"`,
+				`8:7: "shell" is not available with "uses". it is only available with "run"`,
+			},
+		},
+		{
+			what:  "composite action step with all available keys",
+			input: "name: foo\ndescription: bar\nruns:\n  using: composite\n  steps:\n    - id: x\n      name: x\n      if: true\n      run: echo\n      shell: bash\n      working-directory: ./foo\n      env:\n        FOO: foo\n      continue-on-error: true\n    - uses: actions/checkout@v4\n      with:\n        path: foo\n",
+		},
+		{
+			what:  "composite action step with unsupported keys",
+			input: "name: foo\ndescription: bar\nruns:\n  using: composite\n  steps:\n    - run: echo\n      shell: bash\n      timeout-minutes: 10\n    - uses: actions/checkout@v4\n      runs-on: ubuntu-latest\n",
+			want: []string{
+				`8:7: unexpected key "timeout-minutes" for step of composite action. expected one of "continue-on-error", "env", "id", "if", "name", "run", "shell", "uses", "with", "working-directory"`,
+				`10:7: unexpected key "runs-on" for step of composite action. expected one of "continue-on-error", "env", "id", "if", "name", "run", "shell", "uses", "with", "working-directory"`,
+			},
+		},
+		{
+			what:  "composite action run step with shell",
+			input: "name: foo\ndescription: bar\nruns:\n  using: composite\n  steps:\n    - run: echo\n      shell: bash\n",
+		},
+		{
+			what:  "composite action run step without shell",
+			input: "name: foo\ndescription: bar\nruns:\n  using: composite\n  steps:\n    - run: echo\n      shell: bash\n    - name: Hello\n      run: echo hello\n",
+			want:  []string{`8:7: "shell" is required for step running script with "run" in composite action`},
+		},
+		{
+			what:  "composite action step with neither uses nor run",
+			input: "name: foo\ndescription: bar\nruns:\n  using: composite\n  steps:\n    - name: noop\n      shell: bash\n",
+			want:  []string{`6:7: step must run script with "run" section or run action with "uses" section`},
+		},
+		{
+			what:  "expressions in composite action",
+			input: "name: foo\ndescription: bar\ninputs:\n  Message:\n    description: x\n    default: ${{ github.actor }}\noutputs:\n  y:\n    description: y\n    value: ${{ steps.hello.outputs.greeting }}\nruns:\n  using: composite\n  steps:\n    - id: hello\n      if: inputs.message != '' && success()\n      run: echo \"greeting=${{ inputs.message }}\" >> \"$GITHUB_OUTPUT\"\n      shell: bash\n      env:\n        SHA: ${{ github.sha }}\n    - run: echo ${{ steps.hello.outputs.greeting }} ${{ runner.os }}\n      shell: bash\n",
+		},
+		{
+			what:  "context not available in action metadata",
+			input: "name: foo\ndescription: bar\nruns:\n  using: composite\n  steps:\n    - run: echo ${{ matrix.os }} ${{ secrets.TOKEN }}\n      shell: bash\n",
+			want: []string{
+				`6:21: context "matrix" is not allowed here`,
+				`6:38: context "secrets" is not allowed here`,
+			},
+		},
+		{
+			what:  "context not available in default value of input",
+			input: "name: foo\ndescription: bar\ninputs:\n  x:\n    description: x\n    default: ${{ runner.os }}\nruns:\n  using: node20\n  main: index.js\n",
+			want:  []string{`6:18: context "runner" is not allowed here`},
+		},
+		{
+			what:  "undefined input in composite action",
+			input: "name: foo\ndescription: bar\ninputs:\n  x:\n    description: x\nruns:\n  using: composite\n  steps:\n    - run: echo ${{ inputs.y }}\n      shell: bash\n",
+			want:  []string{`9:21: property "y" is not defined in object type {x: string}`},
+		},
+		{
+			what:  "step outputs referred before the step",
+			input: "name: foo\ndescription: bar\nruns:\n  using: composite\n  steps:\n    - run: echo ${{ steps.later.outputs.x }}\n      shell: bash\n    - id: later\n      run: echo\n      shell: bash\n",
+			want:  []string{`6:21: property "later" is not defined in object type {}`},
+		},
+		{
+			what:  "special function not available in step run",
+			input: "name: foo\ndescription: bar\nruns:\n  using: composite\n  steps:\n    - run: echo ${{ always() }}\n      shell: bash\n",
+			want:  []string{`6:21: calling function "always" is not allowed here`},
+		},
+		{
+			what:  "runs is not a mapping",
+			input: "name: foo\ndescription: bar\nruns: node20\n",
+			want:  []string{`3:7: "runs" section must be mapping but got scalar node`},
+		},
+		{
+			what:  "not a mapping",
+			input: "- foo\n",
+			want:  []string{`1:1: action metadata must be mapping but got sequence node`},
+		},
+		{
+			what:  "empty",
+			input: "",
+			want:  []string{`1:1: action metadata is empty`},
+		},
+		{
+			what:  "broken YAML",
+			input: "name: [\n",
+			want:  []string{`could not parse as YAML`},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			errs := lintActionMetadata([]byte(tc.input))
+			if len(errs) != len(tc.want) {
+				t.Fatalf("wanted %d errors but got %d errors: %v", len(tc.want), len(errs), errs)
+			}
+			for i, err := range errs {
+				if !strings.Contains(err.Error(), tc.want[i]) {
+					t.Errorf("error %q does not contain %q", err.Error(), tc.want[i])
+				}
+				if err.Kind != "action-metadata" && err.Kind != "yaml-syntax" && err.Kind != "expression" {
+					t.Errorf("unexpected kind of error: %s", err)
+				}
+			}
+		})
+	}
+}
@@ -31,6 +31,15 @@ func (p *Pos) IsBefore(other *Pos) bool {
 	return p.Col < other.Col
 }
 
+// ComparePos compares two positions. It returns a negative integer when a is before b, a positive
+// integer when a is after b, and zero when they are equal.
+func ComparePos(a, b *Pos) int {
+	if a.Line != b.Line {
+		return a.Line - b.Line
+	}
+	return a.Col - b.Col
+}
+
 // String represents generic string value in YAML file with position.
 type String struct {
 	// Value is a raw value of the string.
@@ -41,6 +50,42 @@ type String struct {
 	Pos *Pos
 }
 
+// linePos returns the position of the i-th line of the string in the source. The lines parameter is
+// the source split into lines and the line parameter is the i-th line of the string value. The
+// leading indentation of the line is skipped. Only lines in a plain scalar or a literal block scalar
+// can be located. When the position is not known, this method returns nil.
+func (s *String) linePos(lines []string, i int, line string) *Pos {
+	if s.Quoted || s.Pos.Line < 1 || s.Pos.Line > len(lines) {
+		return nil
+	}
+
+	l := s.Pos.Line
+	head := lines[l-1]
+	if len(head) < s.Pos.Col {
+		return nil
+	}
+	switch head[s.Pos.Col-1] {
+	case '|':
+		l += i + 1 // Value starts at the next line of the block header
+	case '>':
+		return nil // Lines are folded
+	default:
+		if i > 0 {
+			return nil
+		}
+	}
+	if l > len(lines) {
+		return nil
+	}
+
+	start := strings.Index(strings.TrimRight(lines[l-1], "\r"), line)
+	if start < 0 {
+		return nil
+	}
+	indent := len(line) - len(strings.TrimLeft(line, " \t"))
+	return &Pos{Line: l, Col: start + indent + 1}
+}
+
 // Bool represents generic boolean value in YAML file with position.
 type Bool struct {
 	// Value is a raw value of the bool string.
@@ -164,6 +209,8 @@ const (
 	WorkflowDispatchEventInputTypeChoice
 	// WorkflowDispatchEventInputTypeEnvironment is environment type of input of workflow_dispatch event.
 	WorkflowDispatchEventInputTypeEnvironment
+	// WorkflowDispatchEventInputTypeNumber is number type of input of workflow_dispatch event.
+	WorkflowDispatchEventInputTypeNumber
 )
 
 // DispatchInput is input specified on dispatching workflow manually.
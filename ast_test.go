@@ -0,0 +1,32 @@
+package actionlint
+
+import (
+	"testing"
+)
+
+func TestComparePos(t *testing.T) {
+	testCases := []struct {
+		what string
+		a    *Pos
+		b    *Pos
+		want int
+	}{
+		{"same position", &Pos{3, 5}, &Pos{3, 5}, 0},
+		{"same line, column is before", &Pos{3, 4}, &Pos{3, 5}, -1},
+		{"same line, column is after", &Pos{3, 6}, &Pos{3, 5}, 1},
+		{"line is before", &Pos{2, 10}, &Pos{3, 1}, -1},
+		{"line is after", &Pos{4, 1}, &Pos{3, 10}, 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			have := ComparePos(tc.a, tc.b)
+			if (tc.want < 0 && have >= 0) || (tc.want > 0 && have <= 0) || (tc.want == 0 && have != 0) {
+				t.Fatalf("ComparePos(%s, %s) should be %d but got %d", tc.a, tc.b, tc.want, have)
+			}
+			if (have < 0) != tc.a.IsBefore(tc.b) {
+				t.Fatalf("ComparePos(%s, %s) returned %d but IsBefore() returned %v", tc.a, tc.b, have, tc.a.IsBefore(tc.b))
+			}
+		})
+	}
+}
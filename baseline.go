@@ -0,0 +1,91 @@
+package actionlint
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+var reFingerprint = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// baselineEntry is a line of baseline file. Fingerprint is empty when the line is a comment or an
+// empty line.
+type baselineEntry struct {
+	fingerprint string
+	line        string
+}
+
+// baseline is a set of errors accepted intentionally. Errors in the baseline are not reported.
+// Each line of baseline file starts with a fingerprint of error (see Error.Fingerprint). Texts
+// after the fingerprint are annotations and lines starting with '#' are comments. They are
+// preserved when the baseline is updated.
+type baseline struct {
+	entries      []baselineEntry
+	fingerprints map[string]struct{}
+}
+
+func newBaseline() *baseline {
+	return &baseline{fingerprints: map[string]struct{}{}}
+}
+
+func parseBaseline(b []byte, path string) (*baseline, error) {
+	ret := newBaseline()
+	s := strings.TrimSuffix(strings.ReplaceAll(string(b), "\r\n", "\n"), "\n")
+	if s == "" {
+		return ret, nil
+	}
+	for i, l := range strings.Split(s, "\n") {
+		t := strings.TrimSpace(l)
+		if t == "" || strings.HasPrefix(t, "#") {
+			ret.entries = append(ret.entries, baselineEntry{"", l})
+			continue
+		}
+		f := strings.Fields(t)[0]
+		if !reFingerprint.MatchString(f) {
+			return nil, fmt.Errorf("invalid baseline file %q: line %d does not start with fingerprint of error: %q", path, i+1, l)
+		}
+		ret.entries = append(ret.entries, baselineEntry{f, l})
+		ret.fingerprints[f] = struct{}{}
+	}
+	return ret, nil
+}
+
+func readBaselineFile(path string) (*baseline, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read baseline file %q: %w", path, err)
+	}
+	return parseBaseline(b, path)
+}
+
+func (b *baseline) contains(fingerprint string) bool {
+	_, ok := b.fingerprints[fingerprint]
+	return ok
+}
+
+// add adds the error with the fingerprint to the baseline. The fingerprint should be calculated by
+// Fingerprints function to distinguish errors with the same message in the same file.
+func (b *baseline) add(err *Error, f string) {
+	msg := strings.ReplaceAll(err.Message, "\n", " ")
+	l := fmt.Sprintf("%s %s:%d:%d: %s [%s]", f, err.Filepath, err.Line, err.Column, msg, err.Kind)
+	b.entries = append(b.entries, baselineEntry{f, l})
+	b.fingerprints[f] = struct{}{}
+}
+
+func (b *baseline) bytes() []byte {
+	var buf bytes.Buffer
+	for _, e := range b.entries {
+		buf.WriteString(e.line)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+func (b *baseline) writeFile(path string) error {
+	if err := os.WriteFile(path, b.bytes(), 0644); err != nil {
+		return fmt.Errorf("could not write baseline file %q: %w", path, err)
+	}
+	return nil
+}
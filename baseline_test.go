@@ -0,0 +1,56 @@
+package actionlint
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBaselineParseOK(t *testing.T) {
+	f1 := strings.Repeat("a", 64)
+	f2 := strings.Repeat("0", 64)
+	input := "# comment\n\n" + f1 + " test.yaml:1:1: message [kind]\r\n  " + f2 + "\n"
+	b, err := parseBaseline([]byte(input), "baseline.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b.entries) != 4 {
+		t.Fatalf("wanted 4 entries but got %d: %v", len(b.entries), b.entries)
+	}
+	for _, f := range []string{f1, f2} {
+		if _, ok := b.fingerprints[f]; !ok {
+			t.Errorf("fingerprint %q was not parsed", f)
+		}
+	}
+	want := "# comment\n\n" + f1 + " test.yaml:1:1: message [kind]\n  " + f2 + "\n"
+	if have := string(b.bytes()); have != want {
+		t.Fatalf("wanted %q but got %q", want, have)
+	}
+}
+
+func TestBaselineParseError(t *testing.T) {
+	_, err := parseBaseline([]byte("# comment\nfoo test.yaml:1:1: message\n"), "baseline.txt")
+	if err == nil {
+		t.Fatal("error did not occur")
+	}
+	want := `invalid baseline file "baseline.txt": line 2 does not start with fingerprint of error`
+	if msg := err.Error(); !strings.Contains(msg, want) {
+		t.Fatalf("error message %q does not contain %q", msg, want)
+	}
+}
+
+func TestBaselineContains(t *testing.T) {
+	err := &Error{Message: "message", Filepath: "test.yaml", Line: 1, Column: 2, Kind: "kind"}
+	f := err.Fingerprint()
+	b := newBaseline()
+	if b.contains(f) {
+		t.Fatal("empty baseline contains the error")
+	}
+	b.add(err, f)
+	if !b.contains(f) {
+		t.Fatal("error was not added to baseline")
+	}
+	want := f + " test.yaml:1:2: message [kind]\n"
+	if have := string(b.bytes()); have != want {
+		t.Fatalf("wanted %q but got %q", want, have)
+	}
+}
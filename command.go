@@ -1,9 +1,12 @@
 package actionlint
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"io"
+	"io/fs"
+	"path/filepath"
 	"runtime"
 	"runtime/debug"
 )
@@ -48,6 +51,10 @@ const commandUsageHeader = `Usage: actionlint [FLAGS] [FILES...] [-]
 
     $ actionlint -format '{{json .}}'
 
+  To output errors in Code Climate format, use "codeclimate" format.
+
+    $ actionlint -format codeclimate
+
 Documents:
 
   https://github.com/rhysd/actionlint/tree/main/docs
@@ -77,8 +84,8 @@ type Command struct {
 	Stderr io.Writer
 }
 
-func (cmd *Command) runLinter(args []string, opts *LinterOptions, initConfig bool) ([]*Error, error) {
-	l, err := NewLinter(cmd.Stdout, opts)
+func (cmd *Command) runLinter(out io.Writer, args []string, opts *LinterOptions, initConfig bool) ([]*Error, error) {
+	l, err := NewLinter(out, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -87,6 +94,15 @@ func (cmd *Command) runLinter(args []string, opts *LinterOptions, initConfig boo
 		return nil, l.GenerateDefaultConfig(".")
 	}
 
+	errs, err := cmd.lint(l, args, opts)
+	if err != nil {
+		return nil, err
+	}
+	cmd.printIgnoredErrors(l)
+	return errs, nil
+}
+
+func (cmd *Command) lint(l *Linter, args []string, opts *LinterOptions) ([]*Error, error) {
 	if len(args) == 0 {
 		return l.LintRepository(".")
 	}
@@ -106,6 +122,190 @@ func (cmd *Command) runLinter(args []string, opts *LinterOptions, initConfig boo
 	return l.LintFiles(args, nil)
 }
 
+// printIgnoredErrors prints the number of errors removed by ignore patterns to stderr so that they
+// are not silently suppressed. It is not printed to stdout not to break formatted outputs.
+func (cmd *Command) printIgnoredErrors(l *Linter) {
+	if n := l.IgnoredErrors(); n > 0 {
+		fmt.Fprintf(cmd.Stderr, "%d errors were ignored by ignore patterns\n", n)
+	}
+}
+
+// listRules prints all rules applied by the linter. When a format is given by -format option, the
+// list of RuleInfo is formatted with the template.
+func (cmd *Command) listRules(opts *LinterOptions) error {
+	l, err := NewLinter(io.Discard, opts)
+	if err != nil {
+		return err
+	}
+	rules := l.Rules()
+
+	if opts.Format != "" {
+		f, err := NewErrorFormatter(opts.Format)
+		if err != nil {
+			return err
+		}
+		if err := f.temp.Execute(cmd.Stdout, rules); err != nil {
+			return fmt.Errorf("could not format list of rules: %w", err)
+		}
+		return nil
+	}
+
+	w := 0
+	for _, r := range rules {
+		if len(r.Name) > w {
+			w = len(r.Name)
+		}
+	}
+	for _, r := range rules {
+		fmt.Fprintf(cmd.Stdout, "%-*s  %-7s  %s\n", w, r.Name, r.Severity, r.Description)
+	}
+	return nil
+}
+
+// runLinterPerFile lints the files independently and outputs a line indicating whether each file
+// passed or failed after its errors. When no file is given, all workflow files in the current
+// repository and files configured in "files" section of the configuration are linted. It returns
+// the number of failed files. A file fails when some error whose severity is at least the fail level
+// was found or it could not be linted.
+func (cmd *Command) runLinterPerFile(args []string, opts *LinterOptions, failLevel Severity) (int, error) {
+	l, err := NewLinter(cmd.Stdout, opts)
+	if err != nil {
+		return 0, err
+	}
+
+	files := args
+	var proj *Project
+	var actions map[string]struct{}
+	if len(files) == 0 {
+		var as []string
+		proj, files, as, err = l.findRepositoryFiles(".")
+		if err != nil {
+			return 0, err
+		}
+		actions = make(map[string]struct{}, len(as))
+		for _, a := range as {
+			actions[a] = struct{}{}
+		}
+		files = append(files, as...)
+	}
+
+	failed := 0
+	for _, f := range files {
+		var errs []*Error
+		var err error
+		if f == "-" {
+			var b []byte
+			b, err = io.ReadAll(cmd.Stdin)
+			if err != nil {
+				return failed, fmt.Errorf("could not read stdin: %w", err)
+			}
+			f = "<stdin>"
+			if opts.StdinFileName != "" {
+				f = opts.StdinFileName
+			}
+			errs, err = l.Lint(f, b, nil)
+		} else {
+			if _, ok := actions[f]; ok {
+				errs, err = l.lintActionMetadataFile(f, proj)
+			} else {
+				errs, err = l.LintFile(f, proj)
+			}
+			if l.relTo != "" {
+				f = l.relPath(f) // Use the same path as error messages
+			} else if l.cwd != "" {
+				if r, err := filepath.Rel(l.cwd, f); err == nil {
+					f = r // Use relative path as well as error messages
+				}
+			}
+		}
+
+		if err != nil {
+			fmt.Fprintln(cmd.Stderr, err.Error())
+			fmt.Fprintf(cmd.Stdout, "FAIL %s (could not be linted)\n", f)
+			failed++
+			continue
+		}
+
+		n := 0
+		for _, err := range errs {
+			if err.Severity.IsAtLeast(failLevel) {
+				n++
+			}
+		}
+		if n == 0 {
+			fmt.Fprintf(cmd.Stdout, "PASS %s\n", f)
+			continue
+		}
+		fmt.Fprintf(cmd.Stdout, "FAIL %s (%d errors)\n", f, n)
+		failed++
+	}
+
+	cmd.printIgnoredErrors(l)
+	return failed, nil
+}
+
+// updateBaseline merges the errors into the baseline. Errors not in the baseline yet are appended
+// and entries of errors which are no longer found are pruned. Comments and annotations in the
+// baseline are preserved. It returns the number of added entries and pruned entries.
+func updateBaseline(b *baseline, errs []*Error) (int, int) {
+	fps := Fingerprints(errs)
+	found := make(map[string]struct{}, len(errs))
+	for _, f := range fps {
+		found[f] = struct{}{}
+	}
+
+	pruned := 0
+	entries := make([]baselineEntry, 0, len(b.entries))
+	for _, e := range b.entries {
+		if e.fingerprint != "" {
+			if _, ok := found[e.fingerprint]; !ok {
+				delete(b.fingerprints, e.fingerprint)
+				pruned++
+				continue
+			}
+		}
+		entries = append(entries, e)
+	}
+	b.entries = entries
+
+	added := 0
+	for i, err := range errs {
+		if !b.contains(fps[i]) {
+			b.add(err, fps[i])
+			added++
+		}
+	}
+
+	return added, pruned
+}
+
+// runBaselineUpdate lints the files and updates the baseline file with the errors found. Errors are
+// not output since all of them are accepted in the baseline. The baseline file is created when it
+// does not exist yet.
+func (cmd *Command) runBaselineUpdate(args []string, opts *LinterOptions) error {
+	path := opts.BaselineFile
+	b, err := readBaselineFile(path)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		b = newBaseline()
+	}
+
+	opts.BaselineFile = "" // Errors in the current baseline are also necessary to update it
+	errs, err := cmd.runLinter(io.Discard, args, opts, false)
+	if err != nil {
+		return err
+	}
+
+	added, pruned := updateBaseline(b, errs)
+	if err := b.writeFile(path); err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.Stdout, "Updated baseline file %q: %d errors added, %d errors pruned\n", path, added, pruned)
+	return nil
+}
+
 type ignorePatternFlags []string
 
 func (i *ignorePatternFlags) String() string {
@@ -116,6 +316,53 @@ func (i *ignorePatternFlags) Set(v string) error {
 	return nil
 }
 
+// colorOptionFlag is a value of -color option. "auto", "always" and "never" are available. It can
+// also be used as a boolean flag like "-color" to always enable colorful output.
+type colorOptionFlag ColorOptionKind
+
+func (c *colorOptionFlag) String() string {
+	switch ColorOptionKind(*c) {
+	case ColorOptionKindAlways:
+		return "always"
+	case ColorOptionKindNever:
+		return "never"
+	default:
+		return "auto"
+	}
+}
+func (c *colorOptionFlag) Set(v string) error {
+	switch v {
+	case "auto", "false":
+		*c = colorOptionFlag(ColorOptionKindAuto)
+	case "always", "true":
+		*c = colorOptionFlag(ColorOptionKindAlways)
+	case "never":
+		*c = colorOptionFlag(ColorOptionKindNever)
+	default:
+		return fmt.Errorf("value must be one of \"auto\", \"always\" or \"never\" but got %q", v)
+	}
+	return nil
+}
+func (c *colorOptionFlag) IsBoolFlag() bool {
+	return true
+}
+
+// failLevelFlag is a value of -fail-level option. The command fails when some error whose severity
+// is at least this level was found. "error", "warning" and "info" are available.
+type failLevelFlag Severity
+
+func (f *failLevelFlag) String() string {
+	return Severity(*f).String()
+}
+func (f *failLevelFlag) Set(v string) error {
+	s, err := ParseSeverity(v)
+	if err != nil {
+		return err
+	}
+	*f = failLevelFlag(s)
+	return nil
+}
+
 // Main is main function of actionlint. It takes command line arguments as string slice and returns
 // exit status. The args should be entire arguments including the program name, usually given via
 // os.Args.
@@ -125,7 +372,11 @@ func (cmd *Command) Main(args []string) int {
 	var ignorePats ignorePatternFlags
 	var initConfig bool
 	var noColor bool
-	var color bool
+	var color colorOptionFlag
+	var baselineUpdate bool
+	var perFileExit bool
+	var listRules bool
+	failLevel := failLevelFlag(SeverityWarning)
 
 	flags := flag.NewFlagSet(args[0], flag.ContinueOnError)
 	flags.SetOutput(cmd.Stderr)
@@ -133,15 +384,21 @@ func (cmd *Command) Main(args []string) int {
 	flags.StringVar(&opts.Shellcheck, "shellcheck", "shellcheck", "Command name or file path of \"shellcheck\" external command. If empty, shellcheck integration will be disabled")
 	flags.StringVar(&opts.Pyflakes, "pyflakes", "pyflakes", "Command name or file path of \"pyflakes\" external command. If empty, pyflakes integration will be disabled")
 	flags.BoolVar(&opts.Oneline, "oneline", false, "Use one line per one error. Useful for reading error messages from programs")
-	flags.StringVar(&opts.Format, "format", "", "Custom template to format error messages in Go template syntax. See https://github.com/rhysd/actionlint/tree/main/docs/usage.md#format")
+	flags.StringVar(&opts.Format, "format", "", "Custom template to format error messages in Go template syntax, or \"codeclimate\" for Code Climate format. See https://github.com/rhysd/actionlint/tree/main/docs/usage.md#format")
 	flags.StringVar(&opts.ConfigFile, "config-file", "", "File path to config file")
 	flags.BoolVar(&initConfig, "init-config", false, "Generate default config file at .github/actionlint.yaml in current project")
 	flags.BoolVar(&noColor, "no-color", false, "Disable colorful output")
-	flags.BoolVar(&color, "color", false, "Always enable colorful output. This is useful to force colorful outputs")
+	flags.Var(&color, "color", "Colorful output mode. \"auto\" enables colors when the output is terminal and $NO_COLOR is not set, \"always\" forces colors, and \"never\" disables colors. \"-color\" without value is the same as \"-color=always\" (default \"auto\")")
 	flags.BoolVar(&opts.Verbose, "verbose", false, "Enable verbose output")
 	flags.BoolVar(&opts.Debug, "debug", false, "Enable debug output (for development)")
 	flags.BoolVar(&ver, "version", false, "Show version and how this binary was installed")
 	flags.StringVar(&opts.StdinFileName, "stdin-filename", "", "File name when reading input from stdin")
+	flags.StringVar(&opts.BaselineFile, "baseline", "", "File path to baseline file. Errors whose fingerprints are listed in the file are not reported")
+	flags.BoolVar(&baselineUpdate, "baseline-update", false, "Update the baseline file given by -baseline with errors found. New errors are added and errors no longer found are removed")
+	flags.StringVar(&opts.RelativeTo, "relative-to", "", "Directory path which file paths in error messages are made relative to. By default, they are relative to the current working directory")
+	flags.Var(&failLevel, "fail-level", "Minimum severity of errors which make the command fail. \"error\", \"warning\" or \"info\" is available")
+	flags.BoolVar(&listRules, "list-rules", false, "List all rules with their default severities and descriptions. Use -format '{{json .}}' to output them in JSON")
+	flags.BoolVar(&perFileExit, "per-file-exit", false, "Lint each file independently and output \"PASS {path}\" or \"FAIL {path}\" line per file. Checks across multiple files are not run")
 	flags.Usage = func() {
 		fmt.Fprintln(cmd.Stderr, commandUsageHeader)
 		flags.PrintDefaults()
@@ -170,19 +427,49 @@ func (cmd *Command) Main(args []string) int {
 	opts.IgnorePatterns = ignorePats
 	opts.LogWriter = cmd.Stderr
 
-	if color {
-		opts.Color = ColorOptionKindAlways
-	}
+	opts.Color = ColorOptionKind(color)
 	if noColor {
 		opts.Color = ColorOptionKindNever
 	}
 
-	errs, err := cmd.runLinter(flags.Args(), &opts, initConfig)
+	if listRules {
+		if err := cmd.listRules(&opts); err != nil {
+			fmt.Fprintln(cmd.Stderr, err.Error())
+			return ExitStatusFailure
+		}
+		return ExitStatusSuccessNoProblem
+	}
+
+	if baselineUpdate {
+		if opts.BaselineFile == "" {
+			fmt.Fprintln(cmd.Stderr, "-baseline-update requires baseline file path given by -baseline")
+			return ExitStatusInvalidCommandOption
+		}
+		if err := cmd.runBaselineUpdate(flags.Args(), &opts); err != nil {
+			fmt.Fprintln(cmd.Stderr, err.Error())
+			return ExitStatusFailure
+		}
+		return ExitStatusSuccessNoProblem
+	}
+
+	if perFileExit && !initConfig {
+		failed, err := cmd.runLinterPerFile(flags.Args(), &opts, Severity(failLevel))
+		if err != nil {
+			fmt.Fprintln(cmd.Stderr, err.Error())
+			return ExitStatusFailure
+		}
+		if failed > 0 {
+			return ExitStatusSuccessProblemFound
+		}
+		return ExitStatusSuccessNoProblem
+	}
+
+	errs, err := cmd.runLinter(cmd.Stdout, flags.Args(), &opts, initConfig)
 	if err != nil {
 		fmt.Fprintln(cmd.Stderr, err.Error())
 		return ExitStatusFailure
 	}
-	if len(errs) > 0 {
+	if s, ok := MaxSeverity(errs); ok && s.IsAtLeast(Severity(failLevel)) {
 		return ExitStatusSuccessProblemFound // Linter found some issues, yay!
 	}
 
@@ -2,9 +2,15 @@ package actionlint
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/google/go-cmp/cmp"
 )
 
 func ExampleCommand() {
@@ -28,3 +34,474 @@ func ExampleCommand() {
 		panic("actionlint command failed: " + output.String())
 	}
 }
+
+func TestCommandExitStatusRespectsSeverity(t *testing.T) {
+	workflow := filepath.Join("testdata", "err", "deprecated_workflow_commands.yaml")
+
+	testCases := []struct {
+		what   string
+		args   []string
+		status int
+	}{
+		{
+			what:   "errors",
+			args:   []string{},
+			status: ExitStatusSuccessProblemFound,
+		},
+		{
+			what:   "errors demoted to info",
+			args:   []string{"-config-file", filepath.Join("testdata", "config", "severity_info.yml")},
+			status: ExitStatusSuccessNoProblem,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			var output bytes.Buffer
+			cmd := Command{
+				Stdin:  os.Stdin,
+				Stdout: &output,
+				Stderr: &output,
+			}
+			args := append([]string{"actionlint", "-shellcheck=", "-pyflakes=", "-oneline"}, tc.args...)
+			args = append(args, workflow)
+			if status := cmd.Main(args); status != tc.status {
+				t.Fatalf("wanted exit status %d but got %d. output:\n%s", tc.status, status, output.String())
+			}
+			if output.Len() == 0 {
+				t.Fatal("errors were not output")
+			}
+		})
+	}
+}
+
+func TestCommandBaselineUpdate(t *testing.T) {
+	workflow := filepath.Join("testdata", "err", "deprecated_workflow_commands.yaml")
+	resolved := strings.Repeat("0", 64)
+	baseline := filepath.Join(t.TempDir(), "baseline.txt")
+	content := "# Accepted until migrating to environment files\n" + resolved + " resolved error\n"
+	if err := os.WriteFile(baseline, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var output bytes.Buffer
+	cmd := Command{
+		Stdin:  os.Stdin,
+		Stdout: &output,
+		Stderr: &output,
+	}
+	args := []string{"actionlint", "-shellcheck=", "-pyflakes=", "-baseline", baseline, "-baseline-update", workflow}
+	if status := cmd.Main(args); status != ExitStatusSuccessNoProblem {
+		t.Fatalf("wanted exit status %d but got %d. output:\n%s", ExitStatusSuccessNoProblem, status, output.String())
+	}
+	if have, want := output.String(), "4 errors added, 1 errors pruned"; !strings.Contains(have, want) {
+		t.Fatalf("output %q does not contain %q", have, want)
+	}
+
+	b, err := os.ReadFile(baseline)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(b), "\n"), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("wanted 5 lines in baseline file but got %d lines: %q", len(lines), lines)
+	}
+	if lines[0] != "# Accepted until migrating to environment files" {
+		t.Errorf("comment in baseline was not preserved: %q", lines[0])
+	}
+	if strings.Contains(string(b), resolved) {
+		t.Errorf("entry of resolved error was not pruned: %q", lines)
+	}
+	for _, l := range lines[1:] {
+		if !strings.Contains(l, workflow+":") || !strings.HasSuffix(l, "[deprecated-commands]") {
+			t.Errorf("unexpected entry in baseline file: %q", l)
+		}
+	}
+
+	// Errors in the baseline are no longer reported
+	output.Reset()
+	args = []string{"actionlint", "-shellcheck=", "-pyflakes=", "-baseline", baseline, workflow}
+	if status := cmd.Main(args); status != ExitStatusSuccessNoProblem {
+		t.Fatalf("wanted exit status %d but got %d. output:\n%s", ExitStatusSuccessNoProblem, status, output.String())
+	}
+
+	// Updating again does not change the baseline
+	output.Reset()
+	args = []string{"actionlint", "-shellcheck=", "-pyflakes=", "-baseline", baseline, "-baseline-update", workflow}
+	if status := cmd.Main(args); status != ExitStatusSuccessNoProblem {
+		t.Fatalf("wanted exit status %d but got %d. output:\n%s", ExitStatusSuccessNoProblem, status, output.String())
+	}
+	if have, want := output.String(), "0 errors added, 0 errors pruned"; !strings.Contains(have, want) {
+		t.Fatalf("output %q does not contain %q", have, want)
+	}
+	b2, err := os.ReadFile(baseline)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != string(b2) {
+		t.Fatalf("baseline file was changed:\n%s\n---\n%s", b, b2)
+	}
+}
+
+func TestCommandBaselineUpdateWithoutBaseline(t *testing.T) {
+	var output bytes.Buffer
+	cmd := Command{
+		Stdin:  os.Stdin,
+		Stdout: &output,
+		Stderr: &output,
+	}
+	status := cmd.Main([]string{"actionlint", "-baseline-update", filepath.Join("testdata", "err", "deprecated_workflow_commands.yaml")})
+	if status != ExitStatusInvalidCommandOption {
+		t.Fatalf("wanted exit status %d but got %d. output:\n%s", ExitStatusInvalidCommandOption, status, output.String())
+	}
+	if have, want := output.String(), "-baseline-update requires baseline file path"; !strings.Contains(have, want) {
+		t.Fatalf("output %q does not contain %q", have, want)
+	}
+}
+
+func TestCommandColorOption(t *testing.T) {
+	defer func(saved bool) { color.NoColor = saved }(color.NoColor)
+
+	workflow := filepath.Join("testdata", "err", "deprecated_workflow_commands.yaml")
+
+	testCases := []struct {
+		what    string
+		args    []string
+		colored bool
+	}{
+		{"always", []string{"-color=always"}, true},
+		{"boolean flag", []string{"-color"}, true},
+		{"never", []string{"-color=never"}, false},
+		{"no-color flag", []string{"-color", "-no-color"}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			var output bytes.Buffer
+			cmd := Command{
+				Stdin:  os.Stdin,
+				Stdout: &output,
+				Stderr: &output,
+			}
+			args := append([]string{"actionlint", "-shellcheck=", "-pyflakes="}, tc.args...)
+			args = append(args, workflow)
+			if status := cmd.Main(args); status != ExitStatusSuccessProblemFound {
+				t.Fatalf("wanted exit status %d but got %d. output:\n%s", ExitStatusSuccessProblemFound, status, output.String())
+			}
+			if have := strings.Contains(output.String(), "\x1b["); have != tc.colored {
+				t.Fatalf("wanted colored=%v but got colored=%v. output:\n%q", tc.colored, have, output.String())
+			}
+		})
+	}
+}
+
+func TestCommandColorOptionInvalidValue(t *testing.T) {
+	var output bytes.Buffer
+	cmd := Command{
+		Stdin:  os.Stdin,
+		Stdout: &output,
+		Stderr: &output,
+	}
+	status := cmd.Main([]string{"actionlint", "-color=sometimes", filepath.Join("testdata", "err", "deprecated_workflow_commands.yaml")})
+	if status != ExitStatusInvalidCommandOption {
+		t.Fatalf("wanted exit status %d but got %d. output:\n%s", ExitStatusInvalidCommandOption, status, output.String())
+	}
+	if have, want := output.String(), `value must be one of "auto", "always" or "never" but got "sometimes"`; !strings.Contains(have, want) {
+		t.Fatalf("output %q does not contain %q", have, want)
+	}
+}
+
+func TestCommandPerFileExit(t *testing.T) {
+	ok := filepath.Join("testdata", "ok", "bool_conversion.yaml")
+	ng := filepath.Join("testdata", "err", "deprecated_workflow_commands.yaml")
+	missing := filepath.Join("testdata", "ok", "does-not-exist.yaml")
+
+	testCases := []struct {
+		what   string
+		files  []string
+		status int
+		want   []string
+	}{
+		{
+			what:   "all files passed",
+			files:  []string{ok},
+			status: ExitStatusSuccessNoProblem,
+			want:   []string{"PASS " + ok},
+		},
+		{
+			what:   "some files failed",
+			files:  []string{ok, ng},
+			status: ExitStatusSuccessProblemFound,
+			want:   []string{"PASS " + ok, "FAIL " + ng + " (4 errors)"},
+		},
+		{
+			what:   "file could not be linted",
+			files:  []string{missing, ok},
+			status: ExitStatusSuccessProblemFound,
+			want:   []string{"FAIL " + missing + " (could not be linted)", "PASS " + ok},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			cmd := Command{
+				Stdin:  os.Stdin,
+				Stdout: &stdout,
+				Stderr: &stderr,
+			}
+			args := append([]string{"actionlint", "-shellcheck=", "-pyflakes=", "-oneline", "-per-file-exit"}, tc.files...)
+			if status := cmd.Main(args); status != tc.status {
+				t.Fatalf("wanted exit status %d but got %d. output:\n%s%s", tc.status, status, stdout.String(), stderr.String())
+			}
+
+			have := []string{}
+			for _, l := range strings.Split(stdout.String(), "\n") {
+				if strings.HasPrefix(l, "PASS ") || strings.HasPrefix(l, "FAIL ") {
+					have = append(have, l)
+				}
+			}
+			if !cmp.Equal(tc.want, have) {
+				t.Fatal(cmp.Diff(tc.want, have))
+			}
+		})
+	}
+}
+
+func TestCommandIgnoredErrors(t *testing.T) {
+	workflow := filepath.Join("testdata", "err", "deprecated_workflow_commands.yaml")
+
+	testCases := []struct {
+		what string
+		args []string
+		want string
+	}{
+		{
+			what: "no error ignored",
+			args: []string{"-ignore", "this matches nothing"},
+		},
+		{
+			what: "some errors ignored",
+			args: []string{"-ignore", `"set-output"`, "-ignore", `"save-state"`},
+			want: "2 errors were ignored by ignore patterns\n",
+		},
+		{
+			what: "per-file exit",
+			args: []string{"-ignore", `"set-env"`, "-per-file-exit"},
+			want: "1 errors were ignored by ignore patterns\n",
+		},
+		{
+			what: "formatted output",
+			args: []string{"-ignore", `"add-path"`, "-format", "{{json .}}"},
+			want: "1 errors were ignored by ignore patterns\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			cmd := Command{
+				Stdin:  os.Stdin,
+				Stdout: &stdout,
+				Stderr: &stderr,
+			}
+			args := append([]string{"actionlint", "-shellcheck=", "-pyflakes=", "-oneline"}, tc.args...)
+			args = append(args, workflow)
+			if status := cmd.Main(args); status != ExitStatusSuccessProblemFound {
+				t.Fatalf("wanted exit status %d but got %d. output:\n%s%s", ExitStatusSuccessProblemFound, status, stdout.String(), stderr.String())
+			}
+			if have := stderr.String(); have != tc.want {
+				t.Fatalf("wanted stderr %q but got %q", tc.want, have)
+			}
+			if strings.Contains(stdout.String(), "ignored") {
+				t.Fatalf("number of ignored errors was output to stdout: %q", stdout.String())
+			}
+		})
+	}
+}
+
+func TestCommandFailLevel(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, ".github", "workflows")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(root, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	write := func(name, step string) string {
+		p := filepath.Join(dir, name)
+		src := "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n" + step
+		if err := os.WriteFile(p, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	errorFile := write("error.yaml", "      - run: echo ${{ unknown }}\n")
+	warningFile := write("warning.yaml", "      # actionlint-disable-next-line expression\n      - run: echo\n")
+	infoFile := write("info.yaml", "      - run: echo\n        if: ${{ 'foo' == 1 }}\n")
+	okFile := write("ok.yaml", "      - run: echo\n")
+
+	testCases := []struct {
+		level    string
+		files    []string
+		status   int
+		failures []string
+	}{
+		{"", []string{errorFile}, ExitStatusSuccessProblemFound, []string{errorFile}},
+		{"", []string{warningFile}, ExitStatusSuccessProblemFound, []string{warningFile}},
+		{"", []string{infoFile}, ExitStatusSuccessNoProblem, nil},
+		{"error", []string{errorFile}, ExitStatusSuccessProblemFound, []string{errorFile}},
+		{"error", []string{warningFile}, ExitStatusSuccessNoProblem, nil},
+		{"error", []string{infoFile}, ExitStatusSuccessNoProblem, nil},
+		{"error", []string{warningFile, infoFile, errorFile}, ExitStatusSuccessProblemFound, []string{errorFile}},
+		{"warning", []string{errorFile}, ExitStatusSuccessProblemFound, []string{errorFile}},
+		{"warning", []string{warningFile}, ExitStatusSuccessProblemFound, []string{warningFile}},
+		{"warning", []string{infoFile}, ExitStatusSuccessNoProblem, nil},
+		{"warning", []string{infoFile, warningFile}, ExitStatusSuccessProblemFound, []string{warningFile}},
+		{"info", []string{errorFile}, ExitStatusSuccessProblemFound, []string{errorFile}},
+		{"info", []string{warningFile}, ExitStatusSuccessProblemFound, []string{warningFile}},
+		{"info", []string{infoFile}, ExitStatusSuccessProblemFound, []string{infoFile}},
+		{"info", []string{okFile}, ExitStatusSuccessNoProblem, nil},
+	}
+
+	for _, tc := range testCases {
+		names := make([]string, 0, len(tc.files))
+		for _, f := range tc.files {
+			names = append(names, filepath.Base(f))
+		}
+		t.Run(fmt.Sprintf("%s %s", tc.level, strings.Join(names, " ")), func(t *testing.T) {
+			for _, perFile := range []bool{false, true} {
+				var stdout, stderr bytes.Buffer
+				cmd := Command{
+					Stdin:  os.Stdin,
+					Stdout: &stdout,
+					Stderr: &stderr,
+				}
+				args := []string{"actionlint", "-shellcheck=", "-pyflakes=", "-oneline"}
+				if tc.level != "" {
+					args = append(args, "-fail-level", tc.level)
+				}
+				if perFile {
+					args = append(args, "-per-file-exit")
+				}
+				args = append(args, tc.files...)
+
+				if status := cmd.Main(args); status != tc.status {
+					t.Fatalf("wanted exit status %d but got %d (per-file=%v). output:\n%s%s", tc.status, status, perFile, stdout.String(), stderr.String())
+				}
+				if stdout.Len() == 0 && tc.files[0] != okFile {
+					t.Fatal("errors were not output")
+				}
+				if !perFile {
+					continue
+				}
+
+				failures := []string{}
+				for _, l := range strings.Split(stdout.String(), "\n") {
+					if strings.HasPrefix(l, "FAIL ") {
+						failures = append(failures, strings.Fields(l)[1])
+					}
+				}
+				want := []string{}
+				for _, f := range tc.failures {
+					want = append(want, filepath.Base(f))
+				}
+				for i, f := range failures {
+					failures[i] = filepath.Base(f)
+				}
+				if !cmp.Equal(want, failures) {
+					t.Fatal(cmp.Diff(want, failures))
+				}
+			}
+		})
+	}
+}
+
+func TestCommandFailLevelInvalidValue(t *testing.T) {
+	var output bytes.Buffer
+	cmd := Command{
+		Stdin:  os.Stdin,
+		Stdout: &output,
+		Stderr: &output,
+	}
+	status := cmd.Main([]string{"actionlint", "-fail-level=critical", filepath.Join("testdata", "err", "deprecated_workflow_commands.yaml")})
+	if status != ExitStatusInvalidCommandOption {
+		t.Fatalf("wanted exit status %d but got %d. output:\n%s", ExitStatusInvalidCommandOption, status, output.String())
+	}
+	if have, want := output.String(), `invalid severity "critical"`; !strings.Contains(have, want) {
+		t.Fatalf("output %q does not contain %q", have, want)
+	}
+}
+
+func TestCommandListRules(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	cmd := Command{
+		Stdin:  os.Stdin,
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	if status := cmd.Main([]string{"actionlint", "-list-rules"}); status != ExitStatusSuccessNoProblem {
+		t.Fatalf("exit status should be success but got %d. stderr: %s", status, stderr.String())
+	}
+
+	// Snapshot of built-in rules. Update this list when adding a new rule
+	want := []string{
+		"action               error    Checks inputs of actions at \"uses:\" and usage of popular actions",
+		"action-metadata      error    Checks structure of action metadata files given by \"files.actions\" configuration",
+		"container-image      error    Checks container image names at \"container:\" and \"services:\"",
+		"credentials          error    Checks passwords are not hardcoded at \"container:\" and \"services:\"",
+		"deprecated-commands  error    Detects deprecated workflow commands like \"::set-output\" in \"run:\" scripts",
+		"disable-comment      warning  Reports stale \"actionlint-disable\" comments which suppress no error",
+		"env-var              error    Checks names of environment variables at \"env:\"",
+		"events               error    Checks webhook events and their filters at \"on:\"",
+		"expression           error    Checks syntax and types of ${{ }} expressions and contexts available in them",
+		"glob                 error    Checks glob patterns in filters like \"branches:\" and \"paths:\"",
+		"id                   error    Checks job IDs and step IDs are valid and unique",
+		"job-needs            error    Checks dependencies between jobs at \"needs:\"",
+		"limits               error    Checks limits of workflows like the number of steps in a job",
+		"matrix               error    Checks values and combinations of \"strategy.matrix\"",
+		"permissions          error    Checks permissions of GITHUB_TOKEN at \"permissions:\"",
+		"pyflakes             error    Checks Python scripts at \"run:\" with pyflakes",
+		"run-script           warning  Checks contents of \"run:\" scripts like hardcoded repository names",
+		"runner-label         error    Checks runner labels at \"runs-on:\"",
+		"shell-name           error    Checks shell names at \"shell:\" and working directories at \"defaults.run\"",
+		"shellcheck           error    Checks shell scripts at \"run:\" with shellcheck",
+		"syntax-check         error    Checks syntax of workflows like unexpected keys and missing required keys",
+		"workflow-call        error    Checks calls of reusable workflows at \"jobs.<job_id>.uses\"",
+		"workflow-name        warning  Detects workflow names duplicated across workflow files",
+		"yaml-syntax          error    Reports syntax errors of YAML in workflow files",
+	}
+	have := strings.Split(strings.TrimSuffix(stdout.String(), "\n"), "\n")
+	if !cmp.Equal(want, have) {
+		t.Fatal(cmp.Diff(want, have))
+	}
+}
+
+func TestCommandListRulesJSON(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	cmd := Command{
+		Stdin:  os.Stdin,
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+	if status := cmd.Main([]string{"actionlint", "-list-rules", "-format", "{{json .}}"}); status != ExitStatusSuccessNoProblem {
+		t.Fatalf("exit status should be success but got %d. stderr: %s", status, stderr.String())
+	}
+
+	var have []*RuleInfo
+	if err := json.Unmarshal(stdout.Bytes(), &have); err != nil {
+		t.Fatalf("output is not valid JSON: %s: %q", err, stdout.String())
+	}
+	if want := BuiltinRules(); !cmp.Equal(want, have) {
+		t.Fatal(cmp.Diff(want, have))
+	}
+	for _, r := range have {
+		if r.Description == "" {
+			t.Errorf("description of rule %q is empty", r.Name)
+		}
+		if _, err := ParseSeverity(r.Severity); err != nil {
+			t.Errorf("severity of rule %q is invalid: %s", r.Name, err)
+		}
+	}
+}
@@ -3,6 +3,9 @@ package actionlint
 import (
 	"fmt"
 	"os"
+	"path"
+	"reflect"
+	"regexp"
 	"strings"
 
 	"gopkg.in/yaml.v3"
@@ -13,20 +16,429 @@ import (
 type Config struct {
 	// SelfHostedRunner is configuration for self-hosted runner.
 	SelfHostedRunner struct {
-		// Labels is label names for self-hosted runner.
+		// Labels is label names for self-hosted runner. Glob patterns like "gpu-*" are also
+		// available.
 		Labels []string `yaml:"labels"`
 	} `yaml:"self-hosted-runner"`
+	// EnableChecks is names of optional checks to enable. Optional checks are disabled by default.
+	EnableChecks []string `yaml:"enable-checks"`
+	// Severity is a map from rule names to severity names to override severities of errors reported
+	// by the rules. Available severity names are "error", "warning" and "info".
+	Severity map[string]string `yaml:"severity"`
+	// PinnedActions is configuration for "pinned-actions" optional check.
+	PinnedActions struct {
+		// Allow is patterns of action names like "actions/*" which are exempt from the check.
+		Allow []string `yaml:"allow"`
+	} `yaml:"pinned-actions"`
+	// Matrix is configuration for "matrix" rule.
+	Matrix struct {
+		// MaxJobs is the maximum number of jobs generated by one matrix. 0 means the default value 256.
+		MaxJobs int `yaml:"max-jobs"`
+	} `yaml:"matrix"`
+	// Limits is configuration for "limits" rule.
+	Limits struct {
+		// MaxSteps is the maximum number of steps in a job. 0 means the number is not checked.
+		MaxSteps int `yaml:"max-steps"`
+	} `yaml:"limits"`
+	// RunScript is configuration for "run-script" rule.
+	RunScript struct {
+		// Repository is the repository name in "owner/name" format for "hardcoded-repository"
+		// optional check. When it is empty, the name is detected from "origin" remote of the Git
+		// repository.
+		Repository string `yaml:"repository"`
+	} `yaml:"run-script"`
+	// Permissions is configuration for "permissions" rule.
+	Permissions struct {
+		// Actions is a map from action names like "owner/repo" to permissions of GITHUB_TOKEN
+		// required by the actions like "pull-requests: write". They are added to the built-in
+		// table of popular actions.
+		Actions map[string][]string `yaml:"actions"`
+	} `yaml:"permissions"`
+	// SetupVersions is configuration for "floating-setup-version" optional check.
+	SetupVersions struct {
+		// Actions is names of setup actions like "actions/setup-python" to check. When it is empty,
+		// all known setup actions are checked.
+		Actions []string `yaml:"actions"`
+	} `yaml:"setup-versions"`
+	// Files is configuration for finding files to lint in addition to workflow files in
+	// ".github/workflows" directory. Patterns are matched with slash-separated paths relative to
+	// the repository root. In addition to the syntax of path.Match, "**" matches zero or more
+	// directories.
+	Files struct {
+		// Workflows is glob patterns of workflow files put in non-standard locations.
+		Workflows []string `yaml:"workflows"`
+		// Actions is glob patterns of action metadata files like "**/action.yml".
+		Actions []string `yaml:"actions"`
+	} `yaml:"files"`
+	// UntrustedInputs is configuration for detecting potentially untrusted inputs in inline scripts
+	// by "expression" rule. Each path is a property dereference chain like "github.event.issue.title".
+	// "*" in the path matches any element of an array.
+	UntrustedInputs struct {
+		// Add is paths to untrusted inputs added to the built-in list.
+		Add []string `yaml:"add"`
+		// Remove is paths removed from the built-in list. Paths under the removed path are also
+		// removed. For example, "github" removes all built-in paths.
+		Remove []string `yaml:"remove"`
+	} `yaml:"untrusted-inputs"`
+	// Shellcheck is configuration for "shellcheck" rule.
+	Shellcheck struct {
+		// Disable is a flag to disable the rule even if shellcheck command is available.
+		Disable bool `yaml:"disable"`
+	} `yaml:"shellcheck"`
+	// Pyflakes is configuration for "pyflakes" rule.
+	Pyflakes struct {
+		// Disable is a flag to disable the rule even if pyflakes command is available.
+		Disable bool `yaml:"disable"`
+	} `yaml:"pyflakes"`
+	// Ignore is regular expressions matching to error messages to ignore. This is the same as
+	// -ignore command line option.
+	Ignore []string `yaml:"ignore"`
+
+	// ignorePats is regular expressions compiled from Ignore. They are compiled only once when the
+	// configuration is loaded.
+	ignorePats []*regexp.Regexp
+	// path is a file path of the configuration. It is empty when the configuration was not loaded
+	// from a file.
+	path string
+}
+
+// Severities returns a map from rule names to severities parsed from "severity" configuration.
+// Values were already validated when the configuration was parsed. Names are validated by Linter.
+func (c *Config) Severities() map[string]Severity {
+	m := make(map[string]Severity, len(c.Severity))
+	for n, s := range c.Severity {
+		if sev, err := ParseSeverity(s); err == nil {
+			m[n] = sev
+		}
+	}
+	return m
+}
+
+// compileIgnorePatterns compiles regular expressions in "ignore" configuration. It returns an error
+// when some pattern is invalid.
+func (c *Config) compileIgnorePatterns() error {
+	rs := make([]*regexp.Regexp, 0, len(c.Ignore))
+	for _, p := range c.Ignore {
+		r, err := regexp.Compile(p)
+		if err != nil {
+			return fmt.Errorf("invalid regular expression %q at \"ignore\": %s", p, err.Error())
+		}
+		rs = append(rs, r)
+	}
+	c.ignorePats = rs
+	return nil
+}
+
+// allOptionalChecks is names of all optional checks which can be enabled by "enable-checks"
+// configuration. Each check is described in "Optional checks" section of docs/checks.md.
+var allOptionalChecks = []string{
+	"cache-lookup-only",
+	"checkout-persist-credentials",
+	"concurrency-cancel-in-progress",
+	"container-latest-tag",
+	"duplicate-workflow-name",
+	"echo-secret",
+	"empty-permissions",
+	"floating-setup-version",
+	"frozen-lockfile",
+	"hardcoded-repository",
+	"matrix-environment",
+	"matrix-include",
+	"missing-permissions",
+	"mixed-type-comparison",
+	"multiline-output",
+	"not-compare-precedence",
+	"pinned-actions",
+	"pinned-images",
+	"pull-requests-permission",
+	"secrets-in-pull-request",
+	"sparse-checkout",
+	"undefined-env",
+	"undefined-step-output",
+	"unused-step-output",
+	"unused-workflow-call-input",
+}
+
+// isOptionalCheckName returns whether the name is a name of optional checks.
+func isOptionalCheckName(name string) bool {
+	for _, n := range allOptionalChecks {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// isCheckEnabled returns if the optional check is enabled by "enable-checks" configuration.
+func (c *Config) isCheckEnabled(name string) bool {
+	for _, n := range c.EnableChecks {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// untrustedInputs returns the search tree of untrusted inputs made from the built-in paths and
+// "untrusted-inputs" configuration. The paths were already validated when the configuration was
+// parsed.
+func (c *Config) untrustedInputs() UntrustedInputSearchRoots {
+	if len(c.UntrustedInputs.Add) == 0 && len(c.UntrustedInputs.Remove) == 0 {
+		return BuiltinUntrustedInputs
+	}
+	ps := []string{}
+Builtin:
+	for _, p := range BuiltinUntrustedInputs.Paths() {
+		for _, r := range c.UntrustedInputs.Remove {
+			r = strings.ToLower(r)
+			if p == r || strings.HasPrefix(p, r+".") {
+				continue Builtin
+			}
+		}
+		ps = append(ps, p)
+	}
+	ps = append(ps, c.UntrustedInputs.Add...)
+	return NewUntrustedInputSearchRoots(ps)
 }
 
 func parseConfig(b []byte, path string) (*Config, error) {
+	var n yaml.Node
+	if err := yaml.Unmarshal(b, &n); err != nil {
+		msg := strings.ReplaceAll(err.Error(), "\n", " ")
+		return nil, fmt.Errorf("could not parse config file %q: %s", path, msg)
+	}
+	if err := validateConfigNode(&n, reflect.TypeOf(Config{}), ""); err != nil {
+		return nil, fmt.Errorf("could not parse config file %q: %s", path, err.Error())
+	}
+
 	var c Config
 	if err := yaml.Unmarshal(b, &c); err != nil {
 		msg := strings.ReplaceAll(err.Error(), "\n", " ")
 		return nil, fmt.Errorf("could not parse config file %q: %s", path, msg)
 	}
+	c.path = path
+	for n, s := range c.Severity {
+		// Rule names are checked by Linter since custom rules may be registered
+		if _, err := ParseSeverity(s); err != nil {
+			return nil, fmt.Errorf("invalid config file %q: %s for rule %q at \"severity\"", path, err.Error(), n)
+		}
+	}
+	for _, p := range c.SelfHostedRunner.Labels {
+		if err := validateGlobPattern(p); err != nil {
+			return nil, fmt.Errorf("invalid config file %q: invalid glob pattern %q at \"self-hosted-runner.labels\": %s", path, p, err.Error())
+		}
+	}
+	for _, p := range c.PinnedActions.Allow {
+		if err := validateGlobPattern(p); err != nil {
+			return nil, fmt.Errorf("invalid config file %q: invalid glob pattern %q at \"pinned-actions.allow\": %s", path, p, err.Error())
+		}
+	}
+	if c.Matrix.MaxJobs < 0 {
+		return nil, fmt.Errorf("invalid config file %q: \"matrix.max-jobs\" must not be negative but got %d", path, c.Matrix.MaxJobs)
+	}
+	if c.Limits.MaxSteps < 0 {
+		return nil, fmt.Errorf("invalid config file %q: \"limits.max-steps\" must not be negative but got %d", path, c.Limits.MaxSteps)
+	}
+	if r := c.RunScript.Repository; r != "" {
+		if ss := strings.Split(r, "/"); len(ss) != 2 || ss[0] == "" || ss[1] == "" {
+			return nil, fmt.Errorf("invalid config file %q: \"run-script.repository\" must be in \"owner/name\" format but got %q", path, r)
+		}
+	}
+	for a, ps := range c.Permissions.Actions {
+		for _, p := range ps {
+			if err := validatePermission(p); err != nil {
+				return nil, fmt.Errorf("invalid config file %q: invalid permission %q for action %q at \"permissions.actions\": %s", path, p, a, err.Error())
+			}
+		}
+	}
+	for _, a := range c.SetupVersions.Actions {
+		if _, ok := setupActionVersionInputs[strings.ToLower(a)]; !ok {
+			ns := make([]string, 0, len(setupActionVersionInputs))
+			for n := range setupActionVersionInputs {
+				ns = append(ns, n)
+			}
+			return nil, fmt.Errorf("invalid config file %q: unknown setup action %q at \"setup-versions.actions\". available actions are %s", path, a, sortedQuotes(ns))
+		}
+	}
+	for _, p := range c.Files.Workflows {
+		if err := validateGlobPattern(p); err != nil {
+			return nil, fmt.Errorf("invalid config file %q: invalid glob pattern %q at \"files.workflows\": %s", path, p, err.Error())
+		}
+	}
+	for _, p := range c.Files.Actions {
+		if err := validateGlobPattern(p); err != nil {
+			return nil, fmt.Errorf("invalid config file %q: invalid glob pattern %q at \"files.actions\": %s", path, p, err.Error())
+		}
+	}
+	for _, p := range c.UntrustedInputs.Add {
+		if err := ValidateUntrustedInputPath(p); err != nil {
+			return nil, fmt.Errorf("invalid config file %q: invalid path at \"untrusted-inputs.add\": %s", path, err.Error())
+		}
+	}
+	for _, p := range c.UntrustedInputs.Remove {
+		if err := ValidateUntrustedInputPath(p); err != nil {
+			return nil, fmt.Errorf("invalid config file %q: invalid path at \"untrusted-inputs.remove\": %s", path, err.Error())
+		}
+	}
+	if err := c.compileIgnorePatterns(); err != nil {
+		return nil, fmt.Errorf("invalid config file %q: %w", path, err)
+	}
 	return &c, nil
 }
 
+// validateConfigNode validates the YAML node strictly against the type of the configuration. It
+// rejects unknown keys and values of wrong types which are silently ignored by yaml.Unmarshal.
+// The section is a dot-separated path to the node like "self-hosted-runner.labels".
+func validateConfigNode(n *yaml.Node, t reflect.Type, section string) error {
+	switch n.Kind {
+	case 0:
+		return nil // Empty document
+	case yaml.DocumentNode:
+		if len(n.Content) == 0 {
+			return nil
+		}
+		return validateConfigNode(n.Content[0], t, section)
+	case yaml.AliasNode:
+		return validateConfigNode(n.Alias, t, section)
+	}
+
+	if n.Kind == yaml.ScalarNode && n.Tag == "!!null" {
+		return nil // Null value means an empty value like "labels:"
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if n.Kind != yaml.MappingNode {
+			return configNodeTypeError(n, "mapping", section)
+		}
+		fields := make(map[string]reflect.Type, t.NumField())
+		keys := make([]string, 0, t.NumField())
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.PkgPath != "" {
+				continue // Unexported field is not a part of the configuration file
+			}
+			k := strings.Split(f.Tag.Get("yaml"), ",")[0]
+			fields[k] = f.Type
+			keys = append(keys, k)
+		}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, v := n.Content[i], n.Content[i+1]
+			ft, ok := fields[k.Value]
+			if !ok {
+				where := "at top level"
+				if section != "" {
+					where = fmt.Sprintf("in %q section", section)
+				}
+				msg := ""
+				if ss := findSimilarStrings(k.Value, keys); len(ss) > 0 {
+					msg = fmt.Sprintf(" did you mean %s?", quotes(ss))
+				}
+				return fmt.Errorf("line %d, column %d: unknown key %q %s.%s available keys are %s", k.Line, k.Column, k.Value, where, msg, sortedQuotes(keys))
+			}
+			if err := validateConfigNode(v, ft, joinConfigSection(section, k.Value)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if n.Kind != yaml.MappingNode {
+			return configNodeTypeError(n, "mapping", section)
+		}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, v := n.Content[i], n.Content[i+1]
+			if err := validateConfigNode(v, t.Elem(), joinConfigSection(section, k.Value)); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice:
+		if n.Kind != yaml.SequenceNode {
+			return configNodeTypeError(n, "sequence", section)
+		}
+		for _, e := range n.Content {
+			if err := validateConfigNode(e, t.Elem(), section); err != nil {
+				return err
+			}
+		}
+	case reflect.String:
+		if n.Kind != yaml.ScalarNode {
+			return configNodeTypeError(n, "string", section)
+		}
+		if section == "enable-checks" && !isOptionalCheckName(n.Value) {
+			msg := ""
+			if ss := findSimilarStrings(n.Value, allOptionalChecks); len(ss) > 0 {
+				msg = fmt.Sprintf(" did you mean %s?", quotes(ss))
+			}
+			return fmt.Errorf("line %d, column %d: unknown optional check %q at \"enable-checks\".%s available checks are %s", n.Line, n.Column, n.Value, msg, quotes(allOptionalChecks))
+		}
+	case reflect.Int:
+		if n.Kind != yaml.ScalarNode || n.Tag != "!!int" {
+			return configNodeTypeError(n, "integer", section)
+		}
+	case reflect.Bool:
+		if n.Kind != yaml.ScalarNode || n.Tag != "!!bool" {
+			return configNodeTypeError(n, "boolean", section)
+		}
+	}
+
+	return nil
+}
+
+func joinConfigSection(section, key string) string {
+	if section == "" {
+		return key
+	}
+	return section + "." + key
+}
+
+func configNodeTypeError(n *yaml.Node, want, section string) error {
+	var have string
+	switch n.Kind {
+	case yaml.MappingNode:
+		have = "mapping"
+	case yaml.SequenceNode:
+		have = "sequence"
+	default:
+		switch n.Tag {
+		case "!!int":
+			have = "integer"
+		case "!!float":
+			have = "float"
+		case "!!bool":
+			have = "boolean"
+		default:
+			have = "string"
+		}
+		have = fmt.Sprintf("%s %q", have, n.Value)
+	}
+	where := "top level"
+	if section != "" {
+		where = fmt.Sprintf("%q", section)
+	}
+	return fmt.Errorf("line %d, column %d: value at %s must be %s but got %s", n.Line, n.Column, where, want, have)
+}
+
+func validateGlobPattern(p string) error {
+	_, err := path.Match(p, "")
+	return err
+}
+
+// validatePermission validates a permission of GITHUB_TOKEN in "scope: level" format like
+// "pull-requests: write".
+func validatePermission(p string) error {
+	ss := strings.SplitN(p, ":", 2)
+	if len(ss) != 2 {
+		return fmt.Errorf("permission must be in \"scope: level\" format like \"pull-requests: write\"")
+	}
+	scope, level := strings.TrimSpace(ss[0]), strings.TrimSpace(ss[1])
+	if _, ok := allPermissionScopes[scope]; !ok {
+		return fmt.Errorf("unknown permission scope %q", scope)
+	}
+	if level != "read" && level != "write" {
+		return fmt.Errorf("permission level must be \"read\" or \"write\" but got %q", level)
+	}
+	return nil
+}
+
 func readConfigFile(path string) (*Config, error) {
 	b, err := os.ReadFile(path)
 	if err != nil {
@@ -37,8 +449,48 @@ func readConfigFile(path string) (*Config, error) {
 
 func writeDefaultConfigFile(path string) error {
 	b := []byte(`self-hosted-runner:
-  # Labels of self-hosted runner in array of string
+  # Labels of self-hosted runner in array of string. Glob patterns like "gpu-*" are available
   labels: []
+# Names of optional checks to enable in array of string
+enable-checks: []
+# Severities overridden per rule name. Available severities are "error", "warning" and "info"
+severity: {}
+pinned-actions:
+  # Patterns of action names exempt from "pinned-actions" check in array of string
+  allow: []
+matrix:
+  # Maximum number of jobs generated by one matrix. 0 means the default value 256
+  max-jobs: 0
+limits:
+  # Maximum number of steps in a job. 0 means the number is not checked
+  max-steps: 0
+run-script:
+  # Repository name in "owner/name" format for "hardcoded-repository" check. Detected from "origin" remote when empty
+  repository: ""
+permissions:
+  # Map from action names to permissions of GITHUB_TOKEN required by the actions like "pull-requests: write"
+  actions: {}
+setup-versions:
+  # Names of setup actions checked by "floating-setup-version" check in array of string. All known setup actions when empty
+  actions: []
+files:
+  # Glob patterns of workflow files outside ".github/workflows" relative to the repository root in array of string
+  workflows: []
+  # Glob patterns of action metadata files like "**/action.yml" relative to the repository root in array of string
+  actions: []
+untrusted-inputs:
+  # Paths to untrusted inputs like "github.event.issue.title" added to the built-in list in array of string
+  add: []
+  # Paths removed from the built-in list of untrusted inputs in array of string. "github" removes all built-in paths
+  remove: []
+shellcheck:
+  # Disable "shellcheck" rule even if shellcheck command is available
+  disable: false
+pyflakes:
+  # Disable "pyflakes" rule even if pyflakes command is available
+  disable: false
+# Regular expressions matching to error messages to ignore in array of string
+ignore: []
 `)
 	if err := os.WriteFile(path, b, 0644); err != nil {
 		return fmt.Errorf("could not write default configuration file at %q: %w", path, err)
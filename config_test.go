@@ -3,6 +3,8 @@ package actionlint
 import (
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
 	"testing"
 
@@ -40,6 +42,11 @@ func TestConfigParseOK(t *testing.T) {
 			input:  "self-hosted-runner:\n  labels: [foo, bar]",
 			labels: []string{"foo", "bar"},
 		},
+		{
+			what:   "self-hosted-runner label patterns",
+			input:  "self-hosted-runner:\n  labels: ['gpu-*', 'linux-x?']",
+			labels: []string{"gpu-*", "linux-x?"},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -56,6 +63,291 @@ func TestConfigParseOK(t *testing.T) {
 	}
 }
 
+func TestConfigParseEnableChecks(t *testing.T) {
+	input := "enable-checks: [cache-lookup-only]\n"
+	c, err := parseConfig([]byte(input), "/path/to/file.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"cache-lookup-only"}
+	if !cmp.Equal(c.EnableChecks, want) {
+		t.Fatal(cmp.Diff(c.EnableChecks, want))
+	}
+}
+
+func TestConfigParseSeverity(t *testing.T) {
+	input := "severity:\n  deprecated-commands: error\n  shellcheck: info\n  expression: warning\n"
+	c, err := parseConfig([]byte(input), "/path/to/file.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]Severity{
+		"deprecated-commands": SeverityError,
+		"shellcheck":          SeverityInfo,
+		"expression":          SeverityWarning,
+	}
+	have := c.Severities()
+	if !cmp.Equal(want, have) {
+		t.Fatal(cmp.Diff(want, have))
+	}
+}
+
+func TestConfigParseSeverityError(t *testing.T) {
+	testCases := []struct {
+		what  string
+		input string
+		want  string
+	}{
+		{
+			what:  "invalid severity",
+			input: "severity:\n  shellcheck: fatal\n",
+			want:  `invalid severity "fatal"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			_, err := parseConfig([]byte(tc.input), "/path/to/file.yml")
+			if err == nil {
+				t.Fatal("error did not occur")
+			}
+			if msg := err.Error(); !strings.Contains(msg, tc.want) {
+				t.Fatalf("error message %q does not contain %q", msg, tc.want)
+			}
+		})
+	}
+}
+
+func TestConfigParseInvalidLabelPattern(t *testing.T) {
+	_, err := parseConfig([]byte("self-hosted-runner:\n  labels: ['gpu-[']\n"), "/path/to/file.yml")
+	if err == nil {
+		t.Fatal("error did not occur for invalid glob pattern")
+	}
+	want := `invalid config file "/path/to/file.yml": invalid glob pattern "gpu-[" at "self-hosted-runner.labels": syntax error in pattern`
+	if msg := err.Error(); msg != want {
+		t.Fatalf("wanted error message %q but got %q", want, msg)
+	}
+}
+
+func TestConfigParseMatrixMaxJobs(t *testing.T) {
+	c, err := parseConfig([]byte("matrix:\n  max-jobs: 512\n"), "/path/to/file.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Matrix.MaxJobs != 512 {
+		t.Fatalf("wanted 512 but got %d", c.Matrix.MaxJobs)
+	}
+
+	_, err = parseConfig([]byte("matrix:\n  max-jobs: -1\n"), "/path/to/file.yml")
+	if err == nil {
+		t.Fatal("error did not occur for negative max-jobs")
+	}
+	if msg := err.Error(); !strings.Contains(msg, `"matrix.max-jobs" must not be negative but got -1`) {
+		t.Fatalf("unexpected error message: %q", msg)
+	}
+}
+
+func TestConfigParsePermissionsActions(t *testing.T) {
+	c, err := parseConfig([]byte("permissions:\n  actions:\n    owner/pr-comment: ['pull-requests: write', 'contents: read']\n"), "/path/to/file.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"pull-requests: write", "contents: read"}
+	if have := c.Permissions.Actions["owner/pr-comment"]; !cmp.Equal(want, have) {
+		t.Fatal(cmp.Diff(want, have))
+	}
+
+	testCases := []struct {
+		perm string
+		want string
+	}{
+		{"pull-requests", `permission must be in "scope: level" format`},
+		{"pull-request: write", `unknown permission scope "pull-request"`},
+		{"pull-requests: none", `permission level must be "read" or "write" but got "none"`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.perm, func(t *testing.T) {
+			_, err := parseConfig([]byte("permissions:\n  actions:\n    owner/repo: ['"+tc.perm+"']\n"), "/path/to/file.yml")
+			if err == nil {
+				t.Fatal("error did not occur")
+			}
+			if msg := err.Error(); !strings.Contains(msg, tc.want) || !strings.Contains(msg, `for action "owner/repo" at "permissions.actions"`) {
+				t.Fatalf("unexpected error message: %q", msg)
+			}
+		})
+	}
+}
+
+func TestConfigParseRunScriptRepository(t *testing.T) {
+	c, err := parseConfig([]byte("run-script:\n  repository: rhysd/actionlint\n"), "/path/to/file.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have, want := c.RunScript.Repository, "rhysd/actionlint"; have != want {
+		t.Fatalf("wanted %q but got %q", want, have)
+	}
+
+	for _, r := range []string{"actionlint", "rhysd/", "/actionlint", "rhysd/actionlint/foo"} {
+		_, err := parseConfig([]byte("run-script:\n  repository: "+r+"\n"), "/path/to/file.yml")
+		if err == nil {
+			t.Fatalf("error did not occur for %q", r)
+		}
+		if msg, want := err.Error(), `"run-script.repository" must be in "owner/name" format`; !strings.Contains(msg, want) {
+			t.Fatalf("error message %q does not contain %q", msg, want)
+		}
+	}
+}
+
+func TestConfigParseSetupVersionsActions(t *testing.T) {
+	c, err := parseConfig([]byte("setup-versions:\n  actions: [actions/setup-python, Actions/Setup-Node]\n"), "/path/to/file.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"actions/setup-python", "Actions/Setup-Node"}
+	if !cmp.Equal(c.SetupVersions.Actions, want) {
+		t.Fatal(cmp.Diff(c.SetupVersions.Actions, want))
+	}
+
+	_, err = parseConfig([]byte("setup-versions:\n  actions: [actions/checkout]\n"), "/path/to/file.yml")
+	if err == nil {
+		t.Fatal("error did not occur")
+	}
+	msg := `unknown setup action "actions/checkout" at "setup-versions.actions". available actions are "actions/setup-dotnet", `
+	if !strings.Contains(err.Error(), msg) {
+		t.Fatalf("error message %q does not contain %q", err.Error(), msg)
+	}
+}
+
+func TestConfigParseFiles(t *testing.T) {
+	c, err := parseConfig([]byte("files:\n  workflows: [ci/workflows/*.yaml]\n  actions: ['**/action.yml', '**/action.yaml']\n"), "/path/to/file.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"ci/workflows/*.yaml"}; !cmp.Equal(c.Files.Workflows, want) {
+		t.Fatal(cmp.Diff(c.Files.Workflows, want))
+	}
+	if want := []string{"**/action.yml", "**/action.yaml"}; !cmp.Equal(c.Files.Actions, want) {
+		t.Fatal(cmp.Diff(c.Files.Actions, want))
+	}
+
+	for _, sec := range []string{"workflows", "actions"} {
+		_, err := parseConfig([]byte("files:\n  "+sec+": ['foo/[']\n"), "/path/to/file.yml")
+		if err == nil {
+			t.Fatal("error did not occur for invalid glob pattern at", sec)
+		}
+		want := `invalid glob pattern "foo/[" at "files.` + sec + `"`
+		if msg := err.Error(); !strings.Contains(msg, want) {
+			t.Fatalf("error message %q does not contain %q", msg, want)
+		}
+	}
+}
+
+func TestConfigParseLimitsMaxSteps(t *testing.T) {
+	c, err := parseConfig([]byte("limits:\n  max-steps: 100\n"), "/path/to/file.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Limits.MaxSteps != 100 {
+		t.Fatalf("wanted 100 but got %d", c.Limits.MaxSteps)
+	}
+
+	_, err = parseConfig([]byte("limits:\n  max-steps: -1\n"), "/path/to/file.yml")
+	if err == nil {
+		t.Fatal("error did not occur for negative max-steps")
+	}
+	if msg := err.Error(); !strings.Contains(msg, `"limits.max-steps" must not be negative but got -1`) {
+		t.Fatalf("unexpected error message: %q", msg)
+	}
+}
+
+func TestConfigParsePinnedActions(t *testing.T) {
+	c, err := parseConfig([]byte("pinned-actions:\n  allow:\n    - actions/*\n    - rhysd/action-setup-vim\n"), "/path/to/file.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"actions/*", "rhysd/action-setup-vim"}
+	if !cmp.Equal(want, c.PinnedActions.Allow) {
+		t.Fatal(cmp.Diff(want, c.PinnedActions.Allow))
+	}
+
+	_, err = parseConfig([]byte("pinned-actions:\n  allow:\n    - actions/[checkout\n"), "/path/to/file.yml")
+	if err == nil {
+		t.Fatal("error did not occur for invalid glob pattern")
+	}
+	if msg := err.Error(); !strings.Contains(msg, `invalid glob pattern "actions/[checkout" at "pinned-actions.allow"`) {
+		t.Fatalf("unexpected error message: %q", msg)
+	}
+}
+
+func TestConfigParseUntrustedInputs(t *testing.T) {
+	c, err := parseConfig([]byte("untrusted-inputs:\n  add: [github.event.release.name, github.event.commits.*.id]\n  remove: [github.head_ref]\n"), "/path/to/file.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	paths := map[string]struct{}{}
+	for _, p := range c.untrustedInputs().Paths() {
+		paths[p] = struct{}{}
+	}
+	for _, p := range []string{"github.event.release.name", "github.event.commits.*.id", "github.event.issue.title"} {
+		if _, ok := paths[p]; !ok {
+			t.Errorf("%q is not included in %v", p, paths)
+		}
+	}
+	if _, ok := paths["github.head_ref"]; ok {
+		t.Errorf("removed path \"github.head_ref\" is included in %v", paths)
+	}
+
+	c, err = parseConfig([]byte("untrusted-inputs:\n  add: [github.event.release.name]\n  remove: [github]\n"), "/path/to/file.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have, want := c.untrustedInputs().Paths(), []string{"github.event.release.name"}; !cmp.Equal(want, have) {
+		t.Fatal(cmp.Diff(want, have))
+	}
+
+	c, err = parseConfig([]byte("untrusted-inputs: {}\n"), "/path/to/file.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have, want := c.untrustedInputs().Paths(), BuiltinUntrustedInputs.Paths(); !cmp.Equal(want, have) {
+		t.Fatal(cmp.Diff(want, have))
+	}
+
+	for _, sec := range []string{"add", "remove"} {
+		_, err := parseConfig([]byte("untrusted-inputs:\n  "+sec+": [\"github.event['issue']\"]\n"), "/path/to/file.yml")
+		if err == nil {
+			t.Fatal("error did not occur for invalid path at", sec)
+		}
+		want := `invalid path at "untrusted-inputs.` + sec + `": "github.event['issue']" is not a valid property dereference chain`
+		if msg := err.Error(); !strings.Contains(msg, want) {
+			t.Fatalf("error message %q does not contain %q", msg, want)
+		}
+	}
+}
+
+func TestConfigParseIgnore(t *testing.T) {
+	c, err := parseConfig([]byte("ignore:\n  - 'label \"my-.+\" is unknown'\n  - '^shellcheck reported issue'\n"), "/path/to/file.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{`label "my-.+" is unknown`, `^shellcheck reported issue`}
+	if !cmp.Equal(c.Ignore, want) {
+		t.Fatal(cmp.Diff(c.Ignore, want))
+	}
+	if n := len(c.ignorePats); n != 2 {
+		t.Fatalf("wanted 2 patterns but got %d", n)
+	}
+
+	_, err = parseConfig([]byte("ignore: ['foo(bar']\n"), "/path/to/file.yml")
+	if err == nil {
+		t.Fatal("error did not occur for invalid regular expression")
+	}
+	msg := `invalid regular expression "foo(bar" at "ignore"`
+	if !strings.Contains(err.Error(), msg) {
+		t.Fatalf("error message %q does not contain %q", err.Error(), msg)
+	}
+}
+
 func TestConfigParseError(t *testing.T) {
 	input := "self-hosted-runner: 42\n"
 	_, err := parseConfig([]byte(input), "/path/to/file.yml")
@@ -68,6 +360,146 @@ func TestConfigParseError(t *testing.T) {
 	}
 }
 
+func TestConfigParseAllKeys(t *testing.T) {
+	input := `self-hosted-runner:
+  labels: [gpu-*]
+enable-checks: [pinned-actions]
+severity:
+  deprecated-commands: info
+pinned-actions:
+  allow: [actions/*]
+matrix:
+  max-jobs: 100
+limits:
+  max-steps: 50
+run-script:
+  repository: rhysd/actionlint
+permissions:
+  actions:
+    owner/repo: ["pull-requests: write"]
+setup-versions:
+  actions: [actions/setup-go]
+files:
+  workflows: [ci/*.yaml]
+  actions: ["**/action.yml"]
+untrusted-inputs:
+  add: [github.event.release.name]
+  remove: [github.head_ref]
+shellcheck:
+  disable: true
+pyflakes:
+  disable: false
+ignore: ["^foo"]
+`
+	c, err := parseConfig([]byte(input), "/path/to/file.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Matrix.MaxJobs != 100 || c.Limits.MaxSteps != 50 || c.RunScript.Repository != "rhysd/actionlint" || !c.Shellcheck.Disable || c.Pyflakes.Disable {
+		t.Fatalf("unexpected config: %#v", c)
+	}
+}
+
+func TestConfigParseStrictError(t *testing.T) {
+	testCases := []struct {
+		what  string
+		input string
+		want  string
+	}{
+		{
+			what:  "unknown key at top level",
+			input: "self-hosted-runners:\n  labels: [foo]\n",
+			want:  `line 1, column 1: unknown key "self-hosted-runners" at top level. did you mean "self-hosted-runner"? available keys are "enable-checks", `,
+		},
+		{
+			what:  "unknown key in section",
+			input: "self-hosted-runner:\n  label: [foo]\n",
+			want:  `line 2, column 3: unknown key "label" in "self-hosted-runner" section. did you mean "labels"? available keys are "labels"`,
+		},
+		{
+			what:  "unknown key without similar key",
+			input: "matrix:\n  max-jobs: 10\n  foo: bar\n",
+			want:  `line 3, column 3: unknown key "foo" in "matrix" section. available keys are "max-jobs"`,
+		},
+		{
+			what:  "mapping instead of sequence",
+			input: "enable-checks:\n  pinned-actions: true\n",
+			want:  `line 2, column 3: value at "enable-checks" must be sequence but got mapping`,
+		},
+		{
+			what:  "scalar instead of mapping",
+			input: "self-hosted-runner: 42\n",
+			want:  `line 1, column 21: value at "self-hosted-runner" must be mapping but got integer "42"`,
+		},
+		{
+			what:  "string instead of integer",
+			input: "matrix:\n  max-jobs: foo\n",
+			want:  `line 2, column 13: value at "matrix.max-jobs" must be integer but got string "foo"`,
+		},
+		{
+			what:  "string instead of boolean",
+			input: "shellcheck:\n  disable: yes please\n",
+			want:  `line 2, column 12: value at "shellcheck.disable" must be boolean but got string "yes please"`,
+		},
+		{
+			what:  "sequence instead of string",
+			input: "self-hosted-runner:\n  labels:\n    - [foo, bar]\n",
+			want:  `line 3, column 7: value at "self-hosted-runner.labels" must be string but got sequence`,
+		},
+		{
+			what:  "wrong type in map value",
+			input: "permissions:\n  actions:\n    owner/repo: \"pull-requests: write\"\n",
+			want:  `line 3, column 17: value at "permissions.actions.owner/repo" must be sequence but got string "pull-requests: write"`,
+		},
+		{
+			what:  "unknown optional check",
+			input: "enable-checks:\n  - pinned-actions\n  - foo\n",
+			want:  `line 3, column 5: unknown optional check "foo" at "enable-checks". available checks are "cache-lookup-only", `,
+		},
+		{
+			what:  "misspelled optional check",
+			input: "enable-checks:\n  - pinned-action\n",
+			want:  `line 2, column 5: unknown optional check "pinned-action" at "enable-checks". did you mean "pinned-actions"? available checks are "cache-lookup-only", `,
+		},
+		{
+			what:  "sequence at top level",
+			input: "- foo\n",
+			want:  `line 1, column 1: value at top level must be mapping but got sequence`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			_, err := parseConfig([]byte(tc.input), "/path/to/file.yml")
+			if err == nil {
+				t.Fatal("error did not occur")
+			}
+			msg := err.Error()
+			if !strings.Contains(msg, "could not parse config file \"/path/to/file.yml\": ") {
+				t.Fatalf("unexpected error message: %q", msg)
+			}
+			if !strings.Contains(msg, tc.want) {
+				t.Fatalf("error message %q does not contain %q", msg, tc.want)
+			}
+		})
+	}
+}
+
+func TestConfigOptionalChecksAreDocumented(t *testing.T) {
+	b, err := os.ReadFile(filepath.Join("docs", "checks.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	documented := []string{}
+	for _, m := range regexp.MustCompile("(?m)^Name: `([a-z-]+)`$").FindAllStringSubmatch(string(b), -1) {
+		documented = append(documented, m[1])
+	}
+	sort.Strings(documented)
+	if !cmp.Equal(allOptionalChecks, documented) {
+		t.Fatalf("optional checks are not matched to docs/checks.md: %s", cmp.Diff(allOptionalChecks, documented))
+	}
+}
+
 func TestConfigReadFileOK(t *testing.T) {
 	p := filepath.Join("testdata", "config", "ok.yml")
 	c, err := readConfigFile(p)
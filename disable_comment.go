@@ -0,0 +1,232 @@
+package actionlint
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+var reDisableComment = regexp.MustCompile(`^\s*#\s*actionlint-(disable-next-line|disable|enable)(?:\s+(.*?))?\s*$`)
+
+// disabledRange is a range of lines where errors of the rule are disabled by a comment. The rule
+// is empty when all rules are disabled.
+type disabledRange struct {
+	directive string
+	rule      string
+	line      int // Line of the comment
+	col       int // Column of the comment
+	start     int
+	end       int // Inclusive. -1 means the end of file
+	used      bool
+}
+
+func (r *disabledRange) disables(err *Error) bool {
+	if r.rule != "" && r.rule != err.Kind {
+		return false
+	}
+	return r.start <= err.Line && (r.end < 0 || err.Line <= r.end)
+}
+
+// disableComments is a set of comments to disable errors in workflow source.
+//
+//	# actionlint-disable-next-line [rule...]
+//	# actionlint-disable [rule...]
+//	# actionlint-enable [rule...]
+//
+// "actionlint-disable-next-line" disables errors at the next line. "actionlint-disable" disables
+// errors until "actionlint-enable" comment for the same rules or the end of file. When no rule name
+// is given, all rules are disabled (or enabled). Rule names are separated by spaces or commas.
+// Lines in block scalars like "run: |" are not disable comments even if they start with "#".
+type disableComments struct {
+	ranges []*disabledRange
+}
+
+// yamlComments is a set of comment lines collected from YAML nodes. Lines starting with "#" in
+// block scalars like "run: |" are not comments of YAML but parts of the scalar values such as
+// comments of shell scripts.
+type yamlComments struct {
+	lines    []string
+	comments map[string]int   // Map from comment lines to their counts. Spaces around them are trimmed
+	scalars  map[int]struct{} // Lines in block scalars
+}
+
+// parseYAMLComments collects comments from HeadComment, LineComment, and FootComment of the YAML
+// nodes. yaml.Node does not have positions of comments so the positions are searched in the lines.
+// It returns nil when the source cannot be parsed as YAML.
+func parseYAMLComments(src []byte, lines []string) *yamlComments {
+	c := &yamlComments{lines, map[string]int{}, map[int]struct{}{}}
+	dec := yaml.NewDecoder(bytes.NewReader(src))
+	for {
+		var n yaml.Node
+		if err := dec.Decode(&n); err != nil {
+			if err == io.EOF {
+				return c
+			}
+			return nil
+		}
+		c.collect(&n, -1)
+	}
+}
+
+func (c *yamlComments) collect(n *yaml.Node, parentIndent int) {
+	for _, s := range []string{n.HeadComment, n.LineComment, n.FootComment} {
+		for _, l := range strings.Split(s, "\n") {
+			if l = strings.TrimSpace(l); l != "" {
+				c.comments[l]++
+			}
+		}
+	}
+	if n.Kind == yaml.ScalarNode && n.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 {
+		c.addBlockScalar(n.Line, parentIndent)
+	}
+	for _, child := range n.Content {
+		c.collect(child, n.Column-1)
+	}
+}
+
+// addBlockScalar adds lines of the block scalar whose header like "|" is at the line. The scalar
+// continues while lines are indented at least as deep as the first line of its content.
+func (c *yamlComments) addBlockScalar(line, parentIndent int) {
+	indent := -1
+	for i := line; i < len(c.lines); i++ { // The index i is the 0-based index of the next line
+		l := c.lines[i]
+		t := strings.TrimLeft(l, " ")
+		if t == "" {
+			c.scalars[i+1] = struct{}{}
+			continue
+		}
+		w := len(l) - len(t)
+		if indent < 0 {
+			if w <= parentIndent {
+				return
+			}
+			indent = w
+		}
+		if w < indent {
+			return
+		}
+		c.scalars[i+1] = struct{}{}
+	}
+}
+
+// consume returns whether the line is a comment of YAML. The comment is consumed so that the same
+// comment is not found twice.
+func (c *yamlComments) consume(line int, text string) bool {
+	if _, ok := c.scalars[line]; ok {
+		return false
+	}
+	text = strings.TrimSpace(text)
+	if c.comments[text] == 0 {
+		return false
+	}
+	c.comments[text]--
+	return true
+}
+
+func parseDisableComments(src []byte) *disableComments {
+	ret := &disableComments{}
+	if !bytes.Contains(src, []byte("actionlint-")) {
+		return ret
+	}
+	open := map[string]*disabledRange{} // Key is rule name. Empty key means all rules
+
+	lines := strings.Split(strings.ReplaceAll(string(src), "\r\n", "\n"), "\n")
+	comments := parseYAMLComments(src, lines) // nil when the source is broken. Then all lines are checked
+
+	for i, l := range lines {
+		m := reDisableComment.FindStringSubmatch(l)
+		if m == nil {
+			continue
+		}
+		if comments != nil && !comments.consume(i+1, l) {
+			continue
+		}
+		line, col := i+1, strings.IndexByte(l, '#')+1
+		directive := m[1]
+		rules := strings.FieldsFunc(m[2], func(r rune) bool { return r == ',' || unicode.IsSpace(r) })
+		if len(rules) == 0 {
+			rules = []string{""}
+		}
+
+		switch directive {
+		case "disable-next-line":
+			for _, r := range rules {
+				ret.ranges = append(ret.ranges, &disabledRange{directive, r, line, col, line + 1, line + 1, false})
+			}
+		case "disable":
+			for _, r := range rules {
+				if o, ok := open[r]; ok {
+					o.end = line - 1
+				}
+				d := &disabledRange{directive, r, line, col, line + 1, -1, false}
+				open[r] = d
+				ret.ranges = append(ret.ranges, d)
+			}
+		case "enable":
+			if rules[0] == "" {
+				for r, o := range open {
+					o.end = line - 1
+					delete(open, r)
+				}
+				continue
+			}
+			for _, r := range rules {
+				if o, ok := open[r]; ok {
+					o.end = line - 1
+					delete(open, r)
+				}
+			}
+		}
+	}
+
+	return ret
+}
+
+// filter removes errors disabled by the comments. Comments which disabled some error are marked as
+// used.
+func (d *disableComments) filter(errs []*Error) []*Error {
+	if len(d.ranges) == 0 {
+		return errs
+	}
+
+	filtered := make([]*Error, 0, len(errs))
+Loop:
+	for _, err := range errs {
+		for _, r := range d.ranges {
+			if r.disables(err) {
+				r.used = true
+				continue Loop
+			}
+		}
+		filtered = append(filtered, err)
+	}
+	return filtered
+}
+
+// unusedErrors returns warnings for the comments which disabled no error. It must be called after
+// the filter method.
+func (d *disableComments) unusedErrors() []*Error {
+	errs := []*Error{}
+	for _, r := range d.ranges {
+		if r.used {
+			continue
+		}
+		what := "any error"
+		if r.rule != "" {
+			what = fmt.Sprintf("errors of rule %q", r.rule)
+		}
+		errs = append(errs, &Error{
+			Message:  fmt.Sprintf("\"actionlint-%s\" comment does not disable %s. remove the stale comment", r.directive, what),
+			Line:     r.line,
+			Column:   r.col,
+			Kind:     "disable-comment",
+			Severity: SeverityWarning,
+		})
+	}
+	return errs
+}
@@ -0,0 +1,161 @@
+package actionlint
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDisableCommentsFilter(t *testing.T) {
+	testCases := []struct {
+		what   string
+		src    string
+		errs   []string // "line:kind"
+		want   []string
+		unused []string // "line:col"
+	}{
+		{
+			what: "no comment",
+			src:  "foo: bar\n",
+			errs: []string{"1:action"},
+			want: []string{"1:action"},
+		},
+		{
+			what: "disable next line for all rules",
+			src:  "# actionlint-disable-next-line\nfoo: bar\nfoo: bar\n",
+			errs: []string{"2:action", "2:expression", "3:action"},
+			want: []string{"3:action"},
+		},
+		{
+			what: "disable next line for specific rule",
+			src:  "  # actionlint-disable-next-line action\nfoo: bar\n",
+			errs: []string{"2:action", "2:expression"},
+			want: []string{"2:expression"},
+		},
+		{
+			what: "disable next line for multiple rules",
+			src:  "#actionlint-disable-next-line action, expression\nfoo: bar\n",
+			errs: []string{"2:action", "2:expression", "2:shellcheck"},
+			want: []string{"2:shellcheck"},
+		},
+		{
+			what: "disable region",
+			src:  "a\n# actionlint-disable\nb\nc\n# actionlint-enable\nd\n",
+			errs: []string{"1:action", "3:action", "4:expression", "6:action"},
+			want: []string{"1:action", "6:action"},
+		},
+		{
+			what: "disable region until end of file",
+			src:  "a\n# actionlint-disable expression\nb\nc\n",
+			errs: []string{"1:expression", "3:action", "4:expression"},
+			want: []string{"1:expression", "3:action"},
+		},
+		{
+			what: "enable specific rule",
+			src:  "# actionlint-disable action expression\na\n# actionlint-enable action\nb\n",
+			errs: []string{"2:action", "2:expression", "4:action", "4:expression"},
+			want: []string{"4:action"},
+		},
+		{
+			what:   "stale disable next line comment",
+			src:    "a\n    # actionlint-disable-next-line\nb\n",
+			errs:   []string{"1:action"},
+			want:   []string{"1:action"},
+			unused: []string{"2:5"},
+		},
+		{
+			what:   "stale rule in disable comment",
+			src:    "# actionlint-disable-next-line action shellcheck\na\n",
+			errs:   []string{"2:action"},
+			want:   []string{},
+			unused: []string{"1:1"},
+		},
+		{
+			what:   "stale disable region",
+			src:    "a\n# actionlint-disable\nb\n# actionlint-enable\nc\n",
+			errs:   []string{"1:action", "5:action"},
+			want:   []string{"1:action", "5:action"},
+			unused: []string{"2:1"},
+		},
+		{
+			what: "comment in literal block scalar",
+			src:  "run: |\n  # actionlint-disable-next-line\n  echo\nfoo: bar\n",
+			errs: []string{"3:shellcheck", "4:action"},
+			want: []string{"3:shellcheck", "4:action"},
+		},
+		{
+			what: "comment in folded block scalar",
+			src:  "steps:\n  - run: >\n      echo\n      # actionlint-disable\n  - run: echo\n",
+			errs: []string{"5:action"},
+			want: []string{"5:action"},
+		},
+		{
+			what: "comment after block scalar",
+			src:  "steps:\n  - run: |\n      echo\n    # actionlint-disable-next-line\n  - run: echo\n",
+			errs: []string{"3:shellcheck", "5:action"},
+			want: []string{"3:shellcheck"},
+		},
+		{
+			what: "same comment in block scalar and YAML",
+			src:  "steps:\n  - run: |\n      # actionlint-disable-next-line\n      echo\n  # actionlint-disable-next-line\n  - run: echo\n",
+			errs: []string{"4:shellcheck", "6:action"},
+			want: []string{"4:shellcheck"},
+		},
+		{
+			what: "empty block scalar",
+			src:  "steps:\n  - run: |\n    # actionlint-disable-next-line\n  - run: echo\n",
+			errs: []string{"4:action"},
+			want: []string{},
+		},
+		{
+			what: "not a disable comment",
+			src:  "# actionlint-disabled\n# disable actionlint\nfoo: bar # actionlint-disable-next-line\na\n",
+			errs: []string{"4:action"},
+			want: []string{"4:action"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			errs := make([]*Error, 0, len(tc.errs))
+			for _, e := range tc.errs {
+				ss := strings.Split(e, ":")
+				var l int
+				fmt.Sscanf(ss[0], "%d", &l)
+				errs = append(errs, &Error{Message: "error", Line: l, Column: 1, Kind: ss[1]})
+			}
+
+			d := parseDisableComments([]byte(tc.src))
+			filtered := d.filter(errs)
+
+			have := []string{}
+			for _, err := range filtered {
+				have = append(have, fmt.Sprintf("%d:%s", err.Line, err.Kind))
+			}
+			want := tc.want
+			if want == nil {
+				want = []string{}
+			}
+			if !cmp.Equal(want, have) {
+				t.Fatal(cmp.Diff(want, have))
+			}
+
+			unused := []string{}
+			for _, err := range d.unusedErrors() {
+				if err.Kind != "disable-comment" || err.Severity != SeverityWarning {
+					t.Errorf("unexpected kind or severity of error for stale comment: %#v", err)
+				}
+				unused = append(unused, fmt.Sprintf("%d:%d", err.Line, err.Column))
+			}
+			wantUnused := tc.unused
+			if wantUnused == nil {
+				wantUnused = []string{}
+			}
+			if !cmp.Equal(wantUnused, unused) {
+				t.Fatal(cmp.Diff(wantUnused, unused))
+			}
+		})
+	}
+}
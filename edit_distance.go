@@ -0,0 +1,81 @@
+package actionlint
+
+import (
+	"sort"
+	"strings"
+)
+
+// editDistance calculates Levenshtein distance between two strings. Distance is calculated per
+// rune.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) < len(rb) {
+		ra, rb = rb, ra
+	}
+
+	// Keep only two rows of the DP table
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d := prev[j-1] + cost // Substitution
+			if prev[j]+1 < d {
+				d = prev[j] + 1 // Deletion
+			}
+			if cur[j-1]+1 < d {
+				d = cur[j-1] + 1 // Insertion
+			}
+			cur[j] = d
+		}
+		prev, cur = cur, prev
+	}
+
+	return prev[len(rb)]
+}
+
+// findSimilarStrings finds candidates which are similar to the given string. Strings are compared
+// case-insensitively. Similar candidates are returned in order of their similarity. Candidates
+// which have the same similarity are sorted in dictionary order. This function is useful to
+// suggest a correct name for typos like "did you mean ...?"
+func findSimilarStrings(s string, candidates []string) []string {
+	l := strings.ToLower(s)
+
+	// Allow 1 typo per 3 characters. For example, "content" (7 chars) allows 2 typos
+	max := len(l) / 3
+	if max < 1 {
+		max = 1
+	}
+
+	type similar struct {
+		value string
+		dist  int
+	}
+	found := []similar{}
+	for _, c := range candidates {
+		if d := editDistance(l, strings.ToLower(c)); d <= max {
+			found = append(found, similar{c, d})
+		}
+	}
+
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].dist != found[j].dist {
+			return found[i].dist < found[j].dist
+		}
+		return found[i].value < found[j].value
+	})
+
+	ret := make([]string, 0, len(found))
+	for _, f := range found {
+		ret = append(ret, f.value)
+	}
+	return ret
+}
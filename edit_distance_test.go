@@ -0,0 +1,62 @@
+package actionlint
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestEditDistance(t *testing.T) {
+	testCases := []struct {
+		a    string
+		b    string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"", "abc", 3},
+		{"abc", "abc", 0},
+		{"check", "checks", 1},
+		{"content", "contents", 1},
+		{"kitten", "sitting", 3},
+		{"issues", "isuses", 2},
+		{"あいう", "あいえ", 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.a+"/"+tc.b, func(t *testing.T) {
+			if have := editDistance(tc.a, tc.b); have != tc.want {
+				t.Fatalf("wanted %d but got %d", tc.want, have)
+			}
+			if have := editDistance(tc.b, tc.a); have != tc.want {
+				t.Fatalf("wanted %d but got %d when swapping arguments", tc.want, have)
+			}
+		})
+	}
+}
+
+func TestFindSimilarStrings(t *testing.T) {
+	candidates := []string{"actions", "checks", "contents", "deployments", "issues", "pages", "packages", "statuses"}
+	testCases := []struct {
+		input string
+		want  []string
+	}{
+		{"check", []string{"checks"}},
+		{"content", []string{"contents"}},
+		{"ACTIONS", []string{"actions"}},
+		{"page", []string{"pages"}},
+		{"package", []string{"packages"}},
+		{"pakages", []string{"packages", "pages"}},
+		{"foo", []string{}},
+		{"", []string{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			have := findSimilarStrings(tc.input, candidates)
+			if !cmp.Equal(tc.want, have) {
+				t.Fatal(cmp.Diff(tc.want, have))
+			}
+		})
+	}
+}
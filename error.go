@@ -3,9 +3,13 @@ package actionlint
 import (
 	"bufio"
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
+	"path/filepath"
+	"sort"
 	"strings"
 	"text/template"
 
@@ -20,6 +24,68 @@ var (
 	gray   = color.New(color.FgHiBlack)
 )
 
+// Severity is a severity of an error detected by actionlint. The zero value is SeverityError.
+type Severity int
+
+const (
+	// SeverityError is severity for errors. This is the default severity.
+	SeverityError Severity = iota
+	// SeverityWarning is severity for warnings. It is used for problems which don't break workflows
+	// immediately such as usage of deprecated features.
+	SeverityWarning
+	// SeverityInfo is severity for informational messages. Errors with this severity don't make
+	// actionlint command fail by default.
+	SeverityInfo
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	case SeverityInfo:
+		return "info"
+	default:
+		return "INVALID SEVERITY"
+	}
+}
+
+// IsAtLeast returns if the severity is the same as or more severe than the given severity. For
+// example, SeverityError is at least SeverityWarning but SeverityInfo is not.
+func (s Severity) IsAtLeast(level Severity) bool {
+	return s <= level
+}
+
+// MaxSeverity returns the most severe severity among the errors. The second return value is false
+// when no error is given.
+func MaxSeverity(errs []*Error) (Severity, bool) {
+	if len(errs) == 0 {
+		return SeverityInfo, false
+	}
+	max := SeverityInfo
+	for _, err := range errs {
+		if err.Severity.IsAtLeast(max) {
+			max = err.Severity
+		}
+	}
+	return max, true
+}
+
+// ParseSeverity parses the given severity name. Available names are "error", "warning" and "info".
+func ParseSeverity(s string) (Severity, error) {
+	switch s {
+	case "error":
+		return SeverityError, nil
+	case "warning":
+		return SeverityWarning, nil
+	case "info":
+		return SeverityInfo, nil
+	default:
+		return SeverityError, fmt.Errorf("invalid severity %q. available severities are \"error\", \"warning\" and \"info\"", s)
+	}
+}
+
 // Error represents an error detected by actionlint rules
 type Error struct {
 	// Message is an error message.
@@ -32,6 +98,51 @@ type Error struct {
 	Column int
 	// Kind is a string to represent kind of the error. Usually rule name which found the error.
 	Kind string
+	// Severity is a severity of the error. Severities can be overridden per rule by configuration.
+	Severity Severity
+	// Fix is a fix of the error suggested by the rule. This field is nil when no fix is available.
+	Fix *ErrorFix
+}
+
+// ErrorFix is a fix of an error. It represents an edit which replaces the text in the range of one
+// line of the source with the replacement.
+type ErrorFix struct {
+	// Line is a line number of the text to be replaced. This value is 1-based.
+	Line int `json:"line"`
+	// Column is a column number where the replaced text starts. This value is 1-based.
+	Column int `json:"column"`
+	// EndColumn is a column number where the replaced text ends. The character at this column is
+	// not included in the range. This value is 1-based.
+	EndColumn int `json:"end_column"`
+	// Replacement is a text to replace the range with.
+	Replacement string `json:"replacement"`
+}
+
+// Apply applies the fix to the given source and returns the edited source. The given source is not
+// modified. When the range of the fix is out of the source, this method returns nil.
+func (f *ErrorFix) Apply(src []byte) []byte {
+	start := 0
+	for l := 1; l < f.Line; l++ {
+		i := bytes.IndexByte(src[start:], '\n')
+		if i < 0 {
+			return nil
+		}
+		start += i + 1
+	}
+	end := len(src)
+	if i := bytes.IndexByte(src[start:], '\n'); i >= 0 {
+		end = start + i
+	}
+	line := src[start:end]
+	if f.Column < 1 || f.EndColumn < f.Column || len(line) < f.EndColumn-1 {
+		return nil
+	}
+
+	ret := make([]byte, 0, len(src)-(f.EndColumn-f.Column)+len(f.Replacement))
+	ret = append(ret, src[:start+f.Column-1]...)
+	ret = append(ret, f.Replacement...)
+	ret = append(ret, src[start+f.EndColumn-1:]...)
+	return ret
 }
 
 // Error returns summary of the error as string.
@@ -43,6 +154,50 @@ func (e *Error) String() string {
 	return e.Error()
 }
 
+// Fingerprint returns a hex-encoded hash string which identifies the error. The hash is calculated
+// from file path, rule name and message of the error. Position of the error is not included so
+// that the fingerprint does not change when lines are inserted or removed before the error. Path
+// separators are normalized so that the fingerprint does not depend on OS. Errors with the same
+// file path, rule name and message have the same fingerprint. Use Fingerprints to distinguish them.
+func (e *Error) Fingerprint() string {
+	return e.fingerprint(0)
+}
+
+func (e *Error) fingerprint(occurrence int) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%s", filepath.ToSlash(e.Filepath), e.Kind, e.Message)
+	if occurrence > 0 {
+		fmt.Fprintf(h, "\x00%d", occurrence)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Fingerprints returns the fingerprints of the errors in the same order as the given slice. Unlike
+// Error.Fingerprint, errors with the same file path, rule name and message have different
+// fingerprints. Occurrence index of such error in order of position is added to the hash. The
+// first occurrence has the same fingerprint as Error.Fingerprint.
+func Fingerprints(errs []*Error) []string {
+	sorted := make([]*Error, len(errs))
+	copy(sorted, errs)
+	SortErrors(sorted)
+
+	type key struct{ path, kind, msg string }
+	seen := map[key]int{}
+	fps := make(map[*Error]string, len(errs))
+	for _, err := range sorted {
+		k := key{filepath.ToSlash(err.Filepath), err.Kind, err.Message}
+		n := seen[k]
+		seen[k] = n + 1
+		fps[err] = err.fingerprint(n)
+	}
+
+	ret := make([]string, 0, len(errs))
+	for _, err := range errs {
+		ret = append(ret, fps[err])
+	}
+	return ret
+}
+
 func errorAt(pos *Pos, kind string, msg string) *Error {
 	return &Error{
 		Message: msg,
@@ -78,13 +233,16 @@ func (e *Error) GetTemplateFields(source []byte) *ErrorTemplateFields {
 	}
 
 	return &ErrorTemplateFields{
-		Message:   e.Message,
-		Filepath:  e.Filepath,
-		Line:      e.Line,
-		Column:    e.Column,
-		Kind:      e.Kind,
-		Snippet:   snippet,
-		EndColumn: end,
+		Message:     e.Message,
+		Filepath:    e.Filepath,
+		Line:        e.Line,
+		Column:      e.Column,
+		Kind:        e.Kind,
+		Severity:    e.Severity.String(),
+		Snippet:     snippet,
+		EndColumn:   end,
+		Fingerprint: e.Fingerprint(),
+		Fix:         e.Fix,
 	}
 }
 
@@ -156,8 +314,8 @@ func (e *Error) getIndicator(line string) string {
 	return fmt.Sprintf("%s^%s", strings.Repeat(" ", sw), strings.Repeat("~", uw))
 }
 
-// ByErrorPosition is predicate for sort.Interface. It sorts errors slice by file path, line, and
-// column.
+// ByErrorPosition is predicate for sort.Interface. It sorts errors slice by file path, line,
+// column, and rule name.
 type ByErrorPosition []*Error
 
 func (by ByErrorPosition) Len() int {
@@ -165,19 +323,26 @@ func (by ByErrorPosition) Len() int {
 }
 
 func (by ByErrorPosition) Less(i, j int) bool {
-	if c := strings.Compare(by[i].Filepath, by[j].Filepath); c != 0 {
+	l, r := by[i], by[j]
+	if c := strings.Compare(l.Filepath, r.Filepath); c != 0 {
 		return c < 0
 	}
-	if by[i].Line == by[j].Line {
-		return by[i].Column < by[j].Column
+	if c := ComparePos(&Pos{l.Line, l.Column}, &Pos{r.Line, r.Column}); c != 0 {
+		return c < 0
 	}
-	return by[i].Line < by[j].Line
+	return l.Kind < r.Kind
 }
 
 func (by ByErrorPosition) Swap(i, j int) {
 	by[i], by[j] = by[j], by[i]
 }
 
+// SortErrors sorts the given errors in place in the same order as ByErrorPosition. The sort is
+// stable so errors reported by the same rule at the same position keep their original order.
+func SortErrors(errs []*Error) {
+	sort.Stable(ByErrorPosition(errs))
+}
+
 // ErrorTemplateFields holds all fields to format one error message.
 type ErrorTemplateFields struct {
 	// Message is error message body.
@@ -191,12 +356,76 @@ type ErrorTemplateFields struct {
 	Column int `json:"column"`
 	// Kind is a rule name the error belongs to.
 	Kind string `json:"kind"`
+	// Severity is a severity of the error. It is one of "error", "warning" or "info".
+	Severity string `json:"severity"`
 	// Snippet is a code snippet and indicator to indicate where the error occurred.
 	// When encoding into JSON, this field may be omitted when the snippet is empty.
 	Snippet string `json:"snippet,omitempty"`
 	// EndColumn is a column number where the error indicator (^~~~~~~) ends. When no indicator
 	// can be shown, EndColumn is equal to Column.
 	EndColumn int `json:"end_column"`
+	// Fingerprint is a hash string to identify the error. See Error.Fingerprint for more details.
+	Fingerprint string `json:"fingerprint"`
+	// Fix is a fix of the error suggested by the rule. It is nil when no fix is available.
+	// When encoding into JSON, this field is omitted when no fix is available.
+	Fix *ErrorFix `json:"fix,omitempty"`
+}
+
+// codeClimateIssue is an issue object in Code Climate format.
+// https://github.com/codeclimate/platform/blob/master/spec/analyzers/SPEC.md#issues
+type codeClimateIssue struct {
+	Type        string              `json:"type"`
+	CheckName   string              `json:"check_name"`
+	Description string              `json:"description"`
+	Categories  []string            `json:"categories"`
+	Severity    string              `json:"severity"`
+	Location    codeClimateLocation `json:"location"`
+	Fingerprint string              `json:"fingerprint"`
+}
+
+type codeClimateLocation struct {
+	Path  string           `json:"path"`
+	Lines codeClimateLines `json:"lines"`
+}
+
+type codeClimateLines struct {
+	Begin int `json:"begin"`
+	End   int `json:"end"`
+}
+
+func codeClimateSeverity(s string) string {
+	switch s {
+	case "warning":
+		return "minor"
+	case "info":
+		return "info"
+	default:
+		return "major"
+	}
+}
+
+func encodeCodeClimate(fields []*ErrorTemplateFields) (string, error) {
+	issues := make([]codeClimateIssue, 0, len(fields))
+	for _, f := range fields {
+		issues = append(issues, codeClimateIssue{
+			Type:        "issue",
+			CheckName:   f.Kind,
+			Description: f.Message,
+			Categories:  []string{"Bug Risk"},
+			Severity:    codeClimateSeverity(f.Severity),
+			Location: codeClimateLocation{
+				Path:  f.Filepath,
+				Lines: codeClimateLines{f.Line, f.Line},
+			},
+			Fingerprint: f.Fingerprint,
+		})
+	}
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	if err := enc.Encode(issues); err != nil {
+		return "", fmt.Errorf("could not encode errors into Code Climate format: %w", err)
+	}
+	return b.String(), nil
 }
 
 func unescapeBackslash(s string) string {
@@ -221,8 +450,12 @@ type ErrorFormatter struct {
 }
 
 // NewErrorFormatter creates new ErrorFormatter instance. Given format must contain at least one
-// {{ }} placeholder. Escaped characters like \n in the format string are unescaped.
+// {{ }} placeholder. Escaped characters like \n in the format string are unescaped. As a special
+// case, "codeclimate" formats errors into JSON in Code Climate format.
 func NewErrorFormatter(format string) (*ErrorFormatter, error) {
+	if format == "codeclimate" {
+		format = "{{codeclimate .}}"
+	}
 	if !strings.Contains(format, "{{") {
 		return nil, fmt.Errorf("template to format error messages must contain at least one {{ }} placeholder: %s", format)
 	}
@@ -238,6 +471,7 @@ func NewErrorFormatter(format string) (*ErrorFormatter, error) {
 		"replace": func(s string, oldnew ...string) string {
 			return strings.NewReplacer(oldnew...).Replace(s)
 		},
+		"codeclimate": encodeCodeClimate,
 	})
 	t, err := template.New("error formatter").Funcs(funcs).Parse(unescapeBackslash(format))
 	if err != nil {
@@ -257,8 +491,10 @@ func (f *ErrorFormatter) Print(out io.Writer, t []*ErrorTemplateFields) error {
 // PrintErrors prints the errors after formatting them with template.
 func (f *ErrorFormatter) PrintErrors(out io.Writer, errs []*Error, src []byte) error {
 	t := make([]*ErrorTemplateFields, 0, len(errs))
-	for _, err := range errs {
-		t = append(t, err.GetTemplateFields(src))
+	for i, fp := range Fingerprints(errs) {
+		fields := errs[i].GetTemplateFields(src)
+		fields.Fingerprint = fp
+		t = append(t, fields)
 	}
 	return f.Print(out, t)
 }
@@ -302,6 +302,29 @@ func TestErrorSortErrorsByFile(t *testing.T) {
 	}
 }
 
+func TestErrorSortErrors(t *testing.T) {
+	input := []*Error{
+		{Filepath: "b.yaml", Line: 1, Column: 1, Kind: "syntax-check"},
+		{Filepath: "a.yaml", Line: 2, Column: 8, Kind: "expression"},
+		{Filepath: "a.yaml", Line: 2, Column: 3, Kind: "expression"},
+		{Filepath: "a.yaml", Line: 2, Column: 3, Kind: "action", Message: "second"},
+		{Filepath: "a.yaml", Line: 2, Column: 3, Kind: "action", Message: "first"},
+		{Filepath: "a.yaml", Line: 1, Column: 10, Kind: "shellcheck"},
+		{Filepath: "", Line: 5, Column: 1, Kind: "expression"},
+	}
+	want := []*Error{input[6], input[5], input[3], input[4], input[2], input[1], input[0]}
+
+	errs := make([]*Error, len(input))
+	copy(errs, input)
+	SortErrors(errs)
+
+	for i, err := range errs {
+		if err != want[i] {
+			t.Errorf("errs[%d] is not sorted correctly. wanted %v but got %v", i, want[i], err)
+		}
+	}
+}
+
 func TestErrorGetTemplateFieldsOK(t *testing.T) {
 	testCases := []struct {
 		message string
@@ -556,3 +579,240 @@ func TestErrorString(t *testing.T) {
 		t.Fatalf("wanted %q but have %q", want, have)
 	}
 }
+
+func TestErrorParseSeverity(t *testing.T) {
+	for _, want := range []Severity{SeverityError, SeverityWarning, SeverityInfo} {
+		have, err := ParseSeverity(want.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if have != want {
+			t.Fatalf("wanted %s but got %s", want, have)
+		}
+	}
+
+	if _, err := ParseSeverity("fatal"); err == nil {
+		t.Fatal("error did not occur for invalid severity")
+	}
+}
+
+func TestErrorFingerprint(t *testing.T) {
+	newErr := func(path string, line, col int, kind, msg string) *Error {
+		return &Error{Filepath: path, Line: line, Column: col, Kind: kind, Message: msg}
+	}
+
+	base := newErr("path/to/file.yaml", 1, 2, "kind", "message").Fingerprint()
+	if base != newErr("path/to/file.yaml", 1, 2, "kind", "message").Fingerprint() {
+		t.Fatal("fingerprints of the same errors are different")
+	}
+	if len(base) != 64 {
+		t.Fatalf("fingerprint should be hex-encoded SHA-256 hash: %q", base)
+	}
+
+	// Position is not included in fingerprint so that it is not changed when lines are inserted
+	if f := newErr("path/to/file.yaml", 3, 4, "kind", "message").Fingerprint(); f != base {
+		t.Errorf("fingerprint should not depend on position but got %q", f)
+	}
+
+	for _, err := range []*Error{
+		newErr("path/to/other.yaml", 1, 2, "kind", "message"),
+		newErr("path/to/file.yaml", 1, 2, "other-kind", "message"),
+		newErr("path/to/file.yaml", 1, 2, "kind", "other message"),
+	} {
+		if f := err.Fingerprint(); f == base {
+			t.Errorf("fingerprint of %s should be different from base fingerprint %q", err, base)
+		}
+	}
+}
+
+func TestErrorFingerprints(t *testing.T) {
+	errs := []*Error{
+		{Filepath: "test.yaml", Line: 5, Column: 1, Kind: "kind", Message: "message"},
+		{Filepath: "test.yaml", Line: 1, Column: 1, Kind: "kind", Message: "message"},
+		{Filepath: "test.yaml", Line: 3, Column: 1, Kind: "kind", Message: "other message"},
+		{Filepath: "other.yaml", Line: 9, Column: 1, Kind: "kind", Message: "message"},
+	}
+
+	fps := Fingerprints(errs)
+	if len(fps) != len(errs) {
+		t.Fatalf("wanted %d fingerprints but got %d", len(errs), len(fps))
+	}
+	// The first occurrence in order of position has the same fingerprint as Error.Fingerprint
+	if fps[1] != errs[1].Fingerprint() {
+		t.Errorf("first occurrence should have fingerprint %q but got %q", errs[1].Fingerprint(), fps[1])
+	}
+	if fps[0] == fps[1] {
+		t.Errorf("errors with the same message in the same file should have different fingerprints: %q", fps[0])
+	}
+	for _, i := range []int{2, 3} {
+		if fps[i] != errs[i].Fingerprint() {
+			t.Errorf("fingerprint of unique error %s should be %q but got %q", errs[i], errs[i].Fingerprint(), fps[i])
+		}
+	}
+
+	// Fingerprints are not changed when lines are inserted before the errors
+	for _, err := range errs {
+		err.Line += 10
+	}
+	if moved := Fingerprints(errs); !cmp.Equal(fps, moved) {
+		t.Errorf("fingerprints were changed by moving errors: %s", cmp.Diff(fps, moved))
+	}
+}
+
+func TestErrorPrintCodeClimate(t *testing.T) {
+	errs := []*Error{
+		{Message: "message 1", Filepath: "file1.yaml", Line: 1, Column: 2, Kind: "kind1", Severity: SeverityError},
+		{Message: "message 2", Filepath: "file2.yaml", Line: 3, Column: 4, Kind: "kind2", Severity: SeverityWarning},
+		{Message: "message 3", Filepath: "file3.yaml", Line: 5, Column: 6, Kind: "kind3", Severity: SeverityInfo},
+	}
+
+	f, err := NewErrorFormatter("codeclimate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	if err := f.PrintErrors(&b, errs, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	type lines struct {
+		Begin int `json:"begin"`
+		End   int `json:"end"`
+	}
+	type location struct {
+		Path  string `json:"path"`
+		Lines lines  `json:"lines"`
+	}
+	type issue struct {
+		Type        string   `json:"type"`
+		CheckName   string   `json:"check_name"`
+		Description string   `json:"description"`
+		Categories  []string `json:"categories"`
+		Severity    string   `json:"severity"`
+		Location    location `json:"location"`
+		Fingerprint string   `json:"fingerprint"`
+	}
+
+	have := []issue{}
+	if err := json.Unmarshal(b.Bytes(), &have); err != nil {
+		t.Fatalf("output is not valid JSON: %v: %q", err, b.String())
+	}
+
+	want := []issue{
+		{
+			Type:        "issue",
+			CheckName:   "kind1",
+			Description: "message 1",
+			Categories:  []string{"Bug Risk"},
+			Severity:    "major",
+			Location:    location{"file1.yaml", lines{1, 1}},
+			Fingerprint: errs[0].Fingerprint(),
+		},
+		{
+			Type:        "issue",
+			CheckName:   "kind2",
+			Description: "message 2",
+			Categories:  []string{"Bug Risk"},
+			Severity:    "minor",
+			Location:    location{"file2.yaml", lines{3, 3}},
+			Fingerprint: errs[1].Fingerprint(),
+		},
+		{
+			Type:        "issue",
+			CheckName:   "kind3",
+			Description: "message 3",
+			Categories:  []string{"Bug Risk"},
+			Severity:    "info",
+			Location:    location{"file3.yaml", lines{5, 5}},
+			Fingerprint: errs[2].Fingerprint(),
+		},
+	}
+
+	if !cmp.Equal(want, have) {
+		t.Fatal(cmp.Diff(want, have))
+	}
+}
+
+func TestErrorPrintCodeClimateEmpty(t *testing.T) {
+	f, err := NewErrorFormatter("codeclimate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	if err := f.PrintErrors(&b, []*Error{}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if have := b.String(); have != "[]\n" {
+		t.Fatalf("empty array should be output but got %q", have)
+	}
+}
+
+func TestErrorMaxSeverity(t *testing.T) {
+	testCases := []struct {
+		what  string
+		input []Severity
+		want  Severity
+		ok    bool
+	}{
+		{"no error", []Severity{}, SeverityInfo, false},
+		{"info only", []Severity{SeverityInfo, SeverityInfo}, SeverityInfo, true},
+		{"warning and info", []Severity{SeverityInfo, SeverityWarning, SeverityInfo}, SeverityWarning, true},
+		{"all severities", []Severity{SeverityWarning, SeverityError, SeverityInfo}, SeverityError, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			errs := make([]*Error, 0, len(tc.input))
+			for _, s := range tc.input {
+				errs = append(errs, &Error{Severity: s})
+			}
+			have, ok := MaxSeverity(errs)
+			if ok != tc.ok {
+				t.Fatalf("wanted ok=%v but got %v", tc.ok, ok)
+			}
+			if ok && have != tc.want {
+				t.Fatalf("wanted %s but got %s", tc.want, have)
+			}
+		})
+	}
+}
+
+func TestErrorSeverityIsAtLeast(t *testing.T) {
+	all := []Severity{SeverityError, SeverityWarning, SeverityInfo}
+	for i, s := range all {
+		for j, level := range all {
+			if have, want := s.IsAtLeast(level), i <= j; have != want {
+				t.Errorf("%s.IsAtLeast(%s) should be %v but got %v", s, level, want, have)
+			}
+		}
+	}
+}
+
+func TestErrorFixApply(t *testing.T) {
+	src := "foo\n  bar baz\nqux"
+	testCases := []struct {
+		what string
+		fix  ErrorFix
+		want string
+		ok   bool
+	}{
+		{"replace word", ErrorFix{2, 3, 6, "hello"}, "foo\n  hello baz\nqux", true},
+		{"insert text", ErrorFix{1, 4, 4, "!"}, "foo!\n  bar baz\nqux", true},
+		{"last line", ErrorFix{3, 1, 4, "end"}, "foo\n  bar baz\nend", true},
+		{"line out of range", ErrorFix{4, 1, 1, "x"}, "", false},
+		{"column out of range", ErrorFix{1, 2, 6, "x"}, "", false},
+		{"invalid range", ErrorFix{1, 3, 2, "x"}, "", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			have := tc.fix.Apply([]byte(src))
+			if (have != nil) != tc.ok {
+				t.Fatalf("wanted ok=%v but got %q", tc.ok, have)
+			}
+			if tc.ok && string(have) != tc.want {
+				t.Fatalf("wanted %q but got %q", tc.want, have)
+			}
+		})
+	}
+}
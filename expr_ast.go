@@ -5,6 +5,10 @@ package actionlint
 type ExprNode interface {
 	// Token returns the first token of the node. This method is useful to get position of this node.
 	Token() *Token
+	// Span returns the start and end byte offsets of the node in the source. The end offset is
+	// exclusive, so the raw source of the node is src[start:end]. Parentheses enclosing an operand
+	// are included in the span of its parent node.
+	Span() (int, int)
 }
 
 // Variable
@@ -21,6 +25,11 @@ func (n *VariableNode) Token() *Token {
 	return n.tok
 }
 
+// Span returns the start and end byte offsets of the node in the source.
+func (n *VariableNode) Span() (int, int) {
+	return tokenSpan(n.tok)
+}
+
 // Literals
 
 // NullNode is node for null literal.
@@ -33,6 +42,11 @@ func (n *NullNode) Token() *Token {
 	return n.tok
 }
 
+// Span returns the start and end byte offsets of the node in the source.
+func (n *NullNode) Span() (int, int) {
+	return tokenSpan(n.tok)
+}
+
 // BoolNode is node for boolean literal, true or false.
 type BoolNode struct {
 	// Value is value of the boolean literal.
@@ -45,6 +59,11 @@ func (n *BoolNode) Token() *Token {
 	return n.tok
 }
 
+// Span returns the start and end byte offsets of the node in the source.
+func (n *BoolNode) Span() (int, int) {
+	return tokenSpan(n.tok)
+}
+
 // IntNode is node for integer literal.
 type IntNode struct {
 	// Value is value of the integer literal.
@@ -57,6 +76,11 @@ func (n *IntNode) Token() *Token {
 	return n.tok
 }
 
+// Span returns the start and end byte offsets of the node in the source.
+func (n *IntNode) Span() (int, int) {
+	return tokenSpan(n.tok)
+}
+
 // FloatNode is node for float literal.
 type FloatNode struct {
 	// Value is value of the float literal.
@@ -69,6 +93,11 @@ func (n *FloatNode) Token() *Token {
 	return n.tok
 }
 
+// Span returns the start and end byte offsets of the node in the source.
+func (n *FloatNode) Span() (int, int) {
+	return tokenSpan(n.tok)
+}
+
 // StringNode is node for string literal.
 type StringNode struct {
 	// Value is value of the string literal. Escapes are resolved and quotes at both edges are
@@ -82,6 +111,11 @@ func (n *StringNode) Token() *Token {
 	return n.tok
 }
 
+// Span returns the start and end byte offsets of the node in the source.
+func (n *StringNode) Span() (int, int) {
+	return tokenSpan(n.tok)
+}
+
 // Operators
 
 // ObjectDerefNode represents property dereference of object like 'foo.bar'.
@@ -90,6 +124,8 @@ type ObjectDerefNode struct {
 	Receiver ExprNode
 	// Property is a name of property to access.
 	Property string
+	start    int
+	end      int
 }
 
 // Token returns the first token of the node. This method is useful to get position of this node.
@@ -97,10 +133,17 @@ func (n ObjectDerefNode) Token() *Token {
 	return n.Receiver.Token()
 }
 
+// Span returns the start and end byte offsets of the node in the source.
+func (n ObjectDerefNode) Span() (int, int) {
+	return n.start, n.end
+}
+
 // ArrayDerefNode represents elements dereference of arrays like '*' in 'foo.bar.*.piyo'.
 type ArrayDerefNode struct {
 	// Receiver is an expression at receiver of array element dereference.
 	Receiver ExprNode
+	start    int
+	end      int
 }
 
 // Token returns the first token of the node. This method is useful to get position of this node.
@@ -108,6 +151,11 @@ func (n ArrayDerefNode) Token() *Token {
 	return n.Receiver.Token()
 }
 
+// Span returns the start and end byte offsets of the node in the source.
+func (n ArrayDerefNode) Span() (int, int) {
+	return n.start, n.end
+}
+
 // IndexAccessNode is node for index access, which represents dynamic object property access or
 // array index access.
 type IndexAccessNode struct {
@@ -115,6 +163,8 @@ type IndexAccessNode struct {
 	Operand ExprNode
 	// Index is an expression at index, which should be integer or string.
 	Index ExprNode
+	start int
+	end   int
 }
 
 // Token returns the first token of the node. This method is useful to get position of this node.
@@ -122,6 +172,11 @@ func (n *IndexAccessNode) Token() *Token {
 	return n.Operand.Token()
 }
 
+// Span returns the start and end byte offsets of the node in the source.
+func (n *IndexAccessNode) Span() (int, int) {
+	return n.start, n.end
+}
+
 // Note: Currently only ! is a logical unary operator
 
 // NotOpNode is node for unary ! operator.
@@ -129,6 +184,7 @@ type NotOpNode struct {
 	// Operand is an expression at operand of ! operator.
 	Operand ExprNode
 	tok     *Token
+	end     int
 }
 
 // Token returns the first token of the node. This method is useful to get position of this node.
@@ -136,6 +192,11 @@ func (n *NotOpNode) Token() *Token {
 	return n.tok
 }
 
+// Span returns the start and end byte offsets of the node in the source.
+func (n *NotOpNode) Span() (int, int) {
+	return n.tok.Offset, n.end
+}
+
 // CompareOpNodeKind is a kind of compare operators; ==, !=, <, <=, >, >=.
 type CompareOpNodeKind int
 
@@ -156,6 +217,25 @@ const (
 	CompareOpNodeKindNotEq
 )
 
+func (k CompareOpNodeKind) String() string {
+	switch k {
+	case CompareOpNodeKindLess:
+		return "<"
+	case CompareOpNodeKindLessEq:
+		return "<="
+	case CompareOpNodeKindGreater:
+		return ">"
+	case CompareOpNodeKindGreaterEq:
+		return ">="
+	case CompareOpNodeKindEq:
+		return "=="
+	case CompareOpNodeKindNotEq:
+		return "!="
+	default:
+		return "INVALID COMPARE OPERATOR"
+	}
+}
+
 // CompareOpNode is node for binary expression to compare values; ==, !=, <, <=, > or >=.
 type CompareOpNode struct {
 	// Kind is a kind of this expression to show which operator is used.
@@ -164,6 +244,8 @@ type CompareOpNode struct {
 	Left ExprNode
 	// Right is an expression for right hand side of the binary operator.
 	Right ExprNode
+	start int
+	end   int
 }
 
 // Token returns the first token of the node. This method is useful to get position of this node.
@@ -171,6 +253,11 @@ func (n *CompareOpNode) Token() *Token {
 	return n.Left.Token()
 }
 
+// Span returns the start and end byte offsets of the node in the source.
+func (n *CompareOpNode) Span() (int, int) {
+	return n.start, n.end
+}
+
 // LogicalOpNodeKind is a kind of logical operators; && and ||.
 type LogicalOpNodeKind int
 
@@ -202,6 +289,8 @@ type LogicalOpNode struct {
 	Left ExprNode
 	// Right is an expression for right hand side of the binary operator.
 	Right ExprNode
+	start int
+	end   int
 }
 
 // Token returns the first token of the node. This method is useful to get position of this node.
@@ -209,6 +298,11 @@ func (n *LogicalOpNode) Token() *Token {
 	return n.Left.Token()
 }
 
+// Span returns the start and end byte offsets of the node in the source.
+func (n *LogicalOpNode) Span() (int, int) {
+	return n.start, n.end
+}
+
 // FuncCallNode represents function call in expression.
 // Note that currently only calling builtin functions is supported.
 type FuncCallNode struct {
@@ -218,6 +312,7 @@ type FuncCallNode struct {
 	// Args is arguments of the function call.
 	Args []ExprNode
 	tok  *Token
+	end  int
 }
 
 // Token returns the first token of the node. This method is useful to get position of this node.
@@ -225,6 +320,15 @@ func (n *FuncCallNode) Token() *Token {
 	return n.tok
 }
 
+// Span returns the start and end byte offsets of the node in the source.
+func (n *FuncCallNode) Span() (int, int) {
+	return n.tok.Offset, n.end
+}
+
+func tokenSpan(t *Token) (int, int) {
+	return t.Offset, t.Offset + len(t.Value)
+}
+
 // VisitExprNodeFunc is a visitor function for VisitExprNode(). The entering argument is set to
 // true when it is called before visiting children. It is set to false when it is called after
 // visiting children. It means that this function is called twice for the same node. The parent
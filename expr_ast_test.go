@@ -0,0 +1,112 @@
+package actionlint
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func exprNodeSummary(n ExprNode) string {
+	switch n := n.(type) {
+	case *VariableNode:
+		return "var:" + n.Name
+	case *NullNode:
+		return "null"
+	case *BoolNode:
+		return fmt.Sprintf("bool:%v", n.Value)
+	case *IntNode:
+		return fmt.Sprintf("int:%d", n.Value)
+	case *FloatNode:
+		return fmt.Sprintf("float:%v", n.Value)
+	case *StringNode:
+		return "string:" + n.Value
+	case *ObjectDerefNode:
+		return "deref:" + n.Property
+	case *ArrayDerefNode:
+		return "arrayderef"
+	case *IndexAccessNode:
+		return "index"
+	case *NotOpNode:
+		return "not"
+	case *CompareOpNode:
+		return "compare:" + n.Kind.String()
+	case *LogicalOpNode:
+		return "logical:" + n.Kind.String()
+	case *FuncCallNode:
+		return "call:" + n.Callee
+	default:
+		panic(fmt.Sprintf("unknown node: %#v", n))
+	}
+}
+
+func parseExprForTest(t *testing.T, src string) ExprNode {
+	t.Helper()
+	n, err := NewExprParser().Parse(NewExprLexer(src + "}}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestExprVisitExprNodeOrder(t *testing.T) {
+	n := parseExprForTest(t, "!(a.b[0] == 'x') && (contains(fromJSON(c.*.d), 1.5) || null != true)")
+
+	entered := []string{}
+	left := []string{}
+	VisitExprNode(n, func(n, _ ExprNode, entering bool) {
+		if entering {
+			entered = append(entered, exprNodeSummary(n))
+		} else {
+			left = append(left, exprNodeSummary(n))
+		}
+	})
+
+	want := []string{
+		"logical:&&",
+		"not",
+		"compare:==",
+		"index",
+		"int:0",
+		"deref:b",
+		"var:a",
+		"string:x",
+		"logical:||",
+		"call:contains",
+		"call:fromJSON",
+		"deref:d",
+		"arrayderef",
+		"var:c",
+		"float:1.5",
+		"compare:!=",
+		"null",
+		"bool:true",
+	}
+	if !cmp.Equal(want, entered) {
+		t.Fatal(cmp.Diff(want, entered))
+	}
+
+	want = []string{
+		"int:0",
+		"var:a",
+		"deref:b",
+		"index",
+		"string:x",
+		"compare:==",
+		"not",
+		"var:c",
+		"arrayderef",
+		"deref:d",
+		"call:fromJSON",
+		"float:1.5",
+		"call:contains",
+		"null",
+		"bool:true",
+		"compare:!=",
+		"logical:||",
+		"logical:&&",
+	}
+	if !cmp.Equal(want, left) {
+		t.Fatal(cmp.Diff(want, left))
+	}
+}
@@ -0,0 +1,85 @@
+package actionlint
+
+import (
+	"strings"
+	"sync"
+)
+
+type exprCacheEntry struct {
+	node   ExprNode
+	offset int
+	err    *ExprError
+}
+
+// maxExprCacheEntries is the default maximum number of expressions cached by exprCache.
+const maxExprCacheEntries = 4096
+
+// exprCache is a cache of parsed expressions keyed by their source. Workflows tend to repeat the
+// same expressions such as ${{ github.sha }} so caching parse results avoids lexing and parsing
+// them again. One instance is shared by all files linted by one Linter instance and it is
+// thread-safe. The number of entries is bounded so that the memory usage does not grow while a
+// long-running process lints many files with one Linter instance. When the cache is full, an
+// arbitrary entry is evicted to add a new entry.
+// Cached nodes are shared by all occurrences of the same expression. Positions in the nodes are
+// relative to the start of the expression so they can be reused at any place, but they must be
+// treated as read-only.
+type exprCache struct {
+	mu    sync.RWMutex
+	cache map[string]*exprCacheEntry
+	max   int
+}
+
+func newExprCache(max int) *exprCache {
+	return &exprCache{cache: map[string]*exprCacheEntry{}, max: max}
+}
+
+// parse parses the given source and returns the parsed node, the offset where the lexer stopped,
+// and an error. The source is a string following "${{" and it may contain text after "}}".
+// Calling this method with nil receiver is allowed. In the case, the source is always parsed.
+func (c *exprCache) parse(src string) (ExprNode, int, *ExprError) {
+	if c == nil {
+		e := parseExprSource(src)
+		return e.node, e.offset, e.err
+	}
+
+	// Trailing text after "}}" is not a part of the expression. Strip it to share the cache entry
+	// between different strings which contain the same expression.
+	key := src
+	if i := strings.Index(src, "}}"); i >= 0 {
+		key = src[:i+2]
+	}
+
+	c.mu.RLock()
+	e, ok := c.cache[key]
+	c.mu.RUnlock()
+	if ok {
+		return e.node, e.offset, e.err
+	}
+
+	e = parseExprSource(key)
+	if e.err != nil && key != src {
+		// "}}" may appear in a string literal like ${{ '}}' }}. Parse the entire source in the case.
+		// The result is not cached since it depends on the trailing text.
+		e = parseExprSource(src)
+		return e.node, e.offset, e.err
+	}
+
+	c.mu.Lock()
+	if _, ok := c.cache[key]; !ok && len(c.cache) >= c.max {
+		for k := range c.cache {
+			delete(c.cache, k)
+			break
+		}
+	}
+	c.cache[key] = e
+	c.mu.Unlock()
+
+	return e.node, e.offset, e.err
+}
+
+func parseExprSource(src string) *exprCacheEntry {
+	l := NewExprLexer(src)
+	p := NewExprParser()
+	n, err := p.Parse(l)
+	return &exprCacheEntry{n, l.Offset(), err}
+}
@@ -1,6 +1,9 @@
 package actionlint
 
 import (
+	"fmt"
+	"regexp"
+	"sort"
 	"strings"
 )
 
@@ -71,6 +74,78 @@ func (ms UntrustedInputSearchRoots) AddRoot(m *UntrustedInputMap) {
 	ms[m.Name] = m
 }
 
+// Paths returns all paths to untrusted inputs in this search tree like "github.event.issue.title"
+// in sorted order. "*" in the paths represents elements of an array.
+func (ms UntrustedInputSearchRoots) Paths() []string {
+	ps := []string{}
+	var collect func(m *UntrustedInputMap, prefix string)
+	collect = func(m *UntrustedInputMap, prefix string) {
+		p := m.Name
+		if prefix != "" {
+			p = prefix + "." + m.Name
+		}
+		if m.Children == nil {
+			ps = append(ps, p)
+			return
+		}
+		for _, c := range m.Children {
+			collect(c, p)
+		}
+	}
+	for _, m := range ms {
+		collect(m, "")
+	}
+	sort.Strings(ps)
+	return ps
+}
+
+// NewUntrustedInputSearchRoots creates a new search tree from the paths to untrusted inputs like
+// "github.event.issue.title" or "github.event.commits.*.message". The paths should be validated
+// with ValidateUntrustedInputPath in advance. Note that only leaves of the tree are detected as
+// untrusted. When a path is a prefix of another path, the shorter path is ignored.
+func NewUntrustedInputSearchRoots(paths []string) UntrustedInputSearchRoots {
+	ms := UntrustedInputSearchRoots{}
+	for _, p := range paths {
+		ss := strings.Split(strings.ToLower(p), ".")
+		m, ok := ms[ss[0]]
+		if !ok {
+			m = NewUntrustedInputMap(ss[0])
+			ms.AddRoot(m)
+		}
+		for _, name := range ss[1:] {
+			c, ok := m.findObjectProp(name)
+			if !ok {
+				c = NewUntrustedInputMap(name)
+				c.Parent = m
+				if m.Children == nil {
+					m.Children = map[string]*UntrustedInputMap{}
+				}
+				m.Children[name] = c
+			}
+			m = c
+		}
+	}
+	return ms
+}
+
+var reUntrustedInputPathElem = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_-]*$`)
+
+// ValidateUntrustedInputPath validates the path to an untrusted input like "github.event.issue.title".
+// The path must be a dot-separated property dereference chain starting with a context name. "*"
+// is available as an element to match any element of an array like "github.event.commits.*.message".
+func ValidateUntrustedInputPath(path string) error {
+	ss := strings.Split(path, ".")
+	for i, s := range ss {
+		if i > 0 && s == "*" {
+			continue
+		}
+		if !reUntrustedInputPathElem.MatchString(s) {
+			return fmt.Errorf("%q is not a valid property dereference chain like \"github.event.issue.title\". invalid element %q at index %d", path, s, i)
+		}
+	}
+	return nil
+}
+
 // TODO: Automatically generate BuiltinUntrustedInputs from https://github.com/github/codeql/blob/main/javascript/ql/src/experimental/Security/CWE-094/ExpressionInjection.ql
 
 // BuiltinUntrustedInputs is list of untrusted inputs. These inputs are detected as untrusted in
@@ -2,8 +2,11 @@ package actionlint
 
 import (
 	"regexp"
+	"sort"
 	"strings"
 	"testing"
+
+	"github.com/google/go-cmp/cmp"
 )
 
 // From https://securitylab.github.com/research/github-actions-untrusted-input/
@@ -68,6 +71,69 @@ func TestExprInsecureBuiltinUntrustedInputs(t *testing.T) {
 	rec(BuiltinUntrustedInputs, []string{})
 }
 
+func TestExprInsecureUntrustedInputSearchRootsFromPaths(t *testing.T) {
+	paths := BuiltinUntrustedInputs.Paths()
+	want := append([]string{}, testAllUntrustedInputs...)
+	sort.Strings(want)
+	if !cmp.Equal(want, paths) {
+		t.Fatal(cmp.Diff(want, paths))
+	}
+
+	roots := NewUntrustedInputSearchRoots(paths)
+	if have := roots.Paths(); !cmp.Equal(paths, have) {
+		t.Fatal(cmp.Diff(paths, have))
+	}
+
+	roots = NewUntrustedInputSearchRoots([]string{"inputs.Title", "github.event.release.name"})
+	if have, want := roots.Paths(), []string{"github.event.release.name", "inputs.title"}; !cmp.Equal(want, have) {
+		t.Fatal(cmp.Diff(want, have))
+	}
+	for _, input := range []string{"inputs.title", "github.event.release.name"} {
+		c := NewUntrustedInputChecker(roots)
+		testRunTrustedInputsCheckerForNode(t, c, input)
+		if len(c.Errs()) != 1 {
+			t.Errorf("untrusted input %q was not detected: %v", input, c.Errs())
+		}
+	}
+}
+
+func TestExprInsecureValidateUntrustedInputPath(t *testing.T) {
+	for _, p := range []string{
+		"github",
+		"github.event.issue.title",
+		"github.event.commits.*.message",
+		"inputs.some-input",
+		"GITHUB.HEAD_REF",
+	} {
+		if err := ValidateUntrustedInputPath(p); err != nil {
+			t.Errorf("%q should be valid but got error: %s", p, err)
+		}
+	}
+
+	testCases := []struct {
+		path string
+		want string
+	}{
+		{"", `invalid element "" at index 0`},
+		{"github.", `invalid element "" at index 1`},
+		{"github..title", `invalid element "" at index 1`},
+		{"*.event", `invalid element "*" at index 0`},
+		{"github.event['issue']", `invalid element "event['issue']" at index 1`},
+		{"github.event.commits[0]", `invalid element "commits[0]" at index 2`},
+		{"github.event.0", `invalid element "0" at index 2`},
+	}
+	for _, tc := range testCases {
+		err := ValidateUntrustedInputPath(tc.path)
+		if err == nil {
+			t.Errorf("%q should be invalid", tc.path)
+			continue
+		}
+		if !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("error %q does not contain %q", err.Error(), tc.want)
+		}
+	}
+}
+
 func testRunTrustedInputsCheckerForNode(t *testing.T, c *UntrustedInputChecker, input string) {
 	n, err := NewExprParser().Parse(NewExprLexer(input + "}}"))
 	if err != nil {
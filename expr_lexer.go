@@ -3,8 +3,8 @@ package actionlint
 import (
 	"fmt"
 	"strconv"
-	"strings"
 	"text/scanner"
+	"unicode/utf8"
 )
 
 // TokenKind is kind of token.
@@ -153,80 +153,130 @@ const expectedDigitChars = "'0'..'9'"
 const expectedAlphaChars = "'a'..'z', 'A'..'Z', '_'"
 const expectedAllChars = expectedAlphaChars + ", " + expectedDigitChars + ", " + expectedPunctChars
 
+// exprLexerPos is a position in the source of ExprLexer.
+type exprLexerPos struct {
+	offset int
+	line   int
+	col    int
+}
+
+// Number of tokens allocated at once by ExprLexer. Allocating tokens one by one is a hot spot on
+// lexing many expressions.
+const exprTokenChunkSize = 8
+
 // ExprLexer is a struct to lex expression syntax. To know the syntax, see
 // https://docs.github.com/en/actions/learn-github-actions/expressions
 type ExprLexer struct {
 	src    string
-	scan   scanner.Scanner
 	lexErr *ExprError
-	start  scanner.Position
+	start  exprLexerPos
+	// Position of the current character which is not eaten yet
+	cur exprLexerPos
+	// The current character and its byte length. ch is scanner.EOF at end of the source
+	ch    rune
+	width int
+	// Tokens allocated in advance. Tokens are created from this buffer to reduce allocations
+	tokens []Token
 }
 
 // NewExprLexer makes new ExprLexer instance.
 func NewExprLexer(src string) *ExprLexer {
 	l := &ExprLexer{
-		src: src,
-		start: scanner.Position{
-			Offset: 0,
-			Line:   1,
-			Column: 1,
-		},
+		src:   src,
+		start: exprLexerPos{0, 1, 1},
+		cur:   exprLexerPos{0, 1, 1},
 	}
-	l.scan.Init(strings.NewReader(src))
-	l.scan.Error = func(_ *scanner.Scanner, m string) {
-		l.error(fmt.Sprintf("scan error while lexing expression: %s", m))
+	l.decode()
+	if l.ch == '\uFEFF' {
+		l.next() // Ignore BOM
 	}
 	return l
 }
 
+// decode reads the character at the current position. Invalid characters are reported in the
+// same manner as text/scanner.
+func (lex *ExprLexer) decode() {
+	o := lex.cur.offset
+	if o >= len(lex.src) {
+		lex.ch, lex.width = scanner.EOF, 0
+		return
+	}
+	if b := lex.src[o]; b < utf8.RuneSelf {
+		lex.ch, lex.width = rune(b), 1 // Fast path for ASCII
+		if b == 0 {
+			lex.error("scan error while lexing expression: invalid character NUL")
+		}
+		return
+	}
+	lex.ch, lex.width = utf8.DecodeRuneInString(lex.src[o:])
+	if lex.ch == utf8.RuneError && lex.width == 1 {
+		lex.error("scan error while lexing expression: invalid UTF-8 encoding")
+	}
+}
+
+// next eats the current character and returns it.
+func (lex *ExprLexer) next() rune {
+	r := lex.ch
+	if r == scanner.EOF {
+		return r
+	}
+	lex.cur.offset += lex.width
+	if r == '\n' {
+		lex.cur.line++
+		lex.cur.col = 1
+	} else {
+		lex.cur.col++
+	}
+	lex.decode()
+	return r
+}
+
 func (lex *ExprLexer) error(msg string) {
 	if lex.lexErr == nil {
-		p := lex.scan.Pos()
+		p := lex.cur
 		lex.lexErr = &ExprError{
 			Message: msg,
-			Offset:  p.Offset,
-			Line:    p.Line,
-			Column:  p.Column,
+			Offset:  p.offset,
+			Line:    p.line,
+			Column:  p.col,
 		}
 	}
 }
 
+func (lex *ExprLexer) newToken(kind TokenKind, value string, pos exprLexerPos) *Token {
+	if len(lex.tokens) == 0 {
+		lex.tokens = make([]Token, exprTokenChunkSize)
+	}
+	t := &lex.tokens[0]
+	lex.tokens = lex.tokens[1:]
+	t.Kind = kind
+	t.Value = value
+	t.Offset = pos.offset
+	t.Line = pos.line
+	t.Column = pos.col
+	return t
+}
+
 func (lex *ExprLexer) token(kind TokenKind) *Token {
-	p := lex.scan.Pos()
 	s := lex.start
-	t := &Token{
-		Kind:   kind,
-		Value:  lex.src[s.Offset:p.Offset],
-		Offset: s.Offset,
-		Line:   s.Line,
-		Column: s.Column,
-	}
-	lex.start = p
+	t := lex.newToken(kind, lex.src[s.offset:lex.cur.offset], s)
+	lex.start = lex.cur
 	return t
 }
 
 func (lex *ExprLexer) eof() *Token {
-	return &Token{
-		Kind:   TokenKindEnd,
-		Value:  "",
-		Offset: lex.start.Offset,
-		Line:   lex.start.Line,
-		Column: lex.start.Column,
-	}
+	return lex.newToken(TokenKindEnd, "", lex.start)
 }
 
 func (lex *ExprLexer) eat() rune {
-	lex.scan.Next()
-	return lex.scan.Peek() // unlike lex.scan.Next(), return top char *after* eating
+	lex.next()
+	return lex.ch // unlike lex.next(), return top char *after* eating
 }
 
 func (lex *ExprLexer) skipWhite() {
-	for {
-		if r := lex.scan.Peek(); !isWhitespace(r) {
-			return
-		}
-		lex.scan.Next()
-		lex.start = lex.scan.Pos()
+	for isWhitespace(lex.ch) {
+		lex.next()
+		lex.start = lex.cur
 	}
 }
 
@@ -274,7 +324,7 @@ func (lex *ExprLexer) lexNum() *Token {
 	// The official document says number literals are 'Any number format supported by JSON' but actually
 	// hex numbers starting with 0x are supported.
 
-	r := lex.scan.Peek() // precond: r is digit or '-'
+	r := lex.ch // precond: r is digit or '-'
 
 	if r == '-' {
 		r = lex.eat()
@@ -283,7 +333,7 @@ func (lex *ExprLexer) lexNum() *Token {
 	if r == '0' {
 		r = lex.eat()
 		if r == 'x' {
-			lex.scan.Next()
+			lex.next()
 			return lex.lexHexInt()
 		}
 	} else {
@@ -342,7 +392,7 @@ func (lex *ExprLexer) lexNum() *Token {
 	}
 
 	if isAlnum(r) {
-		s := lex.src[lex.start.Offset:lex.scan.Pos().Offset]
+		s := lex.src[lex.start.offset:lex.cur.offset]
 		return lex.unexpected(r, "character following number "+s, expectedPunctChars)
 	}
 
@@ -350,7 +400,7 @@ func (lex *ExprLexer) lexNum() *Token {
 }
 
 func (lex *ExprLexer) lexHexInt() *Token {
-	r := lex.scan.Peek()
+	r := lex.ch
 
 	if r == '0' {
 		r = lex.eat()
@@ -370,7 +420,7 @@ func (lex *ExprLexer) lexHexInt() *Token {
 	// Note: GitHub Actions does not support exponent part like 0x1f2p-a8
 
 	if isAlnum(r) {
-		s := lex.src[lex.start.Offset:lex.scan.Pos().Offset]
+		s := lex.src[lex.start.offset:lex.cur.offset]
 		return lex.unexpected(r, "character following hex integer "+s, expectedPunctChars)
 	}
 
@@ -396,7 +446,7 @@ func (lex *ExprLexer) lexEnd() *Token {
 	if r != '}' {
 		return lex.unexpected(r, "end marker }}", "'}'")
 	}
-	lex.scan.Next()
+	lex.next()
 	// }} is an end marker of interpolation
 	return lex.token(TokenKindEnd)
 }
@@ -405,7 +455,7 @@ func (lex *ExprLexer) lexLess() *Token {
 	k := TokenKindLess
 	if lex.eat() == '=' { // eat '<'
 		k = TokenKindLessEq
-		lex.scan.Next()
+		lex.next()
 	}
 	return lex.token(k)
 }
@@ -414,7 +464,7 @@ func (lex *ExprLexer) lexGreater() *Token {
 	k := TokenKindGreater
 	if lex.eat() == '=' { // eat '>'
 		k = TokenKindGreaterEq
-		lex.scan.Next()
+		lex.next()
 	}
 	return lex.token(k)
 }
@@ -423,14 +473,14 @@ func (lex *ExprLexer) lexEq() *Token {
 	if r := lex.eat(); r != '=' { // eat '='
 		return lex.unexpected(r, "== operator", "'='")
 	}
-	lex.scan.Next()
+	lex.next()
 	return lex.token(TokenKindEq)
 }
 
 func (lex *ExprLexer) lexBang() *Token {
 	k := TokenKindNot
 	if lex.eat() == '=' { // eat '!'
-		lex.scan.Next() // eat '='
+		lex.next() // eat '='
 		k = TokenKindNotEq
 	}
 	return lex.token(k)
@@ -440,7 +490,7 @@ func (lex *ExprLexer) lexAnd() *Token {
 	if r := lex.eat(); r != '&' { // eat the first '&'
 		return lex.unexpected(r, "&& operator", "'&'")
 	}
-	lex.scan.Next() // eat the second '&'
+	lex.next() // eat the second '&'
 	return lex.token(TokenKindAnd)
 }
 
@@ -448,12 +498,12 @@ func (lex *ExprLexer) lexOr() *Token {
 	if r := lex.eat(); r != '|' { // eat the first '|'
 		return lex.unexpected(r, "|| operator", "'|'")
 	}
-	lex.scan.Next() // eat the second '|'
+	lex.next() // eat the second '|'
 	return lex.token(TokenKindOr)
 }
 
 func (lex *ExprLexer) lexChar(k TokenKind) *Token {
-	lex.scan.Next()
+	lex.next()
 	return lex.token(k)
 }
 
@@ -463,7 +513,7 @@ func (lex *ExprLexer) lexChar(k TokenKind) *Token {
 func (lex *ExprLexer) Next() *Token {
 	lex.skipWhite()
 
-	r := lex.scan.Peek()
+	r := lex.ch
 	if r == scanner.EOF {
 		return lex.unexpectedEOF()
 	}
@@ -516,7 +566,7 @@ func (lex *ExprLexer) Next() *Token {
 
 // Offset returns the current offset (scanning position).
 func (lex *ExprLexer) Offset() int {
-	return lex.scan.Pos().Offset
+	return lex.cur.offset
 }
 
 // Err returns an error while lexing. When multiple errors occur, the first one is returned.
@@ -533,11 +583,11 @@ func LexExpression(src string) ([]*Token, int, *ExprError) {
 	for {
 		t := l.Next()
 		if l.lexErr != nil {
-			return nil, l.scan.Pos().Offset, l.lexErr
+			return nil, l.cur.offset, l.lexErr
 		}
 		ts = append(ts, t)
 		if t.Kind == TokenKindEnd {
-			return ts, l.scan.Pos().Offset, nil
+			return ts, l.cur.offset, nil
 		}
 	}
 }
@@ -807,6 +807,24 @@ func TestLexExprError(t *testing.T) {
 			want:  "unexpected character 'z' while lexing character following hex integer 0x1",
 			col:   5,
 		},
+		{
+			what:  "NUL character in string literal",
+			input: "'foo\x00bar' }}",
+			want:  "scan error while lexing expression: invalid character NUL",
+			col:   5,
+		},
+		{
+			what:  "invalid UTF-8 sequence in string literal",
+			input: "'\u3042\xff' }}",
+			want:  "scan error while lexing expression: invalid UTF-8 encoding",
+			col:   3,
+		},
+		{
+			what:  "unknown char after multi-byte chars",
+			input: "'\u3042\u3044' ?",
+			want:  "unexpected character '?' while lexing expression",
+			col:   6,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -850,3 +868,59 @@ func TestLexTokenPos(t *testing.T) {
 		}
 	}
 }
+
+func TestLexTokenPosMultiLines(t *testing.T) {
+	input := "\uFEFFfoo(\n  '\u3042\u3044',\n\t\u3046 )}}"
+	want := []struct {
+		offset int
+		line   int
+		col    int
+	}{
+		{0, 1, 1},  // \uFEFFfoo
+		{6, 1, 5},  // (
+		{10, 2, 3}, // 'あい'
+		{18, 2, 7}, // ,
+		{21, 3, 2}, // う (lexed as unexpected character)
+	}
+
+	l := NewExprLexer(input)
+	for i, w := range want[:4] {
+		tok := l.Next()
+		if err := l.Err(); err != nil {
+			t.Fatal(err)
+		}
+		if tok.Offset != w.offset || tok.Line != w.line || tok.Column != w.col {
+			t.Errorf("%dth token %q position mismatch. want=%d:%d:%d, have=%d:%d:%d", i+1, tok.Value, w.offset, w.line, w.col, tok.Offset, tok.Line, tok.Column)
+		}
+	}
+
+	l.Next()
+	err := l.Err()
+	if err == nil {
+		t.Fatal("error did not occur")
+	}
+	w := want[4]
+	if err.Offset != w.offset || err.Line != w.line || err.Column != w.col {
+		t.Errorf("error position mismatch. want=%d:%d:%d, have=%d:%d:%d", w.offset, w.line, w.col, err.Offset, err.Line, err.Column)
+	}
+}
+
+func BenchmarkLexManyInterpolations(b *testing.B) {
+	var sb strings.Builder
+	for i := 0; i < 1000; i++ {
+		sb.WriteString("format('{0}-{1}', github.event.pull_request.head.sha, matrix.os) == 'foo' && steps.build.outputs.result || fromJSON(needs.setup.outputs.value)[0] != 1.5e3 ")
+		if i < 999 {
+			sb.WriteString("&& ")
+		}
+	}
+	sb.WriteString("}}")
+	input := sb.String()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, _, err := LexExpression(input); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
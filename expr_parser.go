@@ -19,6 +19,7 @@ func errorAtToken(t *Token, msg string) *ExprError {
 // https://docs.github.com/en/actions/learn-github-actions/expressions
 type ExprParser struct {
 	cur   *Token
+	end   int // End offset of the last token eaten by the parser
 	lexer *ExprLexer
 	err   *ExprError
 }
@@ -58,6 +59,7 @@ func (p *ExprParser) unexpected(where string, expected []TokenKind) {
 
 func (p *ExprParser) next() *Token {
 	ret := p.cur
+	p.end = ret.Offset + len(ret.Value)
 	p.cur = p.lexer.Next()
 	return ret
 }
@@ -102,7 +104,7 @@ func (p *ExprParser) parseIdent() ExprNode {
 				}
 			}
 		}
-		return &FuncCallNode{ident.Value, args, ident}
+		return &FuncCallNode{ident.Value, args, ident, p.end}
 	default:
 		// Handle keywords. Note that keywords are case sensitive. TRUE, FALSE, NULL are invalid named value.
 		switch ident.Value {
@@ -199,6 +201,7 @@ func (p *ExprParser) parsePrimaryExpr() ExprNode {
 }
 
 func (p *ExprParser) parsePostfixOp() ExprNode {
+	start := p.peek().Offset
 	ret := p.parsePrimaryExpr()
 	if ret == nil {
 		return nil
@@ -211,11 +214,11 @@ func (p *ExprParser) parsePostfixOp() ExprNode {
 			switch p.peek().Kind {
 			case TokenKindStar:
 				p.next() // eat '*'
-				ret = &ArrayDerefNode{ret}
+				ret = &ArrayDerefNode{ret, start, p.end}
 			case TokenKindIdent:
 				t := p.next() // eat 'b' of 'a.b'
 				// Property name is case insensitive. github.event and github.EVENT are the same
-				ret = &ObjectDerefNode{ret, strings.ToLower(t.Value)}
+				ret = &ObjectDerefNode{ret, strings.ToLower(t.Value), start, p.end}
 			default:
 				p.unexpected(
 					"object property dereference like 'a.b' or array element dereference like 'a.*'",
@@ -229,12 +232,12 @@ func (p *ExprParser) parsePostfixOp() ExprNode {
 			if idx == nil {
 				return nil
 			}
-			ret = &IndexAccessNode{ret, idx}
 			if p.peek().Kind != TokenKindRightBracket {
 				p.unexpected("closing bracket ']' for index access", []TokenKind{TokenKindRightBracket})
 				return nil
 			}
 			p.next() // eat ']'
+			ret = &IndexAccessNode{ret, idx, start, p.end}
 		default:
 			return ret
 		}
@@ -253,10 +256,11 @@ func (p *ExprParser) parsePrefixOp() ExprNode {
 		return nil
 	}
 
-	return &NotOpNode{o, t}
+	return &NotOpNode{o, t, p.end}
 }
 
 func (p *ExprParser) parseCompareBinOp() ExprNode {
+	start := p.peek().Offset
 	l := p.parsePrefixOp()
 	if l == nil {
 		return nil
@@ -286,10 +290,11 @@ func (p *ExprParser) parseCompareBinOp() ExprNode {
 		return nil
 	}
 
-	return &CompareOpNode{k, l, r}
+	return &CompareOpNode{k, l, r, start, p.end}
 }
 
 func (p *ExprParser) parseLogicalAnd() ExprNode {
+	start := p.peek().Offset
 	l := p.parseCompareBinOp()
 	if l == nil {
 		return nil
@@ -302,10 +307,11 @@ func (p *ExprParser) parseLogicalAnd() ExprNode {
 	if r == nil {
 		return nil
 	}
-	return &LogicalOpNode{LogicalOpNodeKindAnd, l, r}
+	return &LogicalOpNode{LogicalOpNodeKindAnd, l, r, start, p.end}
 }
 
 func (p *ExprParser) parseLogicalOr() ExprNode {
+	start := p.peek().Offset
 	l := p.parseLogicalAnd()
 	if l == nil {
 		return nil
@@ -318,7 +324,7 @@ func (p *ExprParser) parseLogicalOr() ExprNode {
 	if r == nil {
 		return nil
 	}
-	return &LogicalOpNode{LogicalOpNodeKindOr, l, r}
+	return &LogicalOpNode{LogicalOpNodeKindOr, l, r, start, p.end}
 }
 
 // Err returns an error which was caused while previous parsing.
@@ -333,6 +339,7 @@ func (p *ExprParser) Err() *ExprError {
 func (p *ExprParser) Parse(l *ExprLexer) (ExprNode, *ExprError) {
 	// Init
 	p.err = nil
+	p.end = 0
 	p.lexer = l
 	p.cur = l.Next()
 
@@ -909,6 +909,136 @@ func TestParseExpressionTokenPosition(t *testing.T) {
 	}
 }
 
+func TestParseExpressionAllNodesHaveToken(t *testing.T) {
+	input := "!(github.event.labels.*.name[0] == 'foo') && (contains(fromJSON(env.LIST), 42) || -1.5e3 <= matrix['x']) || null != true}}"
+
+	p := NewExprParser()
+	e, err := p.Parse(NewExprLexer(input))
+	if err != nil {
+		t.Fatal("Parse error:", err)
+	}
+
+	count := 0
+	VisitExprNode(e, func(n, _ ExprNode, entering bool) {
+		if !entering {
+			return
+		}
+		count++
+		tok := n.Token()
+		if tok == nil {
+			t.Errorf("token of node %#v is nil", n)
+			return
+		}
+		if tok.Offset < 0 || len(input) <= tok.Offset {
+			t.Errorf("offset of token %s of node %#v is out of source", tok, n)
+			return
+		}
+		if !strings.HasPrefix(input[tok.Offset:], tok.Value) {
+			t.Errorf("source at offset %d of token %s does not start with %q: %q", tok.Offset, tok, tok.Value, input[tok.Offset:])
+		}
+		if tok.Column != tok.Offset+1 {
+			t.Errorf("column of token %s does not match to its offset", tok)
+		}
+		start, end := n.Span()
+		if start > tok.Offset || end <= tok.Offset || len(input) < end {
+			t.Errorf("span %d..%d of node %#v does not contain its first token %s", start, end, n, tok)
+		}
+	})
+
+	// 3 logical ops, 3 compare ops, 1 not op, 2 function calls, 4 object derefs, 1 array deref,
+	// 2 index accesses, 3 variables, 2 strings, 2 ints, 1 float, 1 null and 1 bool
+	if count != 26 {
+		t.Fatalf("wanted 26 nodes but visited %d nodes", count)
+	}
+}
+
+func TestParseExpressionNodeSpan(t *testing.T) {
+	testCases := []struct {
+		what  string
+		input string
+		want  []string // Raw sources of nodes in visiting order
+	}{
+		{
+			what:  "literals",
+			input: "'foo''s'",
+			want:  []string{"'foo''s'"},
+		},
+		{
+			what:  "property dereferences",
+			input: "github.event.labels.*.name",
+			want: []string{
+				"github.event.labels.*.name",
+				"github.event.labels.*",
+				"github.event.labels",
+				"github.event",
+				"github",
+			},
+		},
+		{
+			what:  "index access",
+			input: "matrix[ 'os' ]",
+			want:  []string{"matrix[ 'os' ]", "'os'", "matrix"},
+		},
+		{
+			what:  "function call",
+			input: "contains( fromJSON(env.LIST), 42 )",
+			want: []string{
+				"contains( fromJSON(env.LIST), 42 )",
+				"fromJSON(env.LIST)",
+				"env.LIST",
+				"env",
+				"42",
+			},
+		},
+		{
+			what:  "operators",
+			input: "!a == b && c || d",
+			want: []string{
+				"!a == b && c || d",
+				"!a == b && c",
+				"!a == b",
+				"!a",
+				"a",
+				"b",
+				"c",
+				"d",
+			},
+		},
+		{
+			what:  "parentheses",
+			input: "!(a || b) && (c).d",
+			want: []string{
+				"!(a || b) && (c).d",
+				"!(a || b)",
+				"a || b",
+				"a",
+				"b",
+				"(c).d",
+				"c",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			e, err := NewExprParser().Parse(NewExprLexer(tc.input + "}}"))
+			if err != nil {
+				t.Fatal("Parse error:", err)
+			}
+			have := []string{}
+			VisitExprNode(e, func(n, _ ExprNode, entering bool) {
+				if entering {
+					start, end := n.Span()
+					have = append(have, tc.input[start:end])
+				}
+			})
+			if !cmp.Equal(tc.want, have) {
+				t.Fatal(cmp.Diff(tc.want, have))
+			}
+		})
+	}
+}
+
 func TestParseReturnFirstErrorOnMultipleErrors(t *testing.T) {
 	p := NewExprParser()
 	_, want := p.Parse(NewExprLexer(".}}"))
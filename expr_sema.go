@@ -1,7 +1,9 @@
 package actionlint
 
 import (
+	"encoding/json"
 	"fmt"
+	"math"
 	"regexp"
 	"strconv"
 	"strings"
@@ -292,6 +294,9 @@ type ExprSemanticsChecker struct {
 	untrusted             *UntrustedInputChecker
 	availableContexts     []string
 	availableSpecialFuncs []string
+	mixedTypeComparisons  []*ExprError
+	checkMixedTypeCompare bool
+	literalComparisons    []*ExprError
 }
 
 // NewExprSemanticsChecker creates new ExprSemanticsChecker instance. When checkUntrustedInput is
@@ -309,6 +314,14 @@ func NewExprSemanticsChecker(checkUntrustedInput bool) *ExprSemanticsChecker {
 	return c
 }
 
+// SetUntrustedInputs sets the search tree of untrusted inputs to detect instead of the built-in
+// BuiltinUntrustedInputs. It does nothing when checking untrusted inputs is disabled.
+func (sema *ExprSemanticsChecker) SetUntrustedInputs(roots UntrustedInputSearchRoots) {
+	if sema.untrusted != nil {
+		sema.untrusted = NewUntrustedInputChecker(roots)
+	}
+}
+
 func errorAtExpr(e ExprNode, msg string) *ExprError {
 	t := e.Token()
 	return &ExprError{
@@ -417,6 +430,14 @@ func (sema *ExprSemanticsChecker) UpdateJobs(ty *ObjectType) {
 	sema.vars["jobs"] = ty
 }
 
+// UpdateContext updates the type of the context with given name. When the context does not exist,
+// it is newly defined. This is useful to check expressions with custom contexts. The name is
+// case-insensitive.
+func (sema *ExprSemanticsChecker) UpdateContext(name string, ty ExprType) {
+	sema.ensureVarsCopied()
+	sema.vars[strings.ToLower(name)] = ty
+}
+
 // SetContextAvailability sets available context names while semantics checks. Some contexts limit
 // where they can be used.
 // https://docs.github.com/en/actions/learn-github-actions/contexts#context-availability
@@ -430,6 +451,28 @@ func (sema *ExprSemanticsChecker) SetContextAvailability(avail []string) {
 	sema.availableContexts = avail
 }
 
+// EnableMixedTypeComparisonCheck enables to detect comparisons between a number value and a string
+// value such as `github.run_number == '5'`. Operands of different types are implicitly converted
+// into numbers on comparison. The detected comparisons are not included in the errors returned from
+// Check method. They can be obtained from MixedTypeComparisons method after the check.
+func (sema *ExprSemanticsChecker) EnableMixedTypeComparisonCheck() {
+	sema.checkMixedTypeCompare = true
+}
+
+// MixedTypeComparisons returns comparisons between a number and a string detected in the last
+// Check method call. EnableMixedTypeComparisonCheck method must be called before the check.
+func (sema *ExprSemanticsChecker) MixedTypeComparisons() []*ExprError {
+	return sema.mixedTypeComparisons
+}
+
+// LiteralComparisons returns comparisons between literals of different types such as `'foo' == 1`
+// detected in the last Check method call. They are not errors since the operands are implicitly
+// converted into numbers, but the results are always the same and meaningless. The detected
+// comparisons are not included in the errors returned from Check method.
+func (sema *ExprSemanticsChecker) LiteralComparisons() []*ExprError {
+	return sema.literalComparisons
+}
+
 func (sema *ExprSemanticsChecker) checkAvailableContext(n *VariableNode) {
 	if len(sema.availableContexts) == 0 {
 		return
@@ -749,10 +792,20 @@ func (sema *ExprSemanticsChecker) checkFuncCall(n *FuncCallNode) ExprType {
 	sigs, ok := sema.funcs[callee]
 	if !ok {
 		ss := make([]string, 0, len(sema.funcs))
-		for n := range sema.funcs {
+		names := make([]string, 0, len(sema.funcs))
+		for n, sigs := range sema.funcs {
 			ss = append(ss, n)
+			if len(sigs) > 0 {
+				names = append(names, sigs[0].Name) // Suggest the name in camel case like "startsWith"
+			} else {
+				names = append(names, n)
+			}
+		}
+		msg := ""
+		if similar := findSimilarStrings(n.Callee, names); len(similar) > 0 {
+			msg = fmt.Sprintf(" did you mean %s?", quotes(similar))
 		}
-		sema.errorf(n, "undefined function %q. available functions are %s", n.Callee, sortedQuotes(ss))
+		sema.errorf(n, "undefined function %q.%s available functions are %s", n.Callee, msg, sortedQuotes(ss))
 		return AnyType{}
 	}
 
@@ -768,6 +821,11 @@ func (sema *ExprSemanticsChecker) checkFuncCall(n *FuncCallNode) ExprType {
 		if err == nil {
 			// When one of overload pass type check, overload was resolved correctly
 			sema.checkBuiltinFunctionCall(n, sig)
+			if callee == "fromjson" {
+				if ty := sema.checkFromJSONLiteral(n); ty != nil {
+					return ty
+				}
+			}
 			return sig.Ret
 		}
 		errs = append(errs, err)
@@ -779,6 +837,64 @@ func (sema *ExprSemanticsChecker) checkFuncCall(n *FuncCallNode) ExprType {
 	return AnyType{}
 }
 
+// checkFromJSONLiteral returns the type of the JSON value when the argument of fromJSON() is a
+// string literal like fromJSON('{"a": 1}'). It returns nil when the argument is not a literal so
+// that the type falls back to any type.
+func (sema *ExprSemanticsChecker) checkFromJSONLiteral(n *FuncCallNode) ExprType {
+	lit, ok := n.Args[0].(*StringNode)
+	if !ok {
+		return nil
+	}
+	var v interface{}
+	if err := json.Unmarshal([]byte(lit.Value), &v); err != nil {
+		sema.errorf(n, "argument of fromJSON() is not a valid JSON value: %s. parsing %q always fails", err.Error(), lit.Value)
+		return nil
+	}
+	return typeOfJSONValue(v)
+}
+
+// typeOfJSONValue returns the type of the value decoded by json.Unmarshal. Since properties of
+// objects are accessed in case-insensitive, the property names are in lower case.
+func typeOfJSONValue(v interface{}) ExprType {
+	switch v := v.(type) {
+	case nil:
+		return NullType{}
+	case bool:
+		return BoolType{}
+	case float64:
+		return NumberType{}
+	case string:
+		return StringType{}
+	case []interface{}:
+		var elem ExprType
+		for _, e := range v {
+			t := typeOfJSONValue(e)
+			if elem == nil {
+				elem = t
+			} else {
+				elem = elem.Merge(t)
+			}
+		}
+		if elem == nil {
+			elem = AnyType{}
+		}
+		return NewArrayType(elem)
+	case map[string]interface{}:
+		o := NewEmptyStrictObjectType()
+		for k, e := range v {
+			k = strings.ToLower(k)
+			t := typeOfJSONValue(e)
+			if p, ok := o.Props[k]; ok {
+				t = p.Merge(t)
+			}
+			o.Props[k] = t
+		}
+		return o
+	default:
+		return AnyType{}
+	}
+}
+
 func (sema *ExprSemanticsChecker) checkNotOp(n *NotOpNode) ExprType {
 	ty := sema.check(n.Operand)
 	if !(BoolType{}).Assignable(ty) {
@@ -788,14 +904,120 @@ func (sema *ExprSemanticsChecker) checkNotOp(n *NotOpNode) ExprType {
 }
 
 func (sema *ExprSemanticsChecker) checkCompareOp(n *CompareOpNode) ExprType {
-	sema.check(n.Left)
-	sema.check(n.Right)
+	lty := sema.check(n.Left)
+	rty := sema.check(n.Right)
 	// Note: Comparing values is very loose. Any value can be compared with any value without an
 	// error.
 	// https://docs.github.com/en/actions/learn-github-actions/expressions#operators
+	if !sema.checkLiteralComparison(n) && sema.checkMixedTypeCompare {
+		sema.checkMixedTypeComparison(n, lty, rty)
+	}
 	return BoolType{}
 }
 
+var reJSONNumber = regexp.MustCompile(`^-?(?:0|[1-9][0-9]*)(?:\.[0-9]+)?(?:[eE][+-]?[0-9]+)?$`)
+
+// literalToNumber converts the literal node into a number as GitHub Actions implicitly does on
+// comparing values of different types. The second return value is a name of the literal's type.
+// It is empty when the node is not a literal.
+// https://docs.github.com/en/actions/learn-github-actions/expressions#operators
+func literalToNumber(n ExprNode) (float64, string) {
+	switch n := n.(type) {
+	case *NullNode:
+		return 0, "null"
+	case *BoolNode:
+		if n.Value {
+			return 1, "bool"
+		}
+		return 0, "bool"
+	case *IntNode:
+		return float64(n.Value), "number"
+	case *FloatNode:
+		return n.Value, "number"
+	case *StringNode:
+		// Empty string is converted into 0. Other strings are parsed as JSON number
+		if n.Value == "" {
+			return 0, "string"
+		}
+		if reJSONNumber.MatchString(n.Value) {
+			if f, err := strconv.ParseFloat(n.Value, 64); err == nil {
+				return f, "string"
+			}
+		}
+		return math.NaN(), "string"
+	default:
+		return 0, ""
+	}
+}
+
+// checkLiteralComparison detects comparisons between literals of different types like `'foo' == 1`,
+// `true < 'x'` or `null == 0`. They are always evaluated to the same result after the implicit
+// conversion. It returns true when the comparison was detected.
+func (sema *ExprSemanticsChecker) checkLiteralComparison(n *CompareOpNode) bool {
+	l, lty := literalToNumber(n.Left)
+	r, rty := literalToNumber(n.Right)
+	if lty == "" || rty == "" || lty == rty {
+		return false
+	}
+
+	var b bool
+	switch n.Kind {
+	case CompareOpNodeKindLess:
+		b = l < r
+	case CompareOpNodeKindLessEq:
+		b = l <= r
+	case CompareOpNodeKindGreater:
+		b = l > r
+	case CompareOpNodeKindGreaterEq:
+		b = l >= r
+	case CompareOpNodeKindEq:
+		b = l == r
+	case CompareOpNodeKindNotEq:
+		b = l != r
+	default:
+		return false
+	}
+
+	lsrc, rsrc := n.Left.Token().Value, n.Right.Token().Value
+	err := errorfAtExpr(
+		n,
+		"comparing %s literal %s with %s literal %s by %q operator is always evaluated to %v. operands of different types are implicitly converted into numbers (%s to %s and %s to %s)",
+		lty,
+		lsrc,
+		rty,
+		rsrc,
+		n.Kind.String(),
+		b,
+		lsrc,
+		strconv.FormatFloat(l, 'g', -1, 64),
+		rsrc,
+		strconv.FormatFloat(r, 'g', -1, 64),
+	)
+	sema.literalComparisons = append(sema.literalComparisons, err)
+	return true
+}
+
+// Number and string are coerced into numbers on comparison. A non-numeric string is converted
+// into NaN so the comparison is almost always unexpected.
+// https://docs.github.com/en/actions/learn-github-actions/expressions#operators
+func (sema *ExprSemanticsChecker) checkMixedTypeComparison(n *CompareOpNode, lty, rty ExprType) {
+	_, lnum := lty.(NumberType)
+	_, lstr := lty.(StringType)
+	_, rnum := rty.(NumberType)
+	_, rstr := rty.(StringType)
+	if !(lnum && rstr) && !(lstr && rnum) {
+		return
+	}
+	err := errorfAtExpr(
+		n,
+		"%s value is compared with %s value by %q operator. the string is implicitly converted into a number and a non-numeric string becomes NaN. convert the operand explicitly with fromJSON() to compare as numbers or with format() to compare as strings",
+		lty.String(),
+		rty.String(),
+		n.Kind.String(),
+	)
+	sema.mixedTypeComparisons = append(sema.mixedTypeComparisons, err)
+}
+
 func (sema *ExprSemanticsChecker) checkLogicalOp(n *LogicalOpNode) ExprType {
 	lty := sema.check(n.Left)
 	rty := sema.check(n.Right)
@@ -840,6 +1062,8 @@ func (sema *ExprSemanticsChecker) check(expr ExprNode) ExprType {
 // while checking the expression as the second return value.
 func (sema *ExprSemanticsChecker) Check(expr ExprNode) (ExprType, []*ExprError) {
 	sema.errs = []*ExprError{}
+	sema.mixedTypeComparisons = nil
+	sema.literalComparisons = nil
 	if sema.untrusted != nil {
 		sema.untrusted.Init()
 	}
@@ -851,3 +1075,70 @@ func (sema *ExprSemanticsChecker) Check(expr ExprNode) (ExprType, []*ExprError)
 	}
 	return ty, errs
 }
+
+// ExprScope is a set of types of contexts used for inferring the type of an expression with
+// TypeOfExpression function. The types of "matrix", "steps", "needs", "inputs" and "jobs" contexts
+// depend on the workflow. Their properties are typed as any when their types are not given since
+// they are unknown.
+type ExprScope struct {
+	// Matrix is the type of "matrix" context.
+	Matrix *ObjectType
+	// Steps is the type of "steps" context.
+	Steps *ObjectType
+	// Needs is the type of "needs" context.
+	Needs *ObjectType
+	// Inputs is the type of "inputs" context.
+	Inputs *ObjectType
+	// Secrets is the type of "secrets" context. Automatically supplied secrets like "GITHUB_TOKEN"
+	// are merged into the type.
+	Secrets *ObjectType
+	// Jobs is the type of "jobs" context.
+	Jobs *ObjectType
+	// Contexts is a map from context names to their types. This is useful to infer types of
+	// expressions with custom contexts. Types in this map take precedence over the other fields.
+	Contexts map[string]ExprType
+}
+
+// TypeOfExpression returns the type of given expression syntax tree inferred in the scope. The
+// scope parameter can be nil. Types of values which are not known statically such as properties of
+// "github.event" are inferred as any type. The returned type can be converted into a string like
+// "number", "object" or "array<string>" with String method. This is useful to show types of
+// expressions in editors.
+// When the expression has some semantic error like an undefined property access, the first error
+// is returned as *ExprError.
+func TypeOfExpression(n ExprNode, scope *ExprScope) (ExprType, error) {
+	if scope == nil {
+		scope = &ExprScope{}
+	}
+	unknown := NewMapObjectType(AnyType{})
+
+	c := NewExprSemanticsChecker(false)
+	for _, ctx := range []struct {
+		update func(*ObjectType)
+		ty     *ObjectType
+	}{
+		{c.UpdateMatrix, scope.Matrix},
+		{c.UpdateSteps, scope.Steps},
+		{c.UpdateNeeds, scope.Needs},
+		{c.UpdateInputs, scope.Inputs},
+		{c.UpdateJobs, scope.Jobs},
+	} {
+		ty := ctx.ty
+		if ty == nil {
+			ty = unknown
+		}
+		ctx.update(ty)
+	}
+	if scope.Secrets != nil {
+		c.UpdateSecrets(scope.Secrets)
+	}
+	for n, ty := range scope.Contexts {
+		c.UpdateContext(n, ty)
+	}
+
+	ty, errs := c.Check(n)
+	if len(errs) > 0 {
+		return nil, errs[0]
+	}
+	return ty, nil
+}
@@ -636,7 +636,7 @@ func TestExprSemanticsCheckOK(t *testing.T) {
 		{
 			what:         "non-special function",
 			input:        "fromJSON('{}')",
-			expected:     AnyType{},
+			expected:     NewEmptyStrictObjectType(),
 			availSPFuncs: []string{"always"},
 		},
 		{
@@ -889,6 +889,20 @@ func TestExprSemanticsCheckError(t *testing.T) {
 				"undefined function \"foooo\"",
 			},
 		},
+		{
+			what:  "undefined function with similar function name",
+			input: "startWith('foo', 'f')",
+			expected: []string{
+				"undefined function \"startWith\". did you mean \"startsWith\"?",
+			},
+		},
+		{
+			what:  "undefined function without similar function name",
+			input: "lower('FOO')",
+			expected: []string{
+				"undefined function \"lower\". available functions are",
+			},
+		},
 		{
 			what:  "wrong number of arguments at function call",
 			input: "contains('foo')",
@@ -1254,6 +1268,512 @@ func TestExprSematincsCheckerUpdateDispatchInputsVarType(t *testing.T) {
 	}
 }
 
+func TestExprSemanticsCheckerUpdateContext(t *testing.T) {
+	// Type of custom context defined by external check
+	ty := NewStrictObjectType(map[string]ExprType{
+		"name": StringType{},
+		"tags": NewArrayType(StringType{}),
+		"meta": NewMapObjectType(NumberType{}),
+	})
+
+	testCases := []struct {
+		what  string
+		input string
+		want  ExprType
+		err   string
+	}{
+		{
+			what:  "property access",
+			input: "deploy.name",
+			want:  StringType{},
+		},
+		{
+			what:  "case insensitive context name",
+			input: "DEPLOY.tags",
+			want:  NewArrayType(StringType{}),
+		},
+		{
+			what:  "function call with context",
+			input: "contains(deploy.tags, 'prod')",
+			want:  BoolType{},
+		},
+		{
+			what:  "map object",
+			input: "deploy.meta.replicas",
+			want:  NumberType{},
+		},
+		{
+			what:  "unknown property",
+			input: "deploy.version",
+			err:   "property \"version\" is not defined in object type",
+		},
+		{
+			what:  "type mismatch",
+			input: "startsWith(deploy.tags, 'v')",
+			err:   "1st argument of function call is not assignable",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			e, err := NewExprParser().Parse(NewExprLexer(tc.input + "}}"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			c := NewExprSemanticsChecker(false)
+			c.UpdateContext("Deploy", ty)
+			have, errs := c.Check(e)
+
+			if tc.err != "" {
+				if len(errs) != 1 {
+					t.Fatalf("wanted one error but got %v", errs)
+				}
+				if !strings.Contains(errs[0].Message, tc.err) {
+					t.Fatalf("error message %q does not contain %q", errs[0].Message, tc.err)
+				}
+				return
+			}
+
+			if len(errs) > 0 {
+				t.Fatal(errs)
+			}
+			if !cmp.Equal(tc.want, have) {
+				t.Fatal(cmp.Diff(tc.want, have))
+			}
+			if !tc.want.Assignable(have) {
+				t.Fatalf("type %s is not assignable to %s", have, tc.want)
+			}
+		})
+	}
+
+	// Check global value is not polluted
+	if _, ok := BuiltinGlobalVariableTypes["deploy"]; ok {
+		t.Fatal("custom context was added to global variables")
+	}
+}
+
+func TestTypeOfExpression(t *testing.T) {
+	matrix := &ExprScope{
+		Matrix: NewStrictObjectType(map[string]ExprType{
+			"os":      StringType{},
+			"node":    NewArrayType(NumberType{}),
+			"include": NewArrayType(NewStrictObjectType(map[string]ExprType{"arch": StringType{}})),
+		}),
+	}
+
+	testCases := []struct {
+		what  string
+		input string
+		scope *ExprScope
+		want  string
+		err   string
+	}{
+		{
+			what:  "event payload",
+			input: "github.event.pull_request.number",
+			want:  "any",
+		},
+		{
+			what:  "event payload object",
+			input: "github.event",
+			want:  "object",
+		},
+		{
+			what:  "number",
+			input: "strategy.job-index",
+			want:  "number",
+		},
+		{
+			what:  "function call",
+			input: "fromJSON(github.event.pull_request.number)",
+			want:  "any",
+		},
+		{
+			what:  "matrix value",
+			input: "matrix.os",
+			scope: matrix,
+			want:  "string",
+		},
+		{
+			what:  "matrix array",
+			input: "matrix.node",
+			scope: matrix,
+			want:  "array<number>",
+		},
+		{
+			what:  "matrix array index",
+			input: "matrix.node[0]",
+			scope: matrix,
+			want:  "number",
+		},
+		{
+			what:  "matrix array of objects",
+			input: "matrix.include[1].arch",
+			scope: matrix,
+			want:  "string",
+		},
+		{
+			what:  "array filter",
+			input: "matrix.include.*.arch",
+			scope: matrix,
+			want:  "array<string>",
+		},
+		{
+			what:  "unknown matrix",
+			input: "matrix.node[0]",
+			want:  "any",
+		},
+		{
+			what:  "custom context",
+			input: "deploy.tags",
+			scope: &ExprScope{Contexts: map[string]ExprType{"deploy": NewStrictObjectType(map[string]ExprType{"tags": NewArrayType(StringType{})})}},
+			want:  "array<string>",
+		},
+		{
+			what:  "undefined matrix property",
+			input: "matrix.arch",
+			scope: matrix,
+			err:   "property \"arch\" is not defined in object type",
+		},
+		{
+			what:  "undefined context",
+			input: "foo.bar",
+			err:   "undefined variable \"foo\"",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			e, err := NewExprParser().Parse(NewExprLexer(tc.input + "}}"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ty, tyErr := TypeOfExpression(e, tc.scope)
+			if tc.err != "" {
+				if tyErr == nil {
+					t.Fatalf("wanted error but got type %s", ty)
+				}
+				if !strings.Contains(tyErr.Error(), tc.err) {
+					t.Fatalf("error message %q does not contain %q", tyErr.Error(), tc.err)
+				}
+				return
+			}
+			if tyErr != nil {
+				t.Fatal(tyErr)
+			}
+			if have := ty.String(); have != tc.want {
+				t.Fatalf("wanted type %q but got %q", tc.want, have)
+			}
+		})
+	}
+
+	// Check global value is not polluted
+	if ty := BuiltinGlobalVariableTypes["matrix"].(*ObjectType); len(ty.Props) > 0 || !ty.IsStrict() {
+		t.Fatalf("matrix type of global variables was changed: %s", ty)
+	}
+}
+
+func TestExprSemanticsCheckerFromJSONLiteral(t *testing.T) {
+	testCases := []struct {
+		what  string
+		input string
+		want  ExprType
+		err   string
+	}{
+		{
+			what:  "object",
+			input: `fromJSON('{"a": 1, "B": "foo"}')`,
+			want: NewStrictObjectType(map[string]ExprType{
+				"a": NumberType{},
+				"b": StringType{},
+			}),
+		},
+		{
+			what:  "property of object",
+			input: `fromJSON('{"a": 1}').a`,
+			want:  NumberType{},
+		},
+		{
+			what:  "property name is case-insensitive",
+			input: `fromJSON('{"FooBar": true}').foobar`,
+			want:  BoolType{},
+		},
+		{
+			what:  "nested object",
+			input: `fromJSON('{"a": {"b": [1, 2]}}').a.b[0]`,
+			want:  NumberType{},
+		},
+		{
+			what:  "array of objects",
+			input: `fromJSON('[{"os": "linux"}, {"os": "macos", "arch": "arm64"}]').*.arch`,
+			want:  &ArrayType{Elem: StringType{}, Deref: true},
+		},
+		{
+			what:  "array of mixed values",
+			input: `fromJSON('[1, "foo"]')`,
+			want:  NewArrayType(StringType{}),
+		},
+		{
+			what:  "empty array",
+			input: `fromJSON('[]')`,
+			want:  NewArrayType(AnyType{}),
+		},
+		{
+			what:  "scalar values",
+			input: `fromJSON('true')`,
+			want:  BoolType{},
+		},
+		{
+			what:  "null",
+			input: `fromJSON('null')`,
+			want:  NullType{},
+		},
+		{
+			what:  "non-literal argument",
+			input: `fromJSON(github.event.inputs.json).a`,
+			want:  AnyType{},
+		},
+		{
+			what:  "undefined property",
+			input: `fromJSON('{"a": 1}').b`,
+			err:   `property "b" is not defined in object type {a: number}`,
+		},
+		{
+			what:  "deref of number",
+			input: `fromJSON('{"a": 1}').a.b`,
+			err:   `receiver of object dereference "b" must be type of object but got "number"`,
+		},
+		{
+			what:  "invalid JSON",
+			input: `fromJSON('{"a": 1')`,
+			err:   `argument of fromJSON() is not a valid JSON value: unexpected end of JSON input. parsing "{\"a\": 1" always fails`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			e, err := NewExprParser().Parse(NewExprLexer(tc.input + "}}"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			c := NewExprSemanticsChecker(false)
+			have, errs := c.Check(e)
+
+			if tc.err != "" {
+				if len(errs) != 1 {
+					t.Fatalf("wanted one error but got %v", errs)
+				}
+				if !strings.Contains(errs[0].Message, tc.err) {
+					t.Fatalf("error message %q does not contain %q", errs[0].Message, tc.err)
+				}
+				return
+			}
+
+			if len(errs) > 0 {
+				t.Fatal(errs)
+			}
+			if !cmp.Equal(tc.want, have) {
+				t.Fatal(cmp.Diff(tc.want, have))
+			}
+		})
+	}
+}
+
+func TestExprSemanticsCheckerMixedTypeComparison(t *testing.T) {
+	testCases := []struct {
+		what  string
+		input string
+		want  []string
+	}{
+		{
+			what:  "number with string literal",
+			input: "strategy.job-index == '0'",
+			want:  []string{`number value is compared with string value by "==" operator`},
+		},
+		{
+			what:  "string literal with number",
+			input: "'0' != strategy.job-index",
+			want:  []string{`string value is compared with number value by "!=" operator`},
+		},
+		{
+			what:  "number literal with string context",
+			input: "github.ref_name < 10",
+			want:  []string{`string value is compared with number value by "<" operator`},
+		},
+		{
+			what:  "greater than",
+			input: "strategy.job-total > '10'",
+			want:  []string{`number value is compared with string value by ">" operator`},
+		},
+		{
+			what:  "multiple comparisons",
+			input: "strategy.job-index == '0' || strategy.job-total == '1'",
+			want: []string{
+				`number value is compared with string value by "==" operator`,
+				`number value is compared with string value by "==" operator`,
+			},
+		},
+		{
+			what:  "numbers",
+			input: "strategy.job-index == 0",
+		},
+		{
+			what:  "strings",
+			input: "github.ref_name == 'main'",
+		},
+		{
+			what:  "explicit conversion with fromJSON",
+			input: "strategy.job-index == fromJSON('0')",
+		},
+		{
+			what:  "explicit conversion with format",
+			input: "format('{0}', strategy.job-index) == '0'",
+		},
+		{
+			what:  "any type is not mixed",
+			input: "github.event.number == '5'",
+		},
+		{
+			what:  "boolean with string",
+			input: "github.event.forced == 'true'",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			e, err := NewExprParser().Parse(NewExprLexer(tc.input + "}}"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			c := NewExprSemanticsChecker(false)
+			_, errs := c.Check(e)
+			if len(errs) > 0 {
+				t.Fatal(errs)
+			}
+			if found := c.MixedTypeComparisons(); len(found) > 0 {
+				t.Fatalf("comparisons were detected though the check was not enabled: %v", found)
+			}
+
+			c = NewExprSemanticsChecker(false)
+			c.EnableMixedTypeComparisonCheck()
+			_, errs = c.Check(e)
+			if len(errs) > 0 {
+				t.Fatal(errs)
+			}
+			found := c.MixedTypeComparisons()
+			if len(found) != len(tc.want) {
+				t.Fatalf("wanted %d comparisons but got %d: %v", len(tc.want), len(found), found)
+			}
+			for i, err := range found {
+				if !strings.Contains(err.Message, tc.want[i]) {
+					t.Errorf("message %q does not contain %q", err.Message, tc.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestExprSemanticsCheckerLiteralComparison(t *testing.T) {
+	testCases := []struct {
+		what  string
+		input string
+		want  []string
+	}{
+		{
+			what:  "string with number",
+			input: "'foo' == 1",
+			want:  []string{`comparing string literal 'foo' with number literal 1 by "==" operator is always evaluated to false. operands of different types are implicitly converted into numbers ('foo' to NaN and 1 to 1)`},
+		},
+		{
+			what:  "numeric string with number",
+			input: "'1.5' < 2",
+			want:  []string{`comparing string literal '1.5' with number literal 2 by "<" operator is always evaluated to true`},
+		},
+		{
+			what:  "non-JSON number string with number",
+			input: "'0x10' == 16",
+			want:  []string{`is always evaluated to false. operands of different types are implicitly converted into numbers ('0x10' to NaN and 16 to 16)`},
+		},
+		{
+			what:  "bool with string",
+			input: "true < 'x'",
+			want:  []string{`comparing bool literal true with string literal 'x' by "<" operator is always evaluated to false`},
+		},
+		{
+			what:  "string with bool",
+			input: "'true' != true",
+			want:  []string{`is always evaluated to true. operands of different types are implicitly converted into numbers ('true' to NaN and true to 1)`},
+		},
+		{
+			what:  "null with number",
+			input: "null == 0",
+			want:  []string{`comparing null literal null with number literal 0 by "==" operator is always evaluated to true`},
+		},
+		{
+			what:  "null with empty string",
+			input: "'' >= null",
+			want:  []string{`comparing string literal '' with null literal null by ">=" operator is always evaluated to true`},
+		},
+		{
+			what:  "null with bool",
+			input: "null == false",
+			want:  []string{`is always evaluated to true. operands of different types are implicitly converted into numbers (null to 0 and false to 0)`},
+		},
+		{
+			what:  "nested comparisons",
+			input: "'a' == 1 && (null != 'b')",
+			want: []string{
+				`comparing string literal 'a' with number literal 1`,
+				`comparing null literal null with string literal 'b'`,
+			},
+		},
+		{
+			what:  "literals of the same type",
+			input: "'a' == 'b' && 1 < 2.5 && true != false && null == null",
+		},
+		{
+			what:  "literal with context",
+			input: "github.ref_name == 1",
+		},
+		{
+			what:  "literal with function call",
+			input: "fromJSON('1') == 1",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			e, err := NewExprParser().Parse(NewExprLexer(tc.input + "}}"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			c := NewExprSemanticsChecker(false)
+			c.EnableMixedTypeComparisonCheck()
+			_, errs := c.Check(e)
+			if len(errs) > 0 {
+				t.Fatal(errs)
+			}
+			if found := c.MixedTypeComparisons(); len(tc.want) > 0 && len(found) > 0 {
+				t.Fatalf("comparisons between literals should not be detected as mixed type comparisons: %v", found)
+			}
+			found := c.LiteralComparisons()
+			if len(found) != len(tc.want) {
+				t.Fatalf("wanted %d comparisons but got %d: %v", len(tc.want), len(found), found)
+			}
+			for i, err := range found {
+				if !strings.Contains(err.Message, tc.want[i]) {
+					t.Errorf("message %q does not contain %q", err.Message, tc.want[i])
+				}
+			}
+		})
+	}
+}
+
 func testObjectPropertiesAreInLowerCase(t *testing.T, ty ExprType) {
 	switch ty := ty.(type) {
 	case *ObjectType:
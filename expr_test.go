@@ -49,6 +49,7 @@ func BenchmarkExprRealWorld(b *testing.B) {
 	}
 
 	b.Run("Lex", func(b *testing.B) {
+		b.ReportAllocs()
 		for i := 0; i < b.N; i++ {
 			for _, expr := range exprs {
 				l := NewExprLexer(expr)
@@ -381,6 +381,11 @@ type ArrayType struct {
 	Deref bool
 }
 
+// NewArrayType creates new ArrayType instance whose elements are typed as the given type.
+func NewArrayType(elem ExprType) *ArrayType {
+	return &ArrayType{Elem: elem, Deref: false}
+}
+
 func (ty *ArrayType) String() string {
 	return fmt.Sprintf("array<%s>", ty.Elem.String())
 }
@@ -20,6 +20,19 @@ func TestExprNewMapObjectType(t *testing.T) {
 	}
 }
 
+func TestExprNewArrayType(t *testing.T) {
+	a := NewArrayType(StringType{})
+	if _, ok := a.Elem.(StringType); !ok {
+		t.Fatalf("element type is not string: %v", a.Elem)
+	}
+	if a.Deref {
+		t.Fatalf("array should not be derived from object filter: %v", a)
+	}
+	if s := a.String(); s != "array<string>" {
+		t.Fatalf("unexpected string representation: %q", s)
+	}
+}
+
 func TestExprObjectTypeSetStrict(t *testing.T) {
 	o := NewEmptyObjectType()
 	if o.IsStrict() || !o.IsLoose() {
@@ -27,7 +27,7 @@ func FuzzCheck(data []byte) int {
 		actionlint.NewRuleEvents(),
 		actionlint.NewRuleGlob(),
 		actionlint.NewRuleJobNeeds(),
-		actionlint.NewRuleAction(ac),
+		actionlint.NewRuleAction(ac, data),
 		actionlint.NewRuleEnvVar(),
 		actionlint.NewRuleID(),
 		actionlint.NewRuleExpression(ac, wc),
@@ -0,0 +1,84 @@
+package actionlint
+
+import (
+	"crypto/sha256"
+	"sync"
+)
+
+type lintCacheEntry struct {
+	sum  [sha256.Size]byte
+	errs []*Error
+}
+
+type lintCacheConfig struct {
+	sum [sha256.Size]byte
+	cfg *Config
+}
+
+// lintCache is a cache of lint results used by Linter.LintWithCache. Results are keyed by file path
+// and they are reused while SHA-256 hash of the file content and the config file content is not
+// changed. One instance is owned by one Linter instance and it is thread-safe.
+type lintCache struct {
+	mu      sync.Mutex
+	results map[string]*lintCacheEntry
+	configs map[string]*lintCacheConfig // Key is root directory of project
+}
+
+func newLintCache() *lintCache {
+	return &lintCache{
+		results: map[string]*lintCacheEntry{},
+		configs: map[string]*lintCacheConfig{},
+	}
+}
+
+// projectConfig returns the config of the project and hash of the config file. The config file is
+// read every time to detect its changes, but it is parsed again only when its content was changed.
+// The returned config is nil when the project has no config file.
+func (c *lintCache) projectConfig(p *Project) (*Config, [sha256.Size]byte, error) {
+	path, src := p.findConfigFile()
+
+	h := sha256.New()
+	if path != "" {
+		h.Write([]byte(path))
+		h.Write([]byte{0})
+		h.Write(src)
+	}
+	var sum [sha256.Size]byte
+	copy(sum[:], h.Sum(nil))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.configs[p.root]; ok && e.sum == sum {
+		return e.cfg, sum, nil
+	}
+
+	var cfg *Config
+	if path != "" {
+		var err error
+		cfg, err = parseConfig(src, path)
+		if err != nil {
+			return nil, sum, err
+		}
+	}
+	c.configs[p.root] = &lintCacheConfig{sum, cfg}
+	return cfg, sum, nil
+}
+
+// get returns the cached errors of the file. The second return value is false when no result is
+// cached for the file or the cached result was created with a different hash.
+func (c *lintCache) get(path string, sum [sha256.Size]byte) ([]*Error, bool) {
+	c.mu.Lock()
+	e, ok := c.results[path]
+	c.mu.Unlock()
+	if !ok || e.sum != sum {
+		return nil, false
+	}
+	return e.errs, true
+}
+
+func (c *lintCache) set(path string, sum [sha256.Size]byte, errs []*Error) {
+	c.mu.Lock()
+	c.results[path] = &lintCacheEntry{sum, errs}
+	c.mu.Unlock()
+}
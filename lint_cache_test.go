@@ -0,0 +1,199 @@
+package actionlint
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestLinterLintWithCacheContentChange(t *testing.T) {
+	var out bytes.Buffer
+	l, err := NewLinter(&out, &LinterOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	proj := &Project{root: t.TempDir()}
+	content := []byte("on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo ${{ unknown }}\n")
+
+	errs, cached, err := l.LintWithCache("test.yaml", content, proj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cached {
+		t.Fatal("result of the first lint should not be cached")
+	}
+	if len(errs) != 1 || !strings.Contains(errs[0].Message, `undefined variable "unknown"`) {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	first := out.String()
+
+	out.Reset()
+	errs2, cached, err := l.LintWithCache("test.yaml", content, proj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cached {
+		t.Fatal("result of unchanged content should be cached")
+	}
+	if len(errs2) != 1 || errs2[0] != errs[0] {
+		t.Fatalf("cached errors are different from the first result: %v", errs2)
+	}
+	if out.String() != first {
+		t.Fatalf("output of cached result is different from the first output: %q vs %q", out.String(), first)
+	}
+
+	// Result of another path is not shared even if the content is the same
+	if _, cached, err := l.LintWithCache("other.yaml", content, proj); err != nil || cached {
+		t.Fatalf("result of other file should not be cached: cached=%v err=%v", cached, err)
+	}
+
+	fixed := bytes.ReplaceAll(content, []byte("unknown"), []byte("github.sha"))
+	errs, cached, err = l.LintWithCache("test.yaml", fixed, proj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cached {
+		t.Fatal("result of changed content should not be cached")
+	}
+	if len(errs) != 0 {
+		t.Fatalf("wanted no error but got %v", errs)
+	}
+}
+
+func TestLinterLintWithCacheConfigChange(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, ".github"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	cfgPath := filepath.Join(root, ".github", "actionlint.yaml")
+	proj := &Project{root: root}
+
+	l, err := NewLinter(io.Discard, &LinterOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := []byte("on: push\njobs:\n  test:\n    runs-on: my-runner\n    steps:\n      - run: echo\n")
+
+	lint := func(wantCached bool, wantErrs int) {
+		t.Helper()
+		errs, cached, err := l.LintWithCache("test.yaml", content, proj)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cached != wantCached {
+			t.Fatalf("wanted cached=%v but got cached=%v", wantCached, cached)
+		}
+		if len(errs) != wantErrs {
+			t.Fatalf("wanted %d errors but got %v", wantErrs, errs)
+		}
+	}
+
+	// No config file
+	lint(false, 1)
+	lint(true, 1)
+
+	// Config file was created
+	if err := os.WriteFile(cfgPath, []byte("self-hosted-runner:\n  labels: [my-runner]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	lint(false, 0)
+	lint(true, 0)
+
+	// Config file was changed
+	if err := os.WriteFile(cfgPath, []byte("self-hosted-runner:\n  labels: [other-runner]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	lint(false, 1)
+	lint(true, 1)
+
+	// Config file was broken
+	if err := os.WriteFile(cfgPath, []byte("self-hosted-runner: [\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := l.LintWithCache("test.yaml", content, proj); err == nil {
+		t.Fatal("error did not occur for broken config file")
+	}
+
+	// Config file was removed
+	if err := os.Remove(cfgPath); err != nil {
+		t.Fatal(err)
+	}
+	lint(false, 1)
+	lint(true, 1)
+}
+
+func TestLinterLintWithCacheConcurrent(t *testing.T) {
+	l, err := NewLinter(io.Discard, &LinterOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.defaultConfig = &Config{}
+	content := []byte("on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo ${{ unknown }}\n")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				errs, _, err := l.LintWithCache("test.yaml", content, nil)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if len(errs) != 1 {
+					t.Errorf("wanted 1 error but got %v", errs)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// Unchanged files are skipped by hashing their content. On testdata/bench/many_scripts.yaml,
+// returning the cached result was about 90x faster than linting the file again (500us -> 5.6us per
+// run).
+func BenchmarkLintWithCache(b *testing.B) {
+	content, err := os.ReadFile(filepath.Join("testdata", "bench", "many_scripts.yaml"))
+	if err != nil {
+		b.Fatal(err)
+	}
+	proj := &Project{root: b.TempDir()}
+
+	for _, cached := range []bool{false, true} {
+		name := "lint"
+		if cached {
+			name = "skip"
+		}
+		b.Run(name, func(b *testing.B) {
+			l, err := NewLinter(io.Discard, &LinterOptions{})
+			if err != nil {
+				b.Fatal(err)
+			}
+			if _, _, err := l.LintWithCache("test.yaml", content, proj); err != nil {
+				b.Fatal(err)
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if !cached {
+					l.lintCache = newLintCache()
+				}
+				errs, hit, err := l.LintWithCache("test.yaml", content, proj)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if hit != cached {
+					b.Fatalf("wanted cached=%v but got %v", cached, hit)
+				}
+				if len(errs) > 0 {
+					b.Fatal("some error occurred:", errs)
+				}
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package actionlint
 
 import (
 	"context"
+	"crypto/sha256"
 	"errors"
 	"fmt"
 	"io"
@@ -11,11 +12,12 @@ import (
 	"runtime"
 	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/fatih/color"
 	"github.com/mattn/go-colorable"
-	"golang.org/x/sync/errgroup"
 	"golang.org/x/sync/semaphore"
 )
 
@@ -84,11 +86,22 @@ type LinterOptions struct {
 	// WorkingDir is a file path to the current working directory. When this value is empty, os.Getwd
 	// will be used to get a working directory.
 	WorkingDir string
+	// Severities is a map from rule names to severities to override severities of errors reported by
+	// the rules. It takes precedence over "severity" configuration in config file.
+	Severities map[string]Severity
+	// BaselineFile is a path to baseline file. Errors whose fingerprints are listed in the file are
+	// not reported. Empty string means no baseline is used.
+	BaselineFile string
+	// RelativeTo is a directory path which file paths of errors are made relative to. When this
+	// value is empty, file paths are relative to the working directory. This is useful when the
+	// command is run in a subdirectory of a monorepo.
+	RelativeTo string
 	// More options will come here
 }
 
 // Linter is struct to lint workflow files.
 type Linter struct {
+	ignored       int64 // Accessed atomically. Put at the first to be 64-bit aligned on 32-bit platforms
 	projects      *Projects
 	out           io.Writer
 	logOut        io.Writer
@@ -100,6 +113,13 @@ type Linter struct {
 	defaultConfig *Config
 	errFmt        *ErrorFormatter
 	cwd           string
+	exprCache     *exprCache
+	severities    map[string]Severity
+	customRules   []func() Rule
+	customNames   []string
+	baseline      *baseline
+	lintCache     *lintCache
+	relTo         string
 }
 
 // NewLinter creates a new Linter instance.
@@ -158,6 +178,15 @@ func NewLinter(out io.Writer, opts *LinterOptions) (*Linter, error) {
 		formatter = f
 	}
 
+	var base *baseline
+	if opts.BaselineFile != "" {
+		b, err := readBaselineFile(opts.BaselineFile)
+		if err != nil {
+			return nil, err
+		}
+		base = b
+	}
+
 	cwd := opts.WorkingDir
 	if cwd == "" {
 		if d, err := os.Getwd(); err == nil {
@@ -165,7 +194,13 @@ func NewLinter(out io.Writer, opts *LinterOptions) (*Linter, error) {
 		}
 	}
 
+	relTo := opts.RelativeTo
+	if relTo != "" && !filepath.IsAbs(relTo) {
+		relTo = filepath.Join(cwd, relTo)
+	}
+
 	return &Linter{
+		0,
 		NewProjects(),
 		out,
 		lout,
@@ -177,9 +212,77 @@ func NewLinter(out io.Writer, opts *LinterOptions) (*Linter, error) {
 		cfg,
 		formatter,
 		cwd,
+		newExprCache(maxExprCacheEntries),
+		opts.Severities,
+		nil,
+		nil,
+		base,
+		newLintCache(),
+		relTo,
 	}, nil
 }
 
+// RegisterRule registers a custom rule which is applied to workflows in addition to the built-in
+// rules. The newRule parameter is a function to create the rule instance. It is called for each
+// workflow file since a rule instance keeps errors found in the file and files are linted in
+// parallel. The rule is usually defined by embedding RuleBase created with NewRuleBase. Rules must
+// be registered before linting any files. To show the description of the rule in the list of rules
+// returned from Rules, define Description() string method on the rule.
+func (l *Linter) RegisterRule(newRule func() Rule) {
+	l.customRules = append(l.customRules, newRule)
+	l.customNames = append(l.customNames, newRule().Name())
+}
+
+// checkSeverityRuleNames checks rule names in LinterOptions.Severities and "severity" configuration
+// are names of built-in rules or custom rules. They are checked on linting files instead of
+// creating the Linter instance since custom rules are registered after that. The cfg parameter
+// can be nil.
+func (l *Linter) checkSeverityRuleNames(cfg *Config) error {
+	for n := range l.severities {
+		if !isKnownRuleName(n, l.customNames) {
+			return fmt.Errorf("unknown rule name %q for overriding severity. available rule names are %s", n, l.ruleNames())
+		}
+	}
+	if cfg == nil {
+		return nil
+	}
+	for n := range cfg.Severity {
+		if !isKnownRuleName(n, l.customNames) {
+			where := "invalid config"
+			if cfg.path != "" {
+				where = fmt.Sprintf("invalid config file %q", cfg.path)
+			}
+			return fmt.Errorf("%s: unknown rule name %q at \"severity\". available rule names are %s", where, n, l.ruleNames())
+		}
+	}
+	return nil
+}
+
+func (l *Linter) ruleNames() string {
+	ns := append([]string{}, allRuleNames...)
+	return sortedQuotes(append(ns, l.customNames...))
+}
+
+// Rules returns information of all rules applied by the linter. Custom rules registered with
+// RegisterRule are listed after the built-in rules. When a custom rule has Description() string
+// method, it is used as the description of the rule. When a custom rule has DefaultSeverity()
+// Severity method, it is used as the severity of the rule. Otherwise the severity is "error".
+func (l *Linter) Rules() []*RuleInfo {
+	rs := BuiltinRules()
+	for _, f := range l.customRules {
+		r := f()
+		info := &RuleInfo{Name: r.Name(), Severity: SeverityError.String()}
+		if d, ok := r.(interface{ Description() string }); ok {
+			info.Description = d.Description()
+		}
+		if s, ok := r.(interface{ DefaultSeverity() Severity }); ok {
+			info.Severity = s.DefaultSeverity().String()
+		}
+		rs = append(rs, info)
+	}
+	return rs
+}
+
 func (l *Linter) log(args ...interface{}) {
 	if l.logLevel < LogLevelVerbose {
 		return
@@ -228,22 +331,101 @@ func (l *Linter) GenerateDefaultConfig(dir string) error {
 
 // LintRepository lints YAML workflow files and outputs the errors to given writer. It finds the nearest
 // `.github/workflows` directory based on `dir` and applies lint rules to all YAML workflow files
-// under the directory.
+// under the directory. Workflow files and action metadata files matching glob patterns in "files"
+// section of the configuration are also linted.
 func (l *Linter) LintRepository(dir string) ([]*Error, error) {
 	l.log("Linting all workflow files in repository:", dir)
 
+	proj, workflows, actions, err := l.findRepositoryFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	errs, err := l.LintFiles(workflows, proj)
+	if err != nil {
+		return errs, err
+	}
+	for _, path := range actions {
+		es, err := l.lintActionMetadataFile(path, proj)
+		if err != nil {
+			return errs, err
+		}
+		errs = append(errs, es...)
+	}
+
+	l.log("Linted", len(workflows), "workflow files and", len(actions), "action metadata files")
+	return errs, nil
+}
+
+// findRepositoryFiles detects the project from the given directory and collects all YAML workflow
+// files in its workflows directory. Workflow files and action metadata files matching the patterns
+// in "files" section of the configuration are also collected. When a file matches both kinds of
+// patterns, it is treated as action metadata file.
+func (l *Linter) findRepositoryFiles(dir string) (*Project, []string, []string, error) {
 	proj := l.projects.At(dir)
 	if proj == nil {
-		return nil, fmt.Errorf("no project was found in any parent directories of %q. check workflows directory is put correctly in your Git repository", dir)
+		return nil, nil, nil, fmt.Errorf("no project was found in any parent directories of %q. check workflows directory is put correctly in your Git repository", dir)
 	}
 
 	l.log("Detected project:", proj.RootDir())
-	wd := proj.WorkflowsDir()
-	return l.LintDir(wd, proj)
+	workflows, err := l.findWorkflowFiles(proj.WorkflowsDir())
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	cfg := l.defaultConfig
+	if cfg == nil {
+		cfg, err = proj.Config()
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	if cfg == nil {
+		return proj, workflows, nil, nil
+	}
+
+	actions, err := proj.findFiles(cfg.Files.Actions)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	extra, err := proj.findFiles(cfg.Files.Workflows)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if len(extra) == 0 {
+		return proj, workflows, actions, nil
+	}
+
+	seen := make(map[string]struct{}, len(workflows)+len(actions))
+	for _, f := range workflows {
+		seen[f] = struct{}{}
+	}
+	for _, f := range actions {
+		seen[f] = struct{}{}
+	}
+	for _, f := range extra {
+		if _, ok := seen[f]; !ok {
+			workflows = append(workflows, f)
+		}
+	}
+	sort.Strings(workflows)
+	l.log("Collected", len(extra), "workflow files and", len(actions), "action metadata files from \"files\" configuration")
+
+	return proj, workflows, actions, nil
 }
 
 // LintDir lints all YAML workflow files in the given directory recursively.
 func (l *Linter) LintDir(dir string, project *Project) ([]*Error, error) {
+	files, err := l.findWorkflowFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	return l.LintFiles(files, project)
+}
+
+// findWorkflowFiles collects all YAML files in the given directory recursively. The returned file
+// paths are sorted.
+func (l *Linter) findWorkflowFiles(dir string) ([]string, error) {
 	files := []string{}
 	if err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
@@ -268,12 +450,17 @@ func (l *Linter) LintDir(dir string, project *Project) ([]*Error, error) {
 	// To make output deterministic, sort order of file paths
 	sort.Strings(files)
 
-	return l.LintFiles(files, project)
+	return files, nil
 }
 
 // LintFiles lints YAML workflow files and outputs the errors to given writer. It applies lint
 // rules to all given files. The project parameter can be nil. In the case, a project is detected
 // from the file path.
+// Files are linted concurrently. The number of files linted at the same time is bounded by
+// GOMAXPROCS. Errors are output in the order of the given file paths so the output is
+// deterministic. When some files could not be linted (e.g. they could not be read), other files
+// are still linted. In the case, this method returns the errors found in other files as the first
+// return value along with the fatal error as the second return value.
 func (l *Linter) LintFiles(filepaths []string, project *Project) ([]*Error, error) {
 	n := len(filepaths)
 	switch n {
@@ -286,17 +473,20 @@ func (l *Linter) LintFiles(filepaths []string, project *Project) ([]*Error, erro
 	l.log("Linting", n, "files")
 
 	cwd := l.cwd
-	proc := newConcurrentProcess(runtime.NumCPU())
-	sema := semaphore.NewWeighted(int64(runtime.NumCPU()))
+	par := runtime.GOMAXPROCS(0)
+	proc := newConcurrentProcess(par)
+	sema := semaphore.NewWeighted(int64(par))
 	ctx := context.Background()
 	dbg := l.debugWriter()
 	acf := NewLocalActionsCacheFactory(dbg)
 	rwcf := NewLocalReusableWorkflowCacheFactory(cwd, dbg)
+	names := &workflowNames{}
 
 	type workspace struct {
 		path string
 		errs []*Error
 		src  []byte
+		err  error
 	}
 
 	ws := make([]workspace, 0, len(filepaths))
@@ -304,7 +494,7 @@ func (l *Linter) LintFiles(filepaths []string, project *Project) ([]*Error, erro
 		ws = append(ws, workspace{path: p})
 	}
 
-	eg := errgroup.Group{}
+	var wg sync.WaitGroup
 	for i := range ws {
 		// Each element of ws is accessed by single goroutine so mutex is unnecessary
 		w := &ws[i]
@@ -317,13 +507,19 @@ func (l *Linter) LintFiles(filepaths []string, project *Project) ([]*Error, erro
 		ac := acf.GetCache(p) // #173
 		rwc := rwcf.GetCache(p)
 
-		eg.Go(func() error {
-			// Bound concurrency on reading files to avoid "too many files to open" error (issue #3)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			// Bound the number of files linted at once. This also avoids "too many files to open"
+			// error on reading many files (issue #3)
 			sema.Acquire(ctx, 1)
+			defer sema.Release(1)
+
 			src, err := os.ReadFile(w.path)
-			sema.Release(1)
 			if err != nil {
-				return fmt.Errorf("could not read %q: %w", w.path, err)
+				w.err = fmt.Errorf("could not read %q: %w", w.path, err)
+				return
 			}
 
 			if cwd != "" {
@@ -331,24 +527,41 @@ func (l *Linter) LintFiles(filepaths []string, project *Project) ([]*Error, erro
 					w.path = r // Use relative path if possible
 				}
 			}
-			errs, err := l.check(w.path, src, p, proc, ac, rwc)
+			errs, err := l.check(w.path, src, p, proc, ac, rwc, names)
 			if err != nil {
-				return fmt.Errorf("fatal error while checking %s: %w", w.path, err)
+				w.err = fmt.Errorf("fatal error while checking %s: %w", w.path, err)
+				return
 			}
 			w.src = src
 			w.errs = errs
-			return nil
-		})
+		}()
 	}
 
+	wg.Wait()
 	proc.wait()
-	if err := eg.Wait(); err != nil {
-		return nil, err
+
+	// Errors across multiple files are reported after all files were checked
+	dups := names.duplicateErrors()
+	for i := range ws {
+		w := &ws[i]
+		d, ok := dups[w.path]
+		if !ok || w.err != nil {
+			continue
+		}
+		w.errs = append(w.errs, l.filterErrors(d.errs, d.cfg)...)
+		SortErrors(w.errs)
 	}
 
 	total := 0
+	fatals := []error{}
 	for i := range ws {
-		total += len(ws[i].errs)
+		w := &ws[i]
+		if w.err != nil {
+			l.log("Could not lint", w.path, "due to fatal error:", w.err)
+			fatals = append(fatals, w.err)
+			continue
+		}
+		total += len(w.errs)
 	}
 
 	all := make([]*Error, 0, total)
@@ -356,8 +569,10 @@ func (l *Linter) LintFiles(filepaths []string, project *Project) ([]*Error, erro
 		temp := make([]*ErrorTemplateFields, 0, total)
 		for i := range ws {
 			w := &ws[i]
-			for _, err := range w.errs {
-				temp = append(temp, err.GetTemplateFields(w.src))
+			for i, fp := range Fingerprints(w.errs) {
+				f := w.errs[i].GetTemplateFields(w.src)
+				f.Fingerprint = fp
+				temp = append(temp, f)
 			}
 			all = append(all, w.errs...)
 		}
@@ -372,9 +587,20 @@ func (l *Linter) LintFiles(filepaths []string, project *Project) ([]*Error, erro
 		}
 	}
 
-	l.log("Found", total, "errors in", n, "files")
+	l.log("Found", total, "errors in", n-len(fatals), "files")
 
-	return all, nil
+	switch len(fatals) {
+	case 0:
+		return all, nil
+	case 1:
+		return all, fatals[0]
+	default:
+		msgs := make([]string, 0, len(fatals))
+		for _, err := range fatals {
+			msgs = append(msgs, err.Error())
+		}
+		return all, fmt.Errorf("%d files could not be linted:\n%s", len(fatals), strings.Join(msgs, "\n"))
+	}
 }
 
 // LintFile lints one YAML workflow file and outputs the errors to given writer. The project
@@ -399,7 +625,7 @@ func (l *Linter) LintFile(path string, project *Project) ([]*Error, error) {
 	dbg := l.debugWriter()
 	localActions := NewLocalActionsCache(project, dbg)
 	localReusableWorkflows := NewLocalReusableWorkflowCache(project, l.cwd, dbg)
-	errs, err := l.check(path, src, project, proc, localActions, localReusableWorkflows)
+	errs, err := l.check(path, src, project, proc, localActions, localReusableWorkflows, nil)
 	proc.wait()
 	if err != nil {
 		return nil, err
@@ -413,6 +639,51 @@ func (l *Linter) LintFile(path string, project *Project) ([]*Error, error) {
 	return errs, err
 }
 
+// lintActionMetadataFile lints one action metadata file (action.yml) and outputs the errors to the
+// writer. The structure of the file and expressions in it are checked.
+func (l *Linter) lintActionMetadataFile(path string, project *Project) ([]*Error, error) {
+	if project == nil {
+		project = l.projects.At(path)
+	}
+
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read %q: %w", path, err)
+	}
+
+	if l.cwd != "" {
+		if r, err := filepath.Rel(l.cwd, path); err == nil {
+			path = r
+		}
+	}
+	l.log("Linting action metadata", path)
+
+	cfg := l.defaultConfig
+	if cfg == nil && project != nil {
+		cfg, err = project.Config()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := l.checkSeverityRuleNames(cfg); err != nil {
+		return nil, err
+	}
+
+	errs := lintActionMetadata(src)
+	for _, err := range errs {
+		err.Filepath = path
+	}
+	errs = l.filterErrors(errs, cfg)
+	SortErrors(errs)
+
+	if l.errFmt != nil {
+		l.errFmt.PrintErrors(l.out, errs, src)
+	} else {
+		l.printErrors(errs, src)
+	}
+	return errs, nil
+}
+
 // Lint lints YAML workflow file content given as byte sequence. The path parameter is used as file
 // path the content came from. Setting "<stdin>" to path parameter indicates the output came from
 // STDIN.
@@ -423,7 +694,7 @@ func (l *Linter) Lint(path string, content []byte, project *Project) ([]*Error,
 	dbg := l.debugWriter()
 	localActions := NewLocalActionsCache(project, dbg)
 	localReusableWorkflows := NewLocalReusableWorkflowCache(project, l.cwd, dbg)
-	errs, err := l.check(path, content, project, proc, localActions, localReusableWorkflows)
+	errs, err := l.check(path, content, project, proc, localActions, localReusableWorkflows, nil)
 	proc.wait()
 	if err != nil {
 		return nil, err
@@ -436,6 +707,76 @@ func (l *Linter) Lint(path string, content []byte, project *Project) ([]*Error,
 	return errs, nil
 }
 
+// Lint lints YAML workflow file content given as byte sequence with the given config. Unlike
+// Linter.Lint method, it does not look for any project or config file on file system. The path
+// parameter is used only as file path of the errors. The cfg parameter can be nil, which means no
+// config. shellcheck and pyflakes commands are run when they are found in $PATH unless they are
+// disabled by the config. Errors are not output anywhere but only returned.
+func Lint(src []byte, path string, cfg *Config) ([]*Error, error) {
+	l, err := NewLinter(io.Discard, &LinterOptions{
+		Shellcheck: "shellcheck",
+		Pyflakes:   "pyflakes",
+	})
+	if err != nil {
+		return nil, err
+	}
+	if cfg != nil {
+		// Copy the config not to modify the given instance which may be shared by multiple goroutines
+		c := *cfg
+		if err := c.compileIgnorePatterns(); err != nil {
+			return nil, fmt.Errorf("invalid config: %w", err)
+		}
+		l.defaultConfig = &c
+	}
+	return l.Lint(path, src, nil)
+}
+
+// LintWithCache lints YAML workflow file content as Lint method does, but it reuses the result of
+// the previous call for the same path when neither the content nor the config file of the project
+// ("actionlint.yaml") was changed since then. This is useful for tools which lint the same files
+// repeatedly such as watch mode of editor integrations. The second return value is true when the
+// errors came from the cache and false when the content was actually linted. Errors are output to
+// the writer in both cases.
+// Results are keyed by the path and SHA-256 hash of the content and the config file content. The
+// config file is read on every call so that its changes are reflected without creating a new Linter
+// instance. Note that a config file given by LinterOptions.ConfigFile is loaded only once when the
+// Linter instance is created, and changes of other files such as local actions and reusable
+// workflows used by the workflow are not detected.
+// This method is thread-safe and can be called from multiple goroutines concurrently. Cached errors
+// are shared by all callers so they must not be modified.
+func (l *Linter) LintWithCache(path string, content []byte, project *Project) ([]*Error, bool, error) {
+	h := sha256.New()
+	h.Write(content)
+	if l.defaultConfig == nil && project != nil {
+		cfg, sum, err := l.lintCache.projectConfig(project)
+		if err != nil {
+			return nil, false, err
+		}
+		h.Write(sum[:])
+		// Do not use the config cached in the Project instance since it is never reloaded
+		project = &Project{root: project.root, config: cfg}
+	}
+	var sum [sha256.Size]byte
+	copy(sum[:], h.Sum(nil))
+
+	if errs, ok := l.lintCache.get(path, sum); ok {
+		l.log("Skipped linting unchanged file", path)
+		if l.errFmt != nil {
+			l.errFmt.PrintErrors(l.out, errs, content)
+		} else {
+			l.printErrors(errs, content)
+		}
+		return errs, true, nil
+	}
+
+	errs, err := l.Lint(path, content, project)
+	if err != nil {
+		return nil, false, err
+	}
+	l.lintCache.set(path, sum, errs)
+	return errs, false, nil
+}
+
 func (l *Linter) check(
 	path string,
 	content []byte,
@@ -443,6 +784,7 @@ func (l *Linter) check(
 	proc *concurrentProcess,
 	localActions *LocalActionsCache,
 	localReusableWorkflows *LocalReusableWorkflowCache,
+	names *workflowNames, // nil when only one file is linted
 ) ([]*Error, error) {
 	// Note: This method is called to check multiple files in parallel.
 	// It must be thread safe assuming fields of Linter are not modified while running.
@@ -472,9 +814,16 @@ func (l *Linter) check(
 	} else {
 		l.debug("No config was found")
 	}
+	if err := l.checkSeverityRuleNames(cfg); err != nil {
+		return nil, err
+	}
 
 	w, all := Parse(content)
 
+	if names != nil && w != nil && w.Name != nil && cfg != nil && cfg.isCheckEnabled("duplicate-workflow-name") {
+		names.add(path, w.Name, project, cfg)
+	}
+
 	if l.logLevel >= LogLevelVerbose {
 		elapsed := time.Since(start)
 		l.log("Found", len(all), "parse errors in", elapsed.Milliseconds(), "ms for", path)
@@ -488,23 +837,39 @@ func (l *Linter) check(
 			labels = cfg.SelfHostedRunner.Labels
 		}
 
+		var repo string
+		if cfg != nil && cfg.isCheckEnabled("hardcoded-repository") {
+			repo = cfg.RunScript.Repository
+			if repo == "" && project != nil {
+				repo = project.Repository()
+			}
+		}
+
+		expr := NewRuleExpression(localActions, localReusableWorkflows)
+		expr.exprCache = l.exprCache // Share parsed expressions across files (thread-safe)
+
 		rules := []Rule{
 			NewRuleMatrix(),
+			NewRuleLimits(),
 			NewRuleCredentials(),
+			NewRuleContainerImage(),
 			NewRuleShellName(),
 			NewRuleRunnerLabel(labels),
 			NewRuleEvents(),
 			NewRuleJobNeeds(),
-			NewRuleAction(localActions),
+			NewRuleAction(localActions, content),
 			NewRuleEnvVar(),
 			NewRuleID(),
 			NewRuleGlob(),
 			NewRulePermissions(),
 			NewRuleWorkflowCall(path, localReusableWorkflows),
-			NewRuleExpression(localActions, localReusableWorkflows),
-			NewRuleDeprecatedCommands(),
+			expr,
+			NewRuleDeprecatedCommands(content),
+			NewRuleRunScript(repo),
 		}
-		if l.shellcheck != "" {
+		if cfg != nil && cfg.Shellcheck.Disable {
+			l.log("Rule \"shellcheck\" was disabled by config")
+		} else if l.shellcheck != "" {
 			r, err := NewRuleShellcheck(l.shellcheck, proc)
 			if err == nil {
 				rules = append(rules, r)
@@ -514,7 +879,9 @@ func (l *Linter) check(
 		} else {
 			l.log("Rule \"shellcheck\" was disabled since shellcheck command name was empty")
 		}
-		if l.pyflakes != "" {
+		if cfg != nil && cfg.Pyflakes.Disable {
+			l.log("Rule \"pyflakes\" was disabled by config")
+		} else if l.pyflakes != "" {
 			r, err := NewRulePyflakes(l.pyflakes, proc)
 			if err == nil {
 				rules = append(rules, r)
@@ -525,8 +892,14 @@ func (l *Linter) check(
 			l.log("Rule \"pyflakes\" was disabled since pyflakes command name was empty")
 		}
 
+		for _, f := range l.customRules {
+			rules = append(rules, f())
+		}
+		expr.exprVisitors = rules
+
 		v := NewVisitor()
 		for _, rule := range rules {
+			rule.SetConfig(cfg)
 			v.AddPass(rule)
 		}
 		if dbg != nil {
@@ -548,26 +921,18 @@ func (l *Linter) check(
 		}
 	}
 
-	if len(l.ignorePats) > 0 {
-		filtered := make([]*Error, 0, len(all))
-	Loop:
-		for _, err := range all {
-			for _, pat := range l.ignorePats {
-				if pat.MatchString(err.Message) {
-					continue Loop
-				}
-			}
-			filtered = append(filtered, err)
-		}
-		all = filtered
+	if d := parseDisableComments(content); len(d.ranges) > 0 {
+		all = d.filter(all)
+		all = append(all, d.unusedErrors()...)
 	}
 
-	sort.Sort(ByErrorPosition(all))
-
 	for _, err := range all {
 		err.Filepath = path // Populate filename in the error
 	}
 
+	all = l.filterErrors(all, cfg)
+	SortErrors(all)
+
 	if l.logLevel >= LogLevelVerbose {
 		elapsed := time.Since(start)
 		l.log("Found total", len(all), "errors in", elapsed.Milliseconds(), "ms for", path)
@@ -576,6 +941,93 @@ func (l *Linter) check(
 	return all, nil
 }
 
+// filterErrors overrides severities of the errors and removes errors ignored by -ignore patterns,
+// "ignore" configuration, or listed in the baseline. File paths of the errors are made relative to
+// the base directory of -relative-to before checking the baseline. The cfg parameter can be nil.
+func (l *Linter) filterErrors(all []*Error, cfg *Config) []*Error {
+	if l.relTo != "" {
+		for _, err := range all {
+			err.Filepath = l.relPath(err.Filepath)
+		}
+	}
+	if cfg != nil && len(cfg.Severity) > 0 {
+		l.overrideSeverities(all, cfg.Severities())
+	}
+	if len(l.severities) > 0 {
+		l.overrideSeverities(all, l.severities)
+	}
+
+	pats := l.ignorePats
+	if cfg != nil && len(cfg.ignorePats) > 0 {
+		pats = append(append([]*regexp.Regexp{}, cfg.ignorePats...), pats...)
+	}
+
+	if len(pats) == 0 && l.baseline == nil {
+		return all
+	}
+
+	var fps []string
+	if l.baseline != nil {
+		fps = Fingerprints(all)
+	}
+
+	filtered := make([]*Error, 0, len(all))
+	ignored := 0
+Loop:
+	for i, err := range all {
+		if fps != nil && l.baseline.contains(fps[i]) {
+			continue
+		}
+		for _, pat := range pats {
+			if pat.MatchString(err.Message) {
+				ignored++
+				continue Loop
+			}
+		}
+		filtered = append(filtered, err)
+	}
+
+	if ignored > 0 {
+		atomic.AddInt64(&l.ignored, int64(ignored))
+		l.log("Ignored", ignored, "errors matched to ignore patterns")
+	}
+	return filtered
+}
+
+// IgnoredErrors returns the number of errors which were matched to ignore patterns given by
+// -ignore option or "ignore" configuration and removed from the results so far.
+func (l *Linter) IgnoredErrors() int {
+	return int(atomic.LoadInt64(&l.ignored))
+}
+
+// relPath returns the file path relative to the base directory given by LinterOptions.RelativeTo.
+// Relative paths are resolved from the working directory. The path is returned as-is when no base
+// directory is given or the path cannot be made relative.
+func (l *Linter) relPath(path string) string {
+	if l.relTo == "" || path == "<stdin>" {
+		return path
+	}
+	abs := path
+	if !filepath.IsAbs(abs) {
+		if l.cwd == "" {
+			return path
+		}
+		abs = filepath.Join(l.cwd, abs)
+	}
+	if r, err := filepath.Rel(l.relTo, abs); err == nil {
+		return r
+	}
+	return path
+}
+
+func (l *Linter) overrideSeverities(errs []*Error, sevs map[string]Severity) {
+	for _, err := range errs {
+		if s, ok := sevs[err.Kind]; ok {
+			err.Severity = s
+		}
+	}
+}
+
 func (l *Linter) printErrors(errs []*Error, src []byte) {
 	if l.oneline {
 		src = nil
@@ -2,6 +2,7 @@ package actionlint
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
 	"io"
 	"os"
@@ -199,6 +200,54 @@ func TestLinterLintError(t *testing.T) {
 	}
 }
 
+func TestLinterErrorsTaggedWithRuleNames(t *testing.T) {
+	found := map[string]struct{}{}
+	for _, subdir := range []string{"examples", "err"} {
+		dir, infiles, err := testFindAllWorkflowsInDir(subdir)
+		if err != nil {
+			panic(err)
+		}
+		proj := &Project{root: dir}
+
+		l, err := NewLinter(io.Discard, &LinterOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		errs, err := l.LintFiles(infiles, proj)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, e := range errs {
+			if !isKnownRuleName(e.Kind, nil) {
+				t.Errorf("error is not tagged with known rule name: %s", e)
+			}
+			if strings.Contains(e.Message, "["+e.Kind+"]") {
+				t.Errorf("rule name is embedded in error message: %s", e)
+			}
+			found[e.Kind] = struct{}{}
+		}
+	}
+
+	// These rules need external commands, multiple files, or specific configurations to report errors
+	skipped := map[string]struct{}{
+		"action-metadata": {},
+		"limits":          {},
+		"pyflakes":        {},
+		"run-script":      {},
+		"shellcheck":      {},
+		"workflow-name":   {},
+	}
+	for _, r := range BuiltinRules() {
+		if _, ok := skipped[r.Name]; ok {
+			continue
+		}
+		if _, ok := found[r.Name]; !ok {
+			t.Errorf("no error tagged with rule %q was found in test data", r.Name)
+		}
+	}
+}
+
 func TestLinterLintProject(t *testing.T) {
 	root := filepath.Join("testdata", "projects")
 	entries, err := os.ReadDir(root)
@@ -236,6 +285,798 @@ func TestLinterLintProject(t *testing.T) {
 	}
 }
 
+func TestLinterLintFilesContinueOnFatalError(t *testing.T) {
+	ok := filepath.Join("testdata", "bench", "small.yaml")
+	bad := filepath.Join("testdata", "examples", "main.yaml")
+	missing := filepath.Join("testdata", "this-file-does-not-exist.yaml")
+
+	opts := LinterOptions{}
+	l, err := NewLinter(io.Discard, &opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.defaultConfig = &Config{}
+
+	errs, err := l.LintFiles([]string{bad, missing, ok}, &Project{root: "."})
+	if err == nil {
+		t.Fatal("fatal error for missing file was not returned")
+	}
+	if !strings.Contains(err.Error(), "this-file-does-not-exist.yaml") {
+		t.Fatalf("error message does not mention the missing file: %v", err)
+	}
+	if len(errs) == 0 {
+		t.Fatal("errors in other files were not returned")
+	}
+	for _, e := range errs {
+		if !strings.HasSuffix(e.Filepath, "main.yaml") {
+			t.Fatalf("unexpected error from file %q: %s", e.Filepath, e)
+		}
+	}
+}
+
+func TestLinterLintRepositoryFilesConfig(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		".git/HEAD":                      "ref: refs/heads/main\n",
+		".github/actionlint.yaml":        "files:\n  workflows: ['ci/**/*.yaml']\n  actions: ['**/action.yml', '**/action.yaml']\n",
+		".github/workflows/test.yaml":    "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo\n",
+		"ci/nested/workflows/build.yaml": "on: push\njobs:\n  build:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo ${{ unknown }}\n",
+		"action.yml":                     "name: root\ndescription: root action\nruns:\n  using: node20\n  main: index.js\n",
+		"actions/foo/action.yml":         "name: foo\ndescription: foo action\nruns:\n  using: composite\n",
+		"actions/nested/bar/action.yaml": "name: bar\ndescription: bar action\nruns:\n  using: node8\n  main: index.js\n",
+		"node_modules/dep/action.yml":    "name: dep\n",
+	}
+	for f, c := range files {
+		p := filepath.Join(root, filepath.FromSlash(f))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(c), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var log strings.Builder
+	l, err := NewLinter(io.Discard, &LinterOptions{WorkingDir: root, Verbose: true, LogWriter: &log})
+	if err != nil {
+		t.Fatal(err)
+	}
+	errs, err := l.LintRepository(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	have := make([]string, 0, len(errs))
+	for _, err := range errs {
+		have = append(have, fmt.Sprintf("%s:%d:%d:%s", filepath.ToSlash(err.Filepath), err.Line, err.Column, err.Kind))
+	}
+	want := []string{
+		"ci/nested/workflows/build.yaml:6:23:expression",
+		"actions/foo/action.yml:3:1:action-metadata",
+		"actions/nested/bar/action.yaml:4:10:action-metadata",
+	}
+	if !cmp.Equal(want, have) {
+		t.Fatal(cmp.Diff(want, have))
+	}
+
+	if msg := "Linted 2 workflow files and 3 action metadata files"; !strings.Contains(log.String(), msg) {
+		t.Fatalf("log does not contain %q: %q", msg, log.String())
+	}
+}
+
+func TestLinterDuplicateWorkflowNames(t *testing.T) {
+	dir := filepath.Join("testdata", "workflow_names")
+	build := filepath.Join(dir, "build.yaml")
+	test := filepath.Join(dir, "test.yaml")
+	release := filepath.Join(dir, "release.yaml")
+
+	want := []string{
+		fmt.Sprintf(`%s:1:7: workflow name "CI" is duplicated. the same name is used in %q`, build, test),
+		fmt.Sprintf(`%s:1:7: workflow name "CI" is duplicated. the same name is used in %q`, test, build),
+	}
+	errs := testOptionalCheck(t, "duplicate-workflow-name", SeverityWarning, want, func(cfg *Config) []*Error {
+		l, err := NewLinter(io.Discard, &LinterOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		l.defaultConfig = cfg
+		errs, err := l.LintFiles([]string{build, release, test}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return errs
+	})
+	for _, err := range errs {
+		if err.Kind != "workflow-name" {
+			t.Errorf("unexpected kind: %s", err)
+		}
+	}
+
+	// Only one file is linted
+	l, err := NewLinter(io.Discard, &LinterOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.defaultConfig = &Config{EnableChecks: []string{"duplicate-workflow-name"}}
+	errs, err = l.LintFiles([]string{build}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) > 0 {
+		t.Fatalf("errors were reported for single file: %v", errs)
+	}
+}
+
+func TestLinterOverrideSeverities(t *testing.T) {
+	f := filepath.Join("testdata", "err", "deprecated_workflow_commands.yaml")
+	proj := &Project{root: "."}
+
+	testCases := []struct {
+		what   string
+		config map[string]string
+		opts   map[string]Severity
+		want   Severity
+	}{
+		{
+			what: "default",
+			want: SeverityError,
+		},
+		{
+			what:   "config",
+			config: map[string]string{"deprecated-commands": "warning"},
+			want:   SeverityWarning,
+		},
+		{
+			what: "option",
+			opts: map[string]Severity{"deprecated-commands": SeverityInfo},
+			want: SeverityInfo,
+		},
+		{
+			what:   "option takes precedence over config",
+			config: map[string]string{"deprecated-commands": "warning"},
+			opts:   map[string]Severity{"deprecated-commands": SeverityInfo},
+			want:   SeverityInfo,
+		},
+		{
+			what:   "other rule",
+			config: map[string]string{"expression": "info"},
+			want:   SeverityError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			l, err := NewLinter(io.Discard, &LinterOptions{Severities: tc.opts})
+			if err != nil {
+				t.Fatal(err)
+			}
+			l.defaultConfig = &Config{Severity: tc.config}
+
+			errs, err := l.LintFile(f, proj)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(errs) == 0 {
+				t.Fatal("no error was found")
+			}
+			for _, err := range errs {
+				if err.Severity != tc.want {
+					t.Errorf("wanted severity %s but got %s: %s", tc.want, err.Severity, err)
+				}
+			}
+		})
+	}
+}
+
+func TestLinterIgnorePatterns(t *testing.T) {
+	src := `on: push
+jobs:
+  test:
+    runs-on: my-runner
+    steps:
+      - run: echo "::set-output name=foo::bar"
+      - run: echo ${{ unknown }}
+`
+
+	testCases := []struct {
+		what    string
+		opts    []string
+		config  []string
+		want    []string
+		ignored int
+	}{
+		{
+			what: "no pattern",
+			want: []string{"runner-label", "deprecated-commands", "expression"},
+		},
+		{
+			what:    "option",
+			opts:    []string{`"set-output" was deprecated`},
+			want:    []string{"runner-label", "expression"},
+			ignored: 1,
+		},
+		{
+			what:    "config",
+			config:  []string{`^label "my-runner" is unknown`, `^undefined variable`},
+			want:    []string{"deprecated-commands"},
+			ignored: 2,
+		},
+		{
+			what:    "both option and config",
+			opts:    []string{`set-output`},
+			config:  []string{`my-runner`},
+			want:    []string{"expression"},
+			ignored: 2,
+		},
+		{
+			what:   "pattern matching to nothing",
+			opts:   []string{`this matches nothing`},
+			config: []string{`this matches nothing`},
+			want:   []string{"runner-label", "deprecated-commands", "expression"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			var log bytes.Buffer
+			l, err := NewLinter(io.Discard, &LinterOptions{IgnorePatterns: tc.opts, Verbose: true, LogWriter: &log})
+			if err != nil {
+				t.Fatal(err)
+			}
+			cfg := &Config{Ignore: tc.config}
+			if err := cfg.compileIgnorePatterns(); err != nil {
+				t.Fatal(err)
+			}
+			l.defaultConfig = cfg
+
+			errs, err := l.Lint("test.yaml", []byte(src), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			have := []string{}
+			for _, err := range errs {
+				have = append(have, err.Kind)
+			}
+			if !cmp.Equal(tc.want, have) {
+				t.Fatal(cmp.Diff(tc.want, have))
+			}
+
+			msg := fmt.Sprintf("Ignored %d errors matched to ignore patterns", tc.ignored)
+			if logged := strings.Contains(log.String(), msg); logged != (tc.ignored > 0) {
+				t.Fatalf("log of ignored errors is unexpected (want %q): %q", msg, log.String())
+			}
+			if n := l.IgnoredErrors(); n != tc.ignored {
+				t.Fatalf("wanted %d ignored errors but got %d", tc.ignored, n)
+			}
+		})
+	}
+}
+
+func TestLinterInvalidIgnorePattern(t *testing.T) {
+	_, err := NewLinter(io.Discard, &LinterOptions{IgnorePatterns: []string{"foo(bar"}})
+	if err == nil {
+		t.Fatal("error did not occur")
+	}
+	want := `invalid regular expression for ignore pattern "foo(bar"`
+	if msg := err.Error(); !strings.Contains(msg, want) {
+		t.Fatalf("error message %q does not contain %q", msg, want)
+	}
+}
+
+func TestLintWithInvalidIgnorePatternInConfig(t *testing.T) {
+	cfg := &Config{Ignore: []string{"foo(bar"}}
+	_, err := Lint([]byte("on: push\n"), "test.yaml", cfg)
+	if err == nil {
+		t.Fatal("error did not occur")
+	}
+	want := `invalid regular expression "foo(bar" at "ignore"`
+	if msg := err.Error(); !strings.Contains(msg, want) {
+		t.Fatalf("error message %q does not contain %q", msg, want)
+	}
+	if cfg.ignorePats != nil {
+		t.Fatal("given config was modified")
+	}
+}
+
+func TestLintWithConfig(t *testing.T) {
+	ok := `on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo "$GITHUB_SHA"
+`
+	bad := `on: push
+jobs:
+  test:
+    runs-on: my-runner
+    steps:
+      - run: echo "::set-output name=foo::bar"
+      - run: echo ${{ unknown }} $FOO
+`
+
+	testCases := []struct {
+		what  string
+		src   string
+		cfg   *Config
+		rules []string
+		sev   Severity // Severity of "deprecated-commands" error
+	}{
+		{
+			what: "clean file",
+			src:  ok,
+		},
+		{
+			what: "clean file with config",
+			src:  ok,
+			cfg:  &Config{},
+		},
+		{
+			what:  "multiple rule hits",
+			src:   bad,
+			rules: []string{"runner-label", "deprecated-commands", "expression"},
+			sev:   SeverityError,
+		},
+		{
+			what: "self-hosted labels and severities in config",
+			src:  bad,
+			cfg: func() *Config {
+				c := &Config{Severity: map[string]string{"deprecated-commands": "warning"}}
+				c.SelfHostedRunner.Labels = []string{"my-*"}
+				return c
+			}(),
+			rules: []string{"deprecated-commands", "expression"},
+			sev:   SeverityWarning,
+		},
+		{
+			what:  "ignore patterns in config",
+			src:   bad,
+			cfg:   &Config{Ignore: []string{`^label "my-runner" is unknown`}},
+			rules: []string{"deprecated-commands", "expression"},
+			sev:   SeverityError,
+		},
+		{
+			what: "external tools disabled by config",
+			src:  bad,
+			cfg: func() *Config {
+				c := &Config{}
+				c.Shellcheck.Disable = true
+				c.Pyflakes.Disable = true
+				return c
+			}(),
+			rules: []string{"runner-label", "deprecated-commands", "expression"},
+			sev:   SeverityError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			errs, err := Lint([]byte(tc.src), "test.yaml", tc.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rules := []string{}
+			for _, e := range errs {
+				if e.Filepath != "test.yaml" {
+					t.Errorf("file path of error is unexpected: %s", e)
+				}
+				if e.Kind == "deprecated-commands" && e.Severity != tc.sev {
+					t.Errorf("wanted severity %s but got %s: %s", tc.sev, e.Severity, e)
+				}
+				if e.Kind == "shellcheck" || e.Kind == "pyflakes" {
+					if tc.cfg != nil && tc.cfg.Shellcheck.Disable {
+						t.Errorf("%q rule should be disabled by config: %s", e.Kind, e)
+					}
+					continue // The external commands may not be installed
+				}
+				if len(rules) == 0 || rules[len(rules)-1] != e.Kind {
+					rules = append(rules, e.Kind)
+				}
+			}
+			if tc.rules == nil {
+				tc.rules = []string{}
+			}
+			if !cmp.Equal(tc.rules, rules) {
+				t.Fatal(cmp.Diff(tc.rules, rules))
+			}
+		})
+	}
+}
+
+func TestLinterRelativeTo(t *testing.T) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := filepath.Join("testdata", "err", "deprecated_workflow_commands.yaml")
+	proj := &Project{root: "."}
+
+	testCases := []struct {
+		what  string
+		base  string
+		input string
+		want  string
+	}{
+		{
+			what:  "no base",
+			input: f,
+			want:  f,
+		},
+		{
+			what:  "relative base",
+			base:  "testdata",
+			input: f,
+			want:  filepath.Join("err", "deprecated_workflow_commands.yaml"),
+		},
+		{
+			what:  "absolute base",
+			base:  filepath.Join(cwd, "testdata", "err"),
+			input: f,
+			want:  "deprecated_workflow_commands.yaml",
+		},
+		{
+			what:  "absolute input path",
+			base:  "testdata",
+			input: filepath.Join(cwd, f),
+			want:  filepath.Join("err", "deprecated_workflow_commands.yaml"),
+		},
+		{
+			what:  "base in subdirectory",
+			base:  filepath.Join("testdata", "ok"),
+			input: f,
+			want:  filepath.Join("..", "err", "deprecated_workflow_commands.yaml"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			var out bytes.Buffer
+			opts := &LinterOptions{WorkingDir: cwd, RelativeTo: tc.base, Oneline: true}
+			l, err := NewLinter(&out, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			l.defaultConfig = &Config{}
+
+			errs, err := l.LintFile(tc.input, proj)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(errs) == 0 {
+				t.Fatal("no error was found")
+			}
+			for _, err := range errs {
+				if err.Filepath != tc.want {
+					t.Errorf("wanted file path %q but got %q", tc.want, err.Filepath)
+				}
+			}
+			if !strings.HasPrefix(out.String(), tc.want+":") {
+				t.Errorf("output does not start with file path %q: %q", tc.want, out.String())
+			}
+		})
+	}
+
+	l, err := NewLinter(io.Discard, &LinterOptions{WorkingDir: cwd, RelativeTo: "testdata"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.defaultConfig = &Config{}
+	errs, err := l.Lint("<stdin>", []byte("on: push\njobs:\n  test:\n    steps:\n      - run: echo\n"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, err := range errs {
+		if err.Filepath != "<stdin>" {
+			t.Errorf("file path of stdin should not be changed but got %q", err.Filepath)
+		}
+	}
+}
+
+func TestLinterOverrideSeveritiesUnknownRule(t *testing.T) {
+	testCases := []struct {
+		what string
+		opts map[string]Severity
+		cfg  map[string]string
+		want string
+	}{
+		{
+			what: "option",
+			opts: map[string]Severity{"unknown-rule": SeverityInfo},
+			want: `unknown rule name "unknown-rule" for overriding severity`,
+		},
+		{
+			what: "config",
+			cfg:  map[string]string{"deprecated-command": "error"},
+			want: `invalid config: unknown rule name "deprecated-command" at "severity"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			l, err := NewLinter(io.Discard, &LinterOptions{Severities: tc.opts})
+			if err != nil {
+				t.Fatal(err)
+			}
+			l.defaultConfig = &Config{Severity: tc.cfg}
+			_, err = l.Lint("test.yaml", []byte("on: push\n"), nil)
+			if err == nil {
+				t.Fatal("error did not occur")
+			}
+			if msg := err.Error(); !strings.Contains(msg, tc.want) {
+				t.Fatalf("error message %q does not contain %q", msg, tc.want)
+			}
+		})
+	}
+}
+
+func TestLinterOverrideSeveritiesOfCustomRule(t *testing.T) {
+	src := "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo\n"
+
+	testCases := []struct {
+		what string
+		opts map[string]Severity
+		cfg  map[string]string
+		want Severity
+	}{
+		{
+			what: "no override",
+			want: SeverityError,
+		},
+		{
+			what: "option",
+			opts: map[string]Severity{"timeout-minutes": SeverityInfo},
+			want: SeverityInfo,
+		},
+		{
+			what: "config",
+			cfg:  map[string]string{"timeout-minutes": "warning"},
+			want: SeverityWarning,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			l, err := NewLinter(io.Discard, &LinterOptions{Severities: tc.opts})
+			if err != nil {
+				t.Fatal(err)
+			}
+			l.RegisterRule(func() Rule {
+				return &customRuleTimeoutMinutes{NewRuleBase("timeout-minutes")}
+			})
+			l.defaultConfig = &Config{Severity: tc.cfg}
+
+			errs, err := l.Lint("test.yaml", []byte(src), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(errs) != 1 {
+				t.Fatalf("wanted 1 error but got %d errors: %v", len(errs), errs)
+			}
+			if s := errs[0].Severity; s != tc.want {
+				t.Fatalf("wanted severity %s but got %s", tc.want, s)
+			}
+		})
+	}
+}
+
+// customRuleTimeoutMinutes is an example of custom rule defined outside the built-in rules. It
+// reports jobs without "timeout-minutes:".
+type customRuleTimeoutMinutes struct {
+	RuleBase
+}
+
+func (r *customRuleTimeoutMinutes) VisitJobPre(n *Job) error {
+	if n.TimeoutMinutes == nil {
+		r.Errorf(n.Pos, "\"timeout-minutes\" is not set at job %q", n.ID.Value)
+	}
+	return nil
+}
+
+func (r *customRuleTimeoutMinutes) Description() string {
+	return "Checks \"timeout-minutes\" is set at all jobs"
+}
+
+type customRuleNoDescription struct {
+	RuleBase
+}
+
+// customRuleContinueOnError is an example of custom rule which reports informational messages. It
+// reports steps with "continue-on-error: true".
+type customRuleContinueOnError struct {
+	RuleBase
+}
+
+func (r *customRuleContinueOnError) VisitStep(n *Step) error {
+	if n.ContinueOnError != nil && n.ContinueOnError.Value {
+		r.Infof(n.Pos, "step continues on error")
+	}
+	return nil
+}
+
+func (r *customRuleContinueOnError) DefaultSeverity() Severity {
+	return SeverityInfo
+}
+
+// customRuleDeprecatedFunc is an example of custom rule which checks expressions. It reports calls of
+// the given function.
+type customRuleDeprecatedFunc struct {
+	RuleBase
+	name string
+}
+
+func (r *customRuleDeprecatedFunc) VisitExpr(n ExprNode, pos *Pos) {
+	VisitExprNode(n, func(n, _ ExprNode, entering bool) {
+		if f, ok := n.(*FuncCallNode); ok && entering && strings.EqualFold(f.Callee, r.name) {
+			t := f.Token()
+			r.Errorf(&Pos{Line: pos.Line + t.Line - 1, Col: pos.Col + t.Column - 1}, "function %q is not allowed", f.Callee)
+		}
+	})
+}
+
+func TestLinterRulesWithCustomRules(t *testing.T) {
+	l, err := NewLinter(io.Discard, &LinterOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.RegisterRule(func() Rule {
+		return &customRuleTimeoutMinutes{NewRuleBase("timeout-minutes")}
+	})
+	l.RegisterRule(func() Rule {
+		return &customRuleNoDescription{NewRuleBase("no-description")}
+	})
+	l.RegisterRule(func() Rule {
+		return &customRuleContinueOnError{NewRuleBase("continue-on-error")}
+	})
+
+	rules := l.Rules()
+	builtin := BuiltinRules()
+	if len(rules) != len(builtin)+3 {
+		t.Fatalf("wanted %d rules but got %d rules: %v", len(builtin)+3, len(rules), rules)
+	}
+	if !cmp.Equal(builtin, rules[:len(builtin)]) {
+		t.Fatal(cmp.Diff(builtin, rules[:len(builtin)]))
+	}
+	want := []*RuleInfo{
+		{"timeout-minutes", "Checks \"timeout-minutes\" is set at all jobs", "error"},
+		{"no-description", "", "error"},
+		{"continue-on-error", "", "info"},
+	}
+	if have := rules[len(builtin):]; !cmp.Equal(want, have) {
+		t.Fatal(cmp.Diff(want, have))
+	}
+}
+
+func TestLinterRegisterCustomRule(t *testing.T) {
+	l, err := NewLinter(io.Discard, &LinterOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.RegisterRule(func() Rule {
+		return &customRuleTimeoutMinutes{NewRuleBase("timeout-minutes")}
+	})
+
+	src := `on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    timeout-minutes: 10
+    steps:
+      - run: echo test
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo build
+`
+	errs, err := l.Lint("test.yaml", []byte(src), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 1 {
+		t.Fatalf("wanted 1 error but got %d errors: %v", len(errs), errs)
+	}
+	want := `test.yaml:8:3: "timeout-minutes" is not set at job "build" [timeout-minutes]`
+	if have := errs[0].Error(); have != want {
+		t.Fatalf("wanted %q but got %q", want, have)
+	}
+
+	// Instances are created per file so errors in other files are not mixed
+	fs := []string{
+		filepath.Join("testdata", "workflow_names", "build.yaml"),
+		filepath.Join("testdata", "workflow_names", "test.yaml"),
+	}
+	errs, err = l.LintFiles(fs, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, err := range errs {
+		if err.Kind != "timeout-minutes" {
+			t.Errorf("unexpected error: %s", err)
+		}
+	}
+	if len(errs) != 2 {
+		t.Fatalf("wanted 2 errors but got %d errors: %v", len(errs), errs)
+	}
+}
+
+func TestLinterRegisterCustomRuleVisitingExpressions(t *testing.T) {
+	l, err := NewLinter(io.Discard, &LinterOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.RegisterRule(func() Rule {
+		return &customRuleDeprecatedFunc{NewRuleBase("deprecated-func"), "hashFiles"}
+	})
+
+	src := `on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    if: github.ref == 'refs/heads/main'
+    steps:
+      - uses: actions/cache@v4
+        with:
+          path: ~/.npm
+          key: npm-${{ hashFiles('**/package-lock.json') }}
+      - run: echo "${{ format('{0}', hashFiles('go.sum')) }}"
+`
+	errs, err := l.Lint("test.yaml", []byte(src), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		`test.yaml:10:24: function "hashFiles" is not allowed [deprecated-func]`,
+		`test.yaml:11:38: function "hashFiles" is not allowed [deprecated-func]`,
+	}
+	have := []string{}
+	for _, err := range errs {
+		have = append(have, err.Error())
+	}
+	if !cmp.Equal(want, have) {
+		t.Fatal(cmp.Diff(want, have))
+	}
+}
+
+func TestLinterRegisterCustomRuleReportingInfo(t *testing.T) {
+	l, err := NewLinter(io.Discard, &LinterOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.RegisterRule(func() Rule {
+		return &customRuleContinueOnError{NewRuleBase("continue-on-error")}
+	})
+
+	src := `on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo test
+        continue-on-error: true
+`
+	errs, err := l.Lint("test.yaml", []byte(src), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 1 {
+		t.Fatalf("wanted 1 error but got %d errors: %v", len(errs), errs)
+	}
+	if errs[0].Kind != "continue-on-error" || errs[0].Severity != SeverityInfo {
+		t.Fatalf("unexpected error: %s (severity: %s)", errs[0], errs[0].Severity)
+	}
+}
+
+func TestLinterInvalidFormatTemplate(t *testing.T) {
+	opts := &LinterOptions{Format: "{{range $err := .}}{{$err.Message}}"}
+	_, err := NewLinter(io.Discard, opts)
+	if err == nil {
+		t.Fatal("error did not occur")
+	}
+	if msg := err.Error(); !strings.Contains(msg, "could not be parsed") {
+		t.Fatalf("unexpected error message: %q", msg)
+	}
+}
+
 func TestLinterFormatErrorMessageOK(t *testing.T) {
 	tests := []struct {
 		file   string
@@ -541,3 +1382,49 @@ func BenchmarkLintRepository(b *testing.B) {
 		}
 	}
 }
+
+func BenchmarkLintFilesSequentialVsConcurrent(b *testing.B) {
+	small := filepath.Join("testdata", "bench", "small.yaml")
+	files := make([]string, 0, 50)
+	for i := 0; i < cap(files); i++ {
+		files = append(files, small)
+	}
+	proj := &Project{root: "."}
+
+	newLinter := func(b *testing.B) *Linter {
+		l, err := NewLinter(io.Discard, &LinterOptions{})
+		if err != nil {
+			b.Fatal(err)
+		}
+		l.defaultConfig = &Config{}
+		return l
+	}
+
+	b.Run(fmt.Sprintf("sequential-%d", len(files)), func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			l := newLinter(b)
+			for _, f := range files {
+				errs, err := l.LintFile(f, proj)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if len(errs) > 0 {
+					b.Fatal("some error occurred:", errs)
+				}
+			}
+		}
+	})
+
+	b.Run(fmt.Sprintf("concurrent-%d", len(files)), func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			l := newLinter(b)
+			errs, err := l.LintFiles(files, proj)
+			if err != nil {
+				b.Fatal(err)
+			}
+			if len(errs) > 0 {
+				b.Fatal("some error occurred:", errs)
+			}
+		}
+	})
+}
@@ -1,7 +1,9 @@
 package actionlint
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"os"
 	"regexp"
 	"strconv"
@@ -54,11 +56,11 @@ type parser struct {
 }
 
 func (p *parser) error(n *yaml.Node, m string) {
-	p.errors = append(p.errors, &Error{m, "", n.Line, n.Column, "syntax-check"})
+	p.errors = append(p.errors, &Error{m, "", n.Line, n.Column, "syntax-check", SeverityError, nil})
 }
 
 func (p *parser) errorAt(pos *Pos, m string) {
-	p.errors = append(p.errors, &Error{m, "", pos.Line, pos.Col, "syntax-check"})
+	p.errors = append(p.errors, &Error{m, "", pos.Line, pos.Col, "syntax-check", SeverityError, nil})
 }
 
 func (p *parser) errorfAt(pos *Pos, format string, args ...interface{}) {
@@ -149,6 +151,15 @@ func (p *parser) parseString(n *yaml.Node, allowEmpty bool) *String {
 	return newString(n)
 }
 
+// https://docs.github.com/en/actions/using-workflows/workflow-syntax-for-github-actions#custom-shell
+func (p *parser) parseShell(n *yaml.Node) *String {
+	if n.Kind == yaml.MappingNode || n.Kind == yaml.SequenceNode {
+		p.errorf(n, "expected shell name or custom shell command like \"perl {0}\" as string but found %s node. custom shell must be a string command containing \"{0}\" placeholder for the script file", nodeKindName(n.Kind))
+		return nil
+	}
+	return p.parseString(n, false)
+}
+
 func (p *parser) parseStringSequence(sec string, n *yaml.Node, allowEmpty bool, allowElemEmpty bool) []*String {
 	if ok := p.checkSequence(sec, n, allowEmpty); !ok {
 		return nil
@@ -359,8 +370,10 @@ func (p *parser) parseWorkflowDispatchEvent(pos *Pos, n *yaml.Node) *WorkflowDis
 						ty = WorkflowDispatchEventInputTypeChoice
 					case "environment":
 						ty = WorkflowDispatchEventInputTypeEnvironment
+					case "number":
+						ty = WorkflowDispatchEventInputTypeNumber
 					default:
-						p.errorf(attr.val, "input type of workflow_dispatch event must be one of \"string\", \"boolean\", \"choice\", \"environment\" but got %q", attr.val.Value)
+						p.errorf(attr.val, "input type of workflow_dispatch event must be one of \"string\", \"boolean\", \"choice\", \"environment\", \"number\" but got %q", attr.val.Value)
 					}
 				case "options":
 					opts = p.parseStringSequence("options", attr.val, false, false)
@@ -689,7 +702,7 @@ func (p *parser) parseDefaults(pos *Pos, n *yaml.Node) *Defaults {
 		for _, attr := range p.parseSectionMapping("run", kv.val, false) {
 			switch attr.id {
 			case "shell":
-				ret.Run.Shell = p.parseString(attr.val, false)
+				ret.Run.Shell = p.parseShell(attr.val)
 			case "working-directory":
 				ret.Run.WorkingDirectory = p.parseString(attr.val, false)
 			default:
@@ -957,6 +970,7 @@ func (p *parser) parseContainer(sec string, pos *Pos, n *yaml.Node) *Container {
 func (p *parser) parseStep(n *yaml.Node) *Step {
 	ret := &Step{Pos: posAt(n)}
 	var workDir *String
+	var execKey *String // The first key which determined kind of the step (running action or shell command)
 
 	for _, kv := range p.parseMapping("element of \"steps\" section", n, false) {
 		switch kv.id {
@@ -979,9 +993,12 @@ func (p *parser) parseStep(n *yaml.Node) *Step {
 			} else if e, ok := ret.Exec.(*ExecAction); ok {
 				exec = e
 			} else {
-				p.errorfAt(kv.key.Pos, "this step is for running shell command since it contains at least one of \"run\", \"shell\" keys, but also contains %q key which is used for running action", kv.key.Value)
+				p.errorfAt(kv.key.Pos, "this step is for running shell command since it contains at least one of \"run\", \"shell\" keys, but also contains %q key which is used for running action. %q key is defined at %s", kv.key.Value, execKey.Value, execKey.Pos.String())
 				continue
 			}
+			if execKey == nil {
+				execKey = kv.key
+			}
 			if kv.id == "uses" {
 				exec.Uses = p.parseString(kv.val, false)
 			} else {
@@ -1009,15 +1026,18 @@ func (p *parser) parseStep(n *yaml.Node) *Step {
 			} else if e, ok := ret.Exec.(*ExecRun); ok {
 				exec = e
 			} else {
-				p.errorfAt(kv.key.Pos, "this step is for running action since it contains at least one of \"uses\", \"with\" keys, but also contains %q key which is used for running shell command", kv.key.Value)
+				p.errorfAt(kv.key.Pos, "this step is for running action since it contains at least one of \"uses\", \"with\" keys, but also contains %q key which is used for running shell command. %q key is defined at %s", kv.key.Value, execKey.Value, execKey.Pos.String())
 				continue
 			}
+			if execKey == nil {
+				execKey = kv.key
+			}
 			switch kv.id {
 			case "run":
 				exec.Run = p.parseString(kv.val, false)
 				exec.RunPos = kv.key.Pos
 			case "shell":
-				exec.Shell = p.parseString(kv.val, false)
+				exec.Shell = p.parseShell(kv.val)
 			}
 			exec.WorkingDirectory = workDir
 			ret.Exec = exec
@@ -1098,7 +1118,7 @@ func (p *parser) parseJob(id *String, n *yaml.Node) *Job {
 
 	// https://docs.github.com/en/actions/using-workflows/reusing-workflows#supported-keywords-for-jobs-that-call-a-reusable-workflow
 	var stepsOnlyKey *String
-	var callOnlyKey *String
+	var callOnlyKeys []*String
 
 	for _, kv := range p.parseMapping(fmt.Sprintf("%q job", id.Value), n, false) {
 		k, v := kv.key, kv.val
@@ -1164,7 +1184,7 @@ func (p *parser) parseJob(id *String, n *yaml.Node) *Job {
 			}
 		case "uses":
 			call.Uses = p.parseString(v, false)
-			callOnlyKey = k
+			callOnlyKeys = append(callOnlyKeys, k)
 		case "with":
 			with := p.parseSectionMapping("with", v, false)
 			call.Inputs = make(map[string]*WorkflowCallInput, len(with))
@@ -1174,7 +1194,7 @@ func (p *parser) parseJob(id *String, n *yaml.Node) *Job {
 					Value: p.parseString(i.val, true),
 				}
 			}
-			callOnlyKey = k
+			callOnlyKeys = append(callOnlyKeys, k)
 		case "secrets":
 			if kv.val.Kind == yaml.ScalarNode {
 				// `secrets: inherit` special case
@@ -1194,7 +1214,7 @@ func (p *parser) parseJob(id *String, n *yaml.Node) *Job {
 					}
 				}
 			}
-			callOnlyKey = k
+			callOnlyKeys = append(callOnlyKeys, k)
 		default:
 			p.unexpectedKey(kv.key, "job", []string{
 				"name",
@@ -1221,7 +1241,13 @@ func (p *parser) parseJob(id *String, n *yaml.Node) *Job {
 	}
 
 	if call.Uses != nil {
-		if stepsOnlyKey != nil {
+		if stepsOnlyKey != nil && stepsOnlyKey.Value == "timeout-minutes" {
+			p.errorfAt(
+				stepsOnlyKey.Pos,
+				"\"timeout-minutes\" is not available when a reusable workflow is called with \"uses\" in job %q. the timeout is controlled by jobs in the called workflow",
+				id.Value,
+			)
+		} else if stepsOnlyKey != nil {
 			p.errorfAt(
 				stepsOnlyKey.Pos,
 				"when a reusable workflow is called with \"uses\", %q is not available. only following keys are allowed: \"name\", \"uses\", \"with\", \"secrets\", \"needs\", \"if\", and \"permissions\" in job %q",
@@ -1239,11 +1265,11 @@ func (p *parser) parseJob(id *String, n *yaml.Node) *Job {
 		if ret.RunsOn == nil {
 			p.errorfAt(id.Pos, "\"runs-on\" section is missing in job %q", id.Value)
 		}
-		if callOnlyKey != nil {
+		for _, k := range callOnlyKeys {
 			p.errorfAt(
-				callOnlyKey.Pos,
+				k.Pos,
 				"%q is only available for a reusable workflow call with \"uses\" but \"uses\" is not found in job %q",
-				callOnlyKey.Value,
+				k.Value,
 				id.Value,
 			)
 		}
@@ -1328,6 +1354,23 @@ func (p *parser) parse(n *yaml.Node) *Workflow {
 // 	}
 // }
 
+// checkMultipleDocuments reports the second YAML document separated with "---" in the source. YAML
+// allows multiple documents in one file, but GitHub Actions reads only the first document of the
+// workflow file and the following documents are silently ignored.
+func (p *parser) checkMultipleDocuments(dec *yaml.Decoder) {
+	var n yaml.Node
+	if err := dec.Decode(&n); err != nil {
+		return // io.EOF means no more document. Broken documents after the first one are not checked
+	}
+	if len(n.Content) == 1 {
+		if c := n.Content[0]; c.Kind == yaml.ScalarNode && c.Tag == "!!null" && c.Value == "" {
+			return // Empty document after the trailing "---" does not matter
+		}
+	}
+	// Position of the document node is the position of "---"
+	p.error(&n, "multiple YAML documents in one workflow file are not supported. GitHub Actions reads only the first document and ignores documents after this \"---\" separator")
+}
+
 func handleYAMLError(err error) []*Error {
 	re := regexp.MustCompile(`\bline (\d+):`)
 
@@ -1337,7 +1380,7 @@ func handleYAMLError(err error) []*Error {
 			l, _ = strconv.Atoi(ss[1])
 		}
 		msg = fmt.Sprintf("could not parse as YAML: %s", msg)
-		return &Error{msg, "", l, 0, "yaml-syntax"}
+		return &Error{msg, "", l, 0, "yaml-syntax", SeverityError, nil}
 	}
 
 	if te, ok := err.(*yaml.TypeError); ok {
@@ -1357,7 +1400,8 @@ func handleYAMLError(err error) []*Error {
 func Parse(b []byte) (*Workflow, []*Error) {
 	var n yaml.Node
 
-	if err := yaml.Unmarshal(b, &n); err != nil {
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	if err := dec.Decode(&n); err != nil && err != io.EOF {
 		return nil, handleYAMLError(err)
 	}
 
@@ -1366,6 +1410,7 @@ func Parse(b []byte) (*Workflow, []*Error) {
 
 	p := &parser{}
 	w := p.parse(&n)
+	p.checkMultipleDocuments(dec)
 
 	return w, p.errors
 }
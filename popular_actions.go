@@ -475,10 +475,13 @@ var PopularActions = map[string]*ActionMetadata{
 	"actions/cache@v3": {
 		Name: "Cache",
 		Inputs: ActionMetadataInputs{
-			"key":               {"key", true},
-			"path":              {"path", true},
-			"restore-keys":      {"restore-keys", false},
-			"upload-chunk-size": {"upload-chunk-size", false},
+			"enablecrossosarchive": {"enableCrossOsArchive", false},
+			"fail-on-cache-miss":   {"fail-on-cache-miss", false},
+			"key":                  {"key", true},
+			"lookup-only":          {"lookup-only", false},
+			"path":                 {"path", true},
+			"restore-keys":         {"restore-keys", false},
+			"upload-chunk-size":    {"upload-chunk-size", false},
 		},
 		Outputs: ActionMetadataOutputs{
 			"cache-hit": {"cache-hit"},
@@ -518,20 +521,22 @@ var PopularActions = map[string]*ActionMetadata{
 	"actions/checkout@v3": {
 		Name: "Checkout",
 		Inputs: ActionMetadataInputs{
-			"clean":               {"clean", false},
-			"fetch-depth":         {"fetch-depth", false},
-			"github-server-url":   {"github-server-url", false},
-			"lfs":                 {"lfs", false},
-			"path":                {"path", false},
-			"persist-credentials": {"persist-credentials", false},
-			"ref":                 {"ref", false},
-			"repository":          {"repository", false},
-			"set-safe-directory":  {"set-safe-directory", false},
-			"ssh-key":             {"ssh-key", false},
-			"ssh-known-hosts":     {"ssh-known-hosts", false},
-			"ssh-strict":          {"ssh-strict", false},
-			"submodules":          {"submodules", false},
-			"token":               {"token", false},
+			"clean":                     {"clean", false},
+			"fetch-depth":               {"fetch-depth", false},
+			"github-server-url":         {"github-server-url", false},
+			"lfs":                       {"lfs", false},
+			"path":                      {"path", false},
+			"persist-credentials":       {"persist-credentials", false},
+			"ref":                       {"ref", false},
+			"repository":                {"repository", false},
+			"set-safe-directory":        {"set-safe-directory", false},
+			"sparse-checkout":           {"sparse-checkout", false},
+			"sparse-checkout-cone-mode": {"sparse-checkout-cone-mode", false},
+			"ssh-key":                   {"ssh-key", false},
+			"ssh-known-hosts":           {"ssh-known-hosts", false},
+			"ssh-strict":                {"ssh-strict", false},
+			"submodules":                {"submodules", false},
+			"token":                     {"token", false},
 		},
 	},
 	"actions/configure-pages@v1": {
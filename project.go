@@ -1,8 +1,14 @@
 package actionlint
 
 import (
+	"bufio"
+	"bytes"
+	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
 )
 
@@ -51,6 +57,66 @@ func (p *Project) WorkflowsDir() string {
 	return filepath.Join(p.root, ".github", "workflows")
 }
 
+// Repository returns the GitHub repository name in "owner/name" format detected from URL of
+// "origin" remote in the Git configuration. It returns an empty string when the repository cannot
+// be detected.
+func (p *Project) Repository() string {
+	dir := filepath.Join(p.root, ".git")
+	// .git is a file which points the actual Git directory in worktrees and submodules
+	if b, err := os.ReadFile(dir); err == nil {
+		s := strings.TrimSpace(string(b))
+		if !strings.HasPrefix(s, "gitdir:") {
+			return ""
+		}
+		dir = strings.TrimSpace(strings.TrimPrefix(s, "gitdir:"))
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(p.root, dir)
+		}
+		// Worktrees share the configuration with the main repository
+		if b, err := os.ReadFile(filepath.Join(dir, "commondir")); err == nil {
+			c := strings.TrimSpace(string(b))
+			if !filepath.IsAbs(c) {
+				c = filepath.Join(dir, c)
+			}
+			dir = c
+		}
+	}
+
+	b, err := os.ReadFile(filepath.Join(dir, "config"))
+	if err != nil {
+		return ""
+	}
+	return originRepositoryInGitConfig(b)
+}
+
+var reGitHubRemoteURL = regexp.MustCompile(`github\.com[:/]([\w.-]+/[\w.-]+?)(?:\.git)?/?$`)
+
+// originRepositoryInGitConfig finds URL of "origin" remote in the content of Git configuration
+// file and returns the GitHub repository name in "owner/name" format.
+func originRepositoryInGitConfig(b []byte) string {
+	section := ""
+	s := bufio.NewScanner(bytes.NewReader(b))
+	for s.Scan() {
+		l := strings.TrimSpace(s.Text())
+		if strings.HasPrefix(l, "[") {
+			section = l
+			continue
+		}
+		if section != `[remote "origin"]` {
+			continue
+		}
+		kv := strings.SplitN(l, "=", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) != "url" {
+			continue
+		}
+		if m := reGitHubRemoteURL.FindStringSubmatch(strings.TrimSpace(kv[1])); m != nil {
+			return m[1]
+		}
+		return ""
+	}
+	return ""
+}
+
 // Knows returns true when the project knows the given file. When a file is included in the
 // project's directory, the project knows the file.
 func (p *Project) Knows(path string) bool {
@@ -65,21 +131,95 @@ func (p *Project) Config() (*Config, error) {
 		return p.config, nil
 	}
 
+	path, b := p.findConfigFile()
+	if path == "" {
+		return nil, nil // not found
+	}
+	cfg, err := parseConfig(b, path)
+	if err != nil {
+		return nil, err
+	}
+	p.config = cfg
+	return cfg, nil
+}
+
+// findConfigFile reads the config file of the project and returns its path and content. The path
+// is empty when no config file is found.
+func (p *Project) findConfigFile() (string, []byte) {
 	for _, f := range []string{"actionlint.yaml", "actionlint.yml"} {
 		path := filepath.Join(p.root, ".github", f)
-		b, err := os.ReadFile(path)
+		if b, err := os.ReadFile(path); err == nil {
+			return path, b
+		}
+	}
+	return "", nil
+}
+
+// findFiles finds files in the project which match any of the glob patterns. Patterns are matched
+// with slash-separated paths relative to the root directory. "**" in patterns matches zero or more
+// directories. The returned file paths are sorted.
+func (p *Project) findFiles(patterns []string) ([]string, error) {
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+
+	files := []string{}
+	if err := filepath.Walk(p.root, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
-			continue // file does not exist
+			return err
 		}
-		cfg, err := parseConfig(b, path)
+		if info.IsDir() {
+			// Dependencies in node_modules may contain their own action.yml
+			if n := info.Name(); n == ".git" || n == "node_modules" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		r, err := filepath.Rel(p.root, path)
 		if err != nil {
-			return nil, err
+			return nil
+		}
+		r = filepath.ToSlash(r)
+		for _, pat := range patterns {
+			if matchFilePattern(pat, r) {
+				files = append(files, path)
+				break
+			}
 		}
-		p.config = cfg
-		return cfg, nil
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("could not find files in %q: %w", p.root, err)
 	}
 
-	return nil, nil // not found
+	sort.Strings(files)
+	return files, nil
+}
+
+// matchFilePattern matches the slash-separated path with the glob pattern. In addition to the
+// syntax of path.Match, "**" matches zero or more directories.
+func matchFilePattern(pat, p string) bool {
+	return matchPathSegments(strings.Split(pat, "/"), strings.Split(p, "/"))
+}
+
+func matchPathSegments(pats, segs []string) bool {
+	for len(pats) > 0 {
+		if pats[0] == "**" {
+			for i := 0; i <= len(segs); i++ {
+				if matchPathSegments(pats[1:], segs[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(segs) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pats[0], segs[0]); !ok {
+			return false
+		}
+		pats, segs = pats[1:], segs[1:]
+	}
+	return len(segs) == 0
 }
 
 // Projects represents set of projects. It caches Project instances which was created previously
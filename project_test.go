@@ -0,0 +1,167 @@
+package actionlint
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestProjectOriginRepositoryInGitConfig(t *testing.T) {
+	testCases := []struct {
+		what   string
+		config string
+		want   string
+	}{
+		{
+			what:   "HTTPS URL",
+			config: "[core]\n\tbare = false\n[remote \"origin\"]\n\turl = https://github.com/rhysd/actionlint.git\n\tfetch = +refs/heads/*:refs/remotes/origin/*\n",
+			want:   "rhysd/actionlint",
+		},
+		{
+			what:   "HTTPS URL without .git",
+			config: "[remote \"origin\"]\n\turl = https://github.com/rhysd/actionlint\n",
+			want:   "rhysd/actionlint",
+		},
+		{
+			what:   "SSH URL",
+			config: "[remote \"origin\"]\n\turl = git@github.com:rhysd/actionlint.git\n",
+			want:   "rhysd/actionlint",
+		},
+		{
+			what:   "other remote before origin",
+			config: "[remote \"upstream\"]\n\turl = https://github.com/other/actionlint.git\n[remote \"origin\"]\n\turl = https://github.com/rhysd/actionlint.git\n",
+			want:   "rhysd/actionlint",
+		},
+		{
+			what:   "no origin",
+			config: "[remote \"upstream\"]\n\turl = https://github.com/rhysd/actionlint.git\n",
+		},
+		{
+			what:   "not GitHub",
+			config: "[remote \"origin\"]\n\turl = https://gitlab.com/rhysd/actionlint.git\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			if have := originRepositoryInGitConfig([]byte(tc.config)); have != tc.want {
+				t.Fatalf("wanted %q but got %q", tc.want, have)
+			}
+		})
+	}
+}
+
+func TestProjectRepository(t *testing.T) {
+	root := t.TempDir()
+	g := filepath.Join(root, ".git")
+	if err := os.Mkdir(g, 0755); err != nil {
+		t.Fatal(err)
+	}
+	c := "[remote \"origin\"]\n\turl = https://github.com/rhysd/actionlint.git\n"
+	if err := os.WriteFile(filepath.Join(g, "config"), []byte(c), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	p := &Project{root: root}
+	if have, want := p.Repository(), "rhysd/actionlint"; have != want {
+		t.Fatalf("wanted %q but got %q", want, have)
+	}
+
+	// .git is a file in worktree
+	wt := t.TempDir()
+	d := filepath.Join(g, "worktrees", "foo")
+	if err := os.MkdirAll(d, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(d, "commondir"), []byte("../..\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(wt, ".git"), []byte("gitdir: "+d+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	p = &Project{root: wt}
+	if have, want := p.Repository(), "rhysd/actionlint"; have != want {
+		t.Fatalf("wanted %q but got %q for worktree", want, have)
+	}
+
+	p = &Project{root: t.TempDir()}
+	if have := p.Repository(); have != "" {
+		t.Fatalf("wanted empty string but got %q for directory without .git", have)
+	}
+}
+
+func TestProjectMatchFilePattern(t *testing.T) {
+	testCases := []struct {
+		pat  string
+		path string
+		want bool
+	}{
+		{"action.yml", "action.yml", true},
+		{"action.yml", "foo/action.yml", false},
+		{"*/action.yml", "foo/action.yml", true},
+		{"*/action.yml", "foo/bar/action.yml", false},
+		{"**/action.yml", "action.yml", true},
+		{"**/action.yml", "foo/action.yml", true},
+		{"**/action.yml", "foo/bar/baz/action.yml", true},
+		{"**/action.yml", "foo/action.yaml", false},
+		{"**/action.y*ml", "foo/action.yaml", true},
+		{"actions/**/action.yml", "actions/action.yml", true},
+		{"actions/**/action.yml", "actions/foo/bar/action.yml", true},
+		{"actions/**/action.yml", "other/foo/action.yml", false},
+		{"ci/**", "ci/foo/bar.yaml", true},
+		{"ci/*.yaml", "ci/foo.yaml", true},
+		{"ci/*.yaml", "ci/foo/bar.yaml", false},
+		{"**/workflows/*.yaml", "tools/ci/workflows/test.yaml", true},
+	}
+
+	for _, tc := range testCases {
+		if have := matchFilePattern(tc.pat, tc.path); have != tc.want {
+			t.Errorf("pattern %q for path %q: wanted %v but got %v", tc.pat, tc.path, tc.want, have)
+		}
+	}
+}
+
+func TestProjectFindFilesInNestedDirectories(t *testing.T) {
+	root := t.TempDir()
+	for _, f := range []string{
+		"action.yml",
+		"actions/foo/action.yml",
+		"actions/nested/bar/action.yaml",
+		"actions/nested/bar/README.md",
+		"node_modules/dep/action.yml",
+		".git/action.yml",
+	} {
+		p := filepath.Join(root, filepath.FromSlash(f))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte{}, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	p := &Project{root: root}
+	have, err := p.findFiles([]string{"**/action.yml", "**/action.yaml"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		filepath.Join(root, "action.yml"),
+		filepath.Join(root, "actions", "foo", "action.yml"),
+		filepath.Join(root, "actions", "nested", "bar", "action.yaml"),
+	}
+	if !cmp.Equal(want, have) {
+		t.Fatal(cmp.Diff(want, have))
+	}
+
+	have, err = p.findFiles(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(have) > 0 {
+		t.Fatalf("no file should be found without patterns but got %v", have)
+	}
+}
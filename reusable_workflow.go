@@ -218,6 +218,9 @@ func (c *LocalReusableWorkflowCache) FindMetadata(spec string) (*ReusableWorkflo
 	src, err := os.ReadFile(file)
 	if err != nil {
 		c.writeCache(spec, nil) // Remember the workflow file was not found
+		if alt := c.findAlternativeExtension(spec); alt != "" {
+			return nil, fmt.Errorf("could not read reusable workflow file for %q: %w. did you mean %q?", spec, err, alt)
+		}
 		return nil, fmt.Errorf("could not read reusable workflow file for %q: %w", spec, err)
 	}
 
@@ -233,6 +236,24 @@ func (c *LocalReusableWorkflowCache) FindMetadata(spec string) (*ReusableWorkflo
 	return m, nil
 }
 
+// findAlternativeExtension returns the spec with another YAML file extension (".yml" or ".yaml")
+// when the workflow file with the extension exists. Otherwise it returns an empty string. This is
+// useful to detect a typo in the file extension.
+func (c *LocalReusableWorkflowCache) findAlternativeExtension(spec string) string {
+	var alt string
+	if strings.HasSuffix(spec, ".yml") {
+		alt = strings.TrimSuffix(spec, ".yml") + ".yaml"
+	} else if strings.HasSuffix(spec, ".yaml") {
+		alt = strings.TrimSuffix(spec, ".yaml") + ".yml"
+	} else {
+		return ""
+	}
+	if _, err := os.Stat(filepath.Join(c.proj.RootDir(), filepath.FromSlash(alt))); err != nil {
+		return ""
+	}
+	return alt
+}
+
 func (c *LocalReusableWorkflowCache) convWorkflowPathToSpec(p string) (string, bool) {
 	if c.proj == nil {
 		return "", false
@@ -8,9 +8,17 @@ import (
 // RuleBase is a struct to be a base of rule structs. Embed this struct to define default methods
 // automatically
 type RuleBase struct {
-	name string
-	errs []*Error
-	dbg  io.Writer
+	name   string
+	errs   []*Error
+	dbg    io.Writer
+	config *Config
+}
+
+// NewRuleBase creates a new RuleBase instance with the rule name. The name is used as kind of errors
+// reported by the rule. This is useful to define a custom rule outside this package by embedding
+// RuleBase. See Linter.RegisterRule for registering the custom rule.
+func NewRuleBase(name string) RuleBase {
+	return RuleBase{name: name}
 }
 
 // VisitStep is callback when visiting Step node.
@@ -28,6 +36,9 @@ func (r *RuleBase) VisitWorkflowPre(node *Workflow) error { return nil }
 // VisitWorkflowPost is callback when visiting Workflow node after visiting its children.
 func (r *RuleBase) VisitWorkflowPost(node *Workflow) error { return nil }
 
+// VisitExpr is callback when visiting an expression in ${{ }}.
+func (r *RuleBase) VisitExpr(node ExprNode, pos *Pos) {}
+
 func (r *RuleBase) error(pos *Pos, msg string) {
 	err := errorAt(pos, r.name, msg)
 	r.errs = append(r.errs, err)
@@ -38,6 +49,26 @@ func (r *RuleBase) errorf(pos *Pos, format string, args ...interface{}) {
 	r.errs = append(r.errs, err)
 }
 
+// errorfWithFix reports an error with the fix suggested by the rule. The fix may be nil when no fix
+// is available.
+func (r *RuleBase) errorfWithFix(pos *Pos, fix *ErrorFix, format string, args ...interface{}) {
+	err := errorfAt(pos, r.name, format, args...)
+	err.Fix = fix
+	r.errs = append(r.errs, err)
+}
+
+func (r *RuleBase) warnf(pos *Pos, format string, args ...interface{}) {
+	err := errorfAt(pos, r.name, format, args...)
+	err.Severity = SeverityWarning
+	r.errs = append(r.errs, err)
+}
+
+func (r *RuleBase) infof(pos *Pos, format string, args ...interface{}) {
+	err := errorfAt(pos, r.name, format, args...)
+	err.Severity = SeverityInfo
+	r.errs = append(r.errs, err)
+}
+
 func (r *RuleBase) debug(format string, args ...interface{}) {
 	if r.dbg == nil {
 		return
@@ -46,6 +77,30 @@ func (r *RuleBase) debug(format string, args ...interface{}) {
 	fmt.Fprintf(r.dbg, format, args...)
 }
 
+// Errorf reports an error at the position with the formatted message. This is for custom rules
+// defined outside this package.
+func (r *RuleBase) Errorf(pos *Pos, format string, args ...interface{}) {
+	r.errorf(pos, format, args...)
+}
+
+// Warnf reports an error with SeverityWarning at the position with the formatted message. This is
+// for custom rules defined outside this package.
+func (r *RuleBase) Warnf(pos *Pos, format string, args ...interface{}) {
+	r.warnf(pos, format, args...)
+}
+
+// Infof reports an error with SeverityInfo at the position with the formatted message. This is for
+// custom rules defined outside this package.
+func (r *RuleBase) Infof(pos *Pos, format string, args ...interface{}) {
+	r.infof(pos, format, args...)
+}
+
+// Debug prints the formatted debug message when debug output is enabled. This is for custom rules
+// defined outside this package.
+func (r *RuleBase) Debug(format string, args ...interface{}) {
+	r.debug(format, args...)
+}
+
 // Errs returns errors found by the rule.
 func (r *RuleBase) Errs() []*Error {
 	return r.errs
@@ -62,10 +117,110 @@ func (r *RuleBase) EnableDebug(out io.Writer) {
 	r.dbg = out
 }
 
+// SetConfig sets user configuration. The config may be nil when no configuration is given.
+func (r *RuleBase) SetConfig(cfg *Config) {
+	r.config = cfg
+}
+
+// Config returns user configuration. It returns nil when no configuration is set.
+func (r *RuleBase) Config() *Config {
+	return r.config
+}
+
+// isCheckEnabled returns if the optional check is enabled by user configuration. Optional checks
+// are disabled by default.
+func (r *RuleBase) isCheckEnabled(name string) bool {
+	return r.config != nil && r.config.isCheckEnabled(name)
+}
+
+// RuleInfo is information of a rule. It is used for listing rules with -list-rules option.
+type RuleInfo struct {
+	// Name is a name of the rule. It is shown at the end of error messages like "[expression]".
+	Name string `json:"name"`
+	// Description is a one-line description of the rule.
+	Description string `json:"description"`
+	// Severity is the highest severity of errors which the rule reports by default. It is one of
+	// "error", "warning" or "info". Severities can be overridden by "severity" configuration.
+	Severity string `json:"severity"`
+}
+
+// allRules is information of all built-in rules. "syntax-check" and "yaml-syntax" are kinds of
+// errors reported while parsing workflows. "workflow-name" is a kind of errors reported across
+// multiple workflow files. "disable-comment" is a kind of errors reported for stale
+// "actionlint-disable" comments. "action-metadata" is a kind of errors reported for action metadata
+// files.
+var allRules = []*RuleInfo{
+	{"action", "Checks inputs of actions at \"uses:\" and usage of popular actions", "error"},
+	{"action-metadata", "Checks structure of action metadata files given by \"files.actions\" configuration", "error"},
+	{"container-image", "Checks container image names at \"container:\" and \"services:\"", "error"},
+	{"credentials", "Checks passwords are not hardcoded at \"container:\" and \"services:\"", "error"},
+	{"deprecated-commands", "Detects deprecated workflow commands like \"::set-output\" in \"run:\" scripts", "error"},
+	{"disable-comment", "Reports stale \"actionlint-disable\" comments which suppress no error", "warning"},
+	{"env-var", "Checks names of environment variables at \"env:\"", "error"},
+	{"events", "Checks webhook events and their filters at \"on:\"", "error"},
+	{"expression", "Checks syntax and types of ${{ }} expressions and contexts available in them", "error"},
+	{"glob", "Checks glob patterns in filters like \"branches:\" and \"paths:\"", "error"},
+	{"id", "Checks job IDs and step IDs are valid and unique", "error"},
+	{"job-needs", "Checks dependencies between jobs at \"needs:\"", "error"},
+	{"limits", "Checks limits of workflows like the number of steps in a job", "error"},
+	{"matrix", "Checks values and combinations of \"strategy.matrix\"", "error"},
+	{"permissions", "Checks permissions of GITHUB_TOKEN at \"permissions:\"", "error"},
+	{"pyflakes", "Checks Python scripts at \"run:\" with pyflakes", "error"},
+	{"run-script", "Checks contents of \"run:\" scripts like hardcoded repository names", "warning"},
+	{"runner-label", "Checks runner labels at \"runs-on:\"", "error"},
+	{"shell-name", "Checks shell names at \"shell:\" and working directories at \"defaults.run\"", "error"},
+	{"shellcheck", "Checks shell scripts at \"run:\" with shellcheck", "error"},
+	{"syntax-check", "Checks syntax of workflows like unexpected keys and missing required keys", "error"},
+	{"workflow-call", "Checks calls of reusable workflows at \"jobs.<job_id>.uses\"", "error"},
+	{"workflow-name", "Detects workflow names duplicated across workflow files", "warning"},
+	{"yaml-syntax", "Reports syntax errors of YAML in workflow files", "error"},
+}
+
+// allRuleNames is names of all built-in rules.
+var allRuleNames = func() []string {
+	ns := make([]string, 0, len(allRules))
+	for _, r := range allRules {
+		ns = append(ns, r.Name)
+	}
+	return ns
+}()
+
+// BuiltinRules returns information of all built-in rules sorted by their names.
+func BuiltinRules() []*RuleInfo {
+	ret := make([]*RuleInfo, 0, len(allRules))
+	for _, r := range allRules {
+		c := *r
+		ret = append(ret, &c)
+	}
+	return ret
+}
+
+// isKnownRuleName returns whether the name is a name of built-in rules or custom rules. The custom
+// parameter is names of custom rules registered with Linter.RegisterRule.
+func isKnownRuleName(name string, custom []string) bool {
+	for _, n := range allRuleNames {
+		if n == name {
+			return true
+		}
+	}
+	for _, n := range custom {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
 // Rule is an interface which all rule structs must meet
 type Rule interface {
 	Pass
+	// VisitExpr is callback when visiting an expression in ${{ }} after it was parsed. The pos
+	// parameter is the position where the expression starts. A token at line L and column C of the
+	// expression is at line pos.Line+L-1 and column pos.Col+C-1 in the file. The syntax tree is
+	// shared across files so it must not be modified.
+	VisitExpr(node ExprNode, pos *Pos)
 	Errs() []*Error
 	Name() string
 	EnableDebug(out io.Writer)
+	SetConfig(cfg *Config)
 }
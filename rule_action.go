@@ -3,6 +3,8 @@ package actionlint
 import (
 	"fmt"
 	"net/url"
+	"path"
+	"regexp"
 	"strconv"
 	"strings"
 )
@@ -11,19 +13,72 @@ import (
 // https://docs.github.com/en/actions/learn-github-actions/workflow-syntax-for-github-actions#jobsjob_idstepsuses
 type RuleAction struct {
 	RuleBase
-	cache *LocalActionsCache
+	cache        *LocalActionsCache
+	lines        []string
+	cacheLookups []*Step
+	cacheSaves   []*ExecAction
+	sparse       *Step
+	sparseDirs   []string
+	// Event which runs the workflow with write token in the context of the base repository such as
+	// "pull_request_target". nil when the workflow is not triggered by such events.
+	privilegedEvent *String
 }
 
-// NewRuleAction creates new RuleAction instance.
-func NewRuleAction(cache *LocalActionsCache) *RuleAction {
+// NewRuleAction creates new RuleAction instance. The src parameter is the source of the workflow.
+// It is used for locating paths in scripts. When it is nil, errors for scripts are reported at
+// "run:".
+func NewRuleAction(cache *LocalActionsCache, src []byte) *RuleAction {
+	var lines []string
+	if src != nil {
+		lines = strings.Split(string(src), "\n")
+	}
 	return &RuleAction{
-		RuleBase: RuleBase{name: "action"},
-		cache:    cache,
+		RuleBase:     RuleBase{name: "action"},
+		cache:        cache,
+		lines:        lines,
+		cacheLookups: nil,
+		cacheSaves:   nil,
+	}
+}
+
+// VisitWorkflowPre is callback when visiting Workflow node before visiting its children.
+func (rule *RuleAction) VisitWorkflowPre(n *Workflow) error {
+	rule.privilegedEvent = nil
+	for _, e := range n.On {
+		if w, ok := e.(*WebhookEvent); ok && (w.Hook.Value == "pull_request_target" || w.Hook.Value == "workflow_run") {
+			rule.privilegedEvent = w.Hook
+			break
+		}
 	}
+	return nil
+}
+
+// VisitJobPre is callback when visiting Job node before visiting its children.
+func (rule *RuleAction) VisitJobPre(n *Job) error {
+	rule.cacheLookups = nil
+	rule.cacheSaves = nil
+	rule.sparse = nil
+	rule.sparseDirs = nil
+	return nil
+}
+
+// VisitJobPost is callback when visiting Job node after visiting its children.
+func (rule *RuleAction) VisitJobPost(n *Job) error {
+	if rule.isCheckEnabled("cache-lookup-only") {
+		rule.checkCacheLookupOnly(n)
+	}
+	return nil
 }
 
 // VisitStep is callback when visiting Step node.
 func (rule *RuleAction) VisitStep(n *Step) error {
+	if r, ok := n.Exec.(*ExecRun); ok {
+		if rule.sparse != nil && rule.isCheckEnabled("sparse-checkout") {
+			rule.checkSparseCheckoutPaths(r)
+		}
+		return nil
+	}
+
 	e, ok := n.Exec.(*ExecAction)
 	if !ok || e.Uses == nil {
 		return nil
@@ -36,6 +91,15 @@ func (rule *RuleAction) VisitStep(n *Step) error {
 		return nil
 	}
 
+	rule.collectCacheUsage(spec, n, e)
+	rule.collectSparseCheckout(spec, n, e)
+	if rule.isCheckEnabled("floating-setup-version") {
+		rule.checkFloatingSetupVersion(spec, n, e)
+	}
+	if rule.privilegedEvent != nil && rule.isCheckEnabled("checkout-persist-credentials") {
+		rule.checkCheckoutPersistCredentials(spec, e)
+	}
+
 	if strings.HasPrefix(spec, "./") {
 		// Relative to repository root
 		rule.checkLocalAction(spec, e)
@@ -51,6 +115,34 @@ func (rule *RuleAction) VisitStep(n *Step) error {
 	return nil
 }
 
+var reFullCommitSHA = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// checkPinnedToCommitSHA checks the action is pinned to a full length commit SHA. Tags and branches
+// are mutable so they can be replaced with malicious code. Actions matching to patterns in
+// "pinned-actions.allow" configuration are exempt. This is an optional check enabled by
+// "pinned-actions".
+// https://docs.github.com/en/actions/security-guides/security-hardening-for-github-actions#using-third-party-actions
+func (rule *RuleAction) checkPinnedToCommitSHA(name, ref string, exec *ExecAction) {
+	if reFullCommitSHA.MatchString(ref) {
+		return
+	}
+	if cfg := rule.Config(); cfg != nil {
+		for _, p := range cfg.PinnedActions.Allow {
+			// Owner and repository names are case-insensitive
+			if ok, _ := path.Match(strings.ToLower(p), strings.ToLower(name)); ok {
+				return
+			}
+		}
+	}
+	rule.warnf(
+		exec.Uses.Pos,
+		"action %q is not pinned to a full length commit SHA. ref %q is mutable. pin the action like \"%s@<commit SHA>\" for supply-chain security",
+		exec.Uses.Value,
+		ref,
+		name,
+	)
+}
+
 // Parse {owner}/{repo}@{ref} or {owner}/{repo}/{path}@{ref}
 func (rule *RuleAction) checkRepoAction(spec string, exec *ExecAction) {
 	s := spec
@@ -61,6 +153,7 @@ func (rule *RuleAction) checkRepoAction(spec string, exec *ExecAction) {
 	}
 	ref := s[idx+1:]
 	s = s[:idx] // remove {ref}
+	name := s
 
 	idx = strings.IndexRune(s, '/')
 	if idx == -1 {
@@ -79,6 +172,12 @@ func (rule *RuleAction) checkRepoAction(spec string, exec *ExecAction) {
 
 	if owner == "" || repo == "" || ref == "" {
 		rule.invalidActionFormat(exec.Uses.Pos, spec, "owner and repo and ref should not be empty")
+	} else if rule.isCheckEnabled("pinned-actions") {
+		rule.checkPinnedToCommitSHA(name, ref, exec)
+	}
+
+	if owner != "" && repo != "" {
+		rule.checkTyposquatting(owner, repo, exec)
 	}
 
 	meta, ok := PopularActions[spec]
@@ -96,12 +195,74 @@ func (rule *RuleAction) checkRepoAction(spec string, exec *ExecAction) {
 	})
 }
 
+// popularActionRepos is a map from "owner/repo" names of popular actions in lower case to the
+// original names.
+var popularActionRepos = func() map[string]string {
+	m := map[string]string{}
+	for spec := range PopularActions {
+		ss := strings.SplitN(spec[:strings.IndexRune(spec, '@')], "/", 3)
+		n := ss[0] + "/" + ss[1]
+		m[strings.ToLower(n)] = n
+	}
+	return m
+}()
+
+// checkTyposquatting checks the action's "owner/repo" is not similar to the name of a popular action
+// owned by other owner. Attackers publish actions with typos of popular ones like "action/checkout"
+// to steal secrets. Note that actions owned by the same owner are not reported since they cannot be
+// published by attackers.
+func (rule *RuleAction) checkTyposquatting(owner, repo string, exec *ExecAction) {
+	name := strings.ToLower(owner + "/" + repo)
+	if _, ok := popularActionRepos[name]; ok {
+		return
+	}
+
+	// Allow 1 typo per 8 characters up to 2 typos. Action names are long so allowing typos like
+	// findSimilarStrings causes false positives
+	max := len(name) / 8
+	if max < 1 {
+		max = 1
+	} else if max > 2 {
+		max = 2
+	}
+
+	cands := []string{}
+	for l, p := range popularActionRepos {
+		if strings.HasPrefix(l, strings.ToLower(owner)+"/") {
+			continue
+		}
+		if editDistance(name, l) <= max {
+			cands = append(cands, p)
+		}
+	}
+	if len(cands) == 0 {
+		return
+	}
+
+	rule.warnf(
+		exec.Uses.Pos,
+		"action %q is similar to popular action(s) %s but it is owned by a different owner. it may be a typosquatting action published by an attacker. make sure the action is what you intended",
+		exec.Uses.Value,
+		sortedQuotes(cands),
+	)
+}
+
 func (rule *RuleAction) invalidActionFormat(pos *Pos, spec string, why string) {
 	rule.errorf(pos, "specifying action %q in invalid format because %s. available formats are \"{owner}/{repo}@{ref}\" or \"{owner}/{repo}/{path}@{ref}\"", spec, why)
 }
 
 // https://docs.github.com/en/actions/learn-github-actions/workflow-syntax-for-github-actions#example-using-the-github-packages-container-registry
 func (rule *RuleAction) checkDockerAction(uri string, exec *ExecAction) {
+	digest := ""
+	if idx := strings.LastIndexByte(uri, '@'); idx != -1 {
+		digest = uri[idx+1:]
+		uri = uri[:idx]
+		if err := validateImageDigest(digest); err != nil {
+			rule.errorf(exec.Uses.Pos, "digest of Docker action %q is malformed. %s", exec.Uses.Value, err.Error())
+			return
+		}
+	}
+
 	tag := ""
 	tagExists := false
 	if idx := strings.IndexRune(uri[len("docker://"):], ':'); idx != -1 {
@@ -125,6 +286,20 @@ func (rule *RuleAction) checkDockerAction(uri string, exec *ExecAction) {
 
 	if tagExists && tag == "" {
 		rule.errorf(exec.Uses.Pos, "tag of Docker action should not be empty: %q", uri)
+		return
+	}
+
+	if digest == "" && rule.isCheckEnabled("pinned-images") {
+		if tag == "" {
+			tag = "latest"
+		}
+		rule.warnf(
+			exec.Uses.Pos,
+			"Docker action %q is not pinned to a digest. tag %q is mutable. pin the image like \"%s@sha256:<digest>\" for reproducibility",
+			exec.Uses.Value,
+			tag,
+			exec.Uses.Value,
+		)
 	}
 }
 
@@ -183,3 +358,301 @@ func (rule *RuleAction) checkAction(meta *ActionMetadata, exec *ExecAction, desc
 		}
 	}
 }
+
+// actionInputValue returns the value of the input of the action at "with:". When the input is not
+// set, it returns an empty string.
+func actionInputValue(exec *ExecAction, name string) string {
+	if i, ok := exec.Inputs[name]; ok && i.Value != nil {
+		return i.Value.Value
+	}
+	return ""
+}
+
+// collectCacheUsage collects steps which look up or save caches with actions/cache. Steps of
+// actions/cache without "lookup-only: true" save the cache in their post steps.
+func (rule *RuleAction) collectCacheUsage(spec string, step *Step, exec *ExecAction) {
+	idx := strings.IndexRune(spec, '@')
+	if idx == -1 {
+		return
+	}
+	switch strings.ToLower(spec[:idx]) {
+	case "actions/cache":
+		if strings.TrimSpace(actionInputValue(exec, "lookup-only")) == "true" {
+			rule.cacheLookups = append(rule.cacheLookups, step)
+		} else {
+			rule.cacheSaves = append(rule.cacheSaves, exec)
+		}
+	case "actions/cache/restore":
+		if strings.TrimSpace(actionInputValue(exec, "lookup-only")) == "true" {
+			rule.cacheLookups = append(rule.cacheLookups, step)
+		}
+	case "actions/cache/save":
+		rule.cacheSaves = append(rule.cacheSaves, exec)
+	}
+}
+
+// checkCacheLookupOnly checks actions/cache steps with "lookup-only: true" have corresponding steps
+// to save the cache in the same job. Such steps only check the existence of the cache and never
+// save it. This is an optional check enabled by "cache-lookup-only".
+// https://github.com/actions/cache#inputs
+func (rule *RuleAction) checkCacheLookupOnly(job *Job) {
+Lookups:
+	for _, s := range rule.cacheLookups {
+		key := actionInputValue(s.Exec.(*ExecAction), "key")
+		for _, save := range rule.cacheSaves {
+			k := actionInputValue(save, "key")
+			if key == "" || k == "" || key == k {
+				continue Lookups
+			}
+		}
+
+		if key == "" {
+			rule.warnf(
+				s.Pos,
+				"cache is looked up with \"lookup-only: true\" but no step saves the cache in job %q. the cache will stay empty since \"lookup-only\" never saves the cache. save it with \"actions/cache/save\" or remove \"lookup-only\"",
+				job.ID.Value,
+			)
+		} else {
+			rule.warnf(
+				s.Pos,
+				"cache with key %q is looked up with \"lookup-only: true\" but no step saves the cache with the same key in job %q. the cache will stay empty since \"lookup-only\" never saves the cache. save it with \"actions/cache/save\" or remove \"lookup-only\"",
+				key,
+				job.ID.Value,
+			)
+		}
+	}
+}
+
+// collectSparseCheckout collects directories checked out by "sparse-checkout" input of
+// actions/checkout. Only cone mode patterns are collected since patterns in non-cone mode are
+// too complicated to correlate with paths in scripts.
+func (rule *RuleAction) collectSparseCheckout(spec string, step *Step, exec *ExecAction) {
+	idx := strings.IndexRune(spec, '@')
+	if idx == -1 || strings.ToLower(spec[:idx]) != "actions/checkout" {
+		return
+	}
+	if actionInputValue(exec, "path") != "" {
+		return // Checked out to other directory. The workspace root is not affected
+	}
+
+	rule.sparse = nil
+	rule.sparseDirs = nil
+
+	input := actionInputValue(exec, "sparse-checkout")
+	if strings.TrimSpace(input) == "" || strings.Contains(input, "${{") {
+		return
+	}
+	if strings.TrimSpace(actionInputValue(exec, "sparse-checkout-cone-mode")) == "false" {
+		return
+	}
+
+	dirs := []string{}
+	for _, l := range strings.Split(input, "\n") {
+		l = strings.Trim(strings.TrimSpace(l), "/")
+		if l == "" {
+			continue
+		}
+		if strings.ContainsAny(l, "*?[!\\") {
+			rule.debug("Give up checking paths in sparse checkout since pattern %q is not for cone mode", l)
+			return
+		}
+		dirs = append(dirs, l)
+	}
+	if len(dirs) == 0 {
+		return
+	}
+
+	rule.sparse = step
+	rule.sparseDirs = dirs
+}
+
+var (
+	reScriptPathDelim = regexp.MustCompile(`[\s'"=;&|()<>,]+`)
+	reScriptCdCommand = regexp.MustCompile(`(?:^|[;&|({]\s*)cd(?:\s|$)`)
+)
+
+// scriptPathsInLine extracts paths relative to the repository root from a line of script. This is
+// heuristic. Only tokens starting with "./" or whose base names have file extensions are
+// regarded as paths to avoid false positives.
+func scriptPathsInLine(line string) []string {
+	if i := strings.Index(line, "#"); i >= 0 && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t') {
+		line = line[:i] // Remove comment
+	}
+
+	ret := []string{}
+	for _, t := range reScriptPathDelim.Split(line, -1) {
+		if t == "" || strings.ContainsAny(t, "$~`@:{}*?[]\\") || strings.Contains(t, "..") {
+			continue
+		}
+		dot := strings.HasPrefix(t, "./")
+		if dot {
+			t = t[2:]
+		}
+		t = strings.TrimRight(t, "/")
+		if t == "" || strings.HasPrefix(t, "/") || strings.HasPrefix(t, "-") || !strings.Contains(t, "/") {
+			continue // Absolute paths, options, and files at root are not target of the check
+		}
+		first := t[:strings.IndexByte(t, '/')]
+		if !dot {
+			if strings.Contains(first, ".") && !strings.HasPrefix(first, ".") {
+				continue // Domain name like "github.com/owner/repo"
+			}
+			if !strings.Contains(path.Base(t), ".") {
+				continue
+			}
+		}
+		ret = append(ret, t)
+	}
+	return ret
+}
+
+// isSparseCheckoutPath returns if the path is checked out by sparse checkout in cone mode with
+// the directories. In cone mode, files directly under the ancestor directories of the
+// directories are also checked out.
+func isSparseCheckoutPath(p string, dirs []string) bool {
+	parent := path.Dir(p)
+	if parent == "." {
+		return true // Files at root are always checked out
+	}
+	for _, d := range dirs {
+		if p == d || strings.HasPrefix(p, d+"/") || strings.HasPrefix(d, p+"/") || strings.HasPrefix(d, parent+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// checkSparseCheckoutPaths checks paths referenced in the script are included in the previous
+// sparse checkout by actions/checkout. Scripts fail when they access files excluded by sparse
+// checkout. This is an optional check enabled by "sparse-checkout".
+// https://github.com/actions/checkout#fetch-only-a-single-file
+func (rule *RuleAction) checkSparseCheckoutPaths(exec *ExecRun) {
+	if exec.Run == nil || exec.WorkingDirectory != nil {
+		return
+	}
+
+	reported := map[string]struct{}{}
+	for i, line := range strings.Split(exec.Run.Value, "\n") {
+		if reScriptCdCommand.MatchString(line) {
+			return // Paths are no longer relative to the repository root
+		}
+		if strings.Contains(line, "${{") {
+			continue
+		}
+		for _, p := range scriptPathsInLine(line) {
+			if _, ok := reported[p]; ok || isSparseCheckoutPath(p, rule.sparseDirs) {
+				continue
+			}
+			reported[p] = struct{}{}
+			pos := exec.Run.linePos(rule.lines, i, line)
+			if pos == nil {
+				pos = exec.Run.Pos
+			} else if j := strings.Index(strings.TrimLeft(line, " \t"), p); j >= 0 {
+				pos.Col += j
+			}
+			rule.warnf(
+				pos,
+				"path %q is not included in sparse checkout by actions/checkout at line %d. included paths are %s. add the path to \"sparse-checkout\" input",
+				p,
+				rule.sparse.Pos.Line,
+				sortedQuotes(rule.sparseDirs),
+			)
+		}
+	}
+}
+
+// setupActionVersionInputs is a map from names of setup actions to their inputs of version
+// specifiers.
+var setupActionVersionInputs = map[string]string{
+	"actions/setup-dotnet": "dotnet-version",
+	"actions/setup-go":     "go-version",
+	"actions/setup-java":   "java-version",
+	"actions/setup-node":   "node-version",
+	"actions/setup-python": "python-version",
+	"ruby/setup-ruby":      "ruby-version",
+}
+
+// reCheckoutPullRequestRef matches expressions in "ref" and "repository" inputs of actions/checkout
+// which point at code controlled by pull requests like "${{ github.event.pull_request.head.sha }}" or
+// "refs/pull/${{ github.event.number }}/merge".
+var reCheckoutPullRequestRef = regexp.MustCompile(`\bgithub\.(?:event\.pull_request\.head\.|event\.workflow_run\.head_|head_ref\b)|\brefs/pull/`)
+
+// checkCheckoutPersistCredentials checks actions/checkout sets "persist-credentials: false" when it
+// checks out code from pull requests in the workflow triggered by "pull_request_target" or
+// "workflow_run" events. By default, actions/checkout persists the token in the local git config
+// and the following steps can read it. When the workflow runs the code from pull requests, the
+// untrusted code can steal the token which has write permissions. The code is regarded as from pull
+// requests when "ref" or "repository" input refers the head of the pull request. Any ref of the
+// action like "v4", "v4.1.1" or a commit SHA is matched. This is an optional check enabled by
+// "checkout-persist-credentials".
+// https://securitylab.github.com/research/github-actions-preventing-pwn-requests/
+func (rule *RuleAction) checkCheckoutPersistCredentials(spec string, exec *ExecAction) {
+	idx := strings.IndexRune(spec, '@')
+	if idx == -1 || strings.ToLower(spec[:idx]) != "actions/checkout" {
+		return
+	}
+	if !reCheckoutPullRequestRef.MatchString(actionInputValue(exec, "ref")) && !reCheckoutPullRequestRef.MatchString(actionInputValue(exec, "repository")) {
+		return // Code of the base repository is trusted
+	}
+	v := strings.TrimSpace(actionInputValue(exec, "persist-credentials"))
+	if strings.EqualFold(v, "false") || strings.Contains(v, "${{") {
+		return
+	}
+	rule.warnf(
+		exec.Uses.Pos,
+		"\"persist-credentials: false\" is not set at %q in the workflow triggered by %q event. the token persisted in git config can be stolen by untrusted code checked out from pull requests. set \"persist-credentials: false\" in \"with:\"",
+		spec,
+		rule.privilegedEvent.Value,
+	)
+}
+
+// reFloatingVersion matches version specifiers which resolve to different versions over time like
+// "3.x", "lts/*", ">=3.8" or "^1.20".
+var reFloatingVersion = regexp.MustCompile(`(?:^|[./])[xX*](?:\.|$)|[<>^~]|\|\|| - `)
+
+// checkFloatingSetupVersion checks version inputs of setup actions like "python-version" of
+// actions/setup-python do not use floating specifiers such as "3.x" or ranges. They resolve to the
+// latest matching version so the version used by the workflow can change without any change in the
+// repository. This is an optional check enabled by "floating-setup-version".
+func (rule *RuleAction) checkFloatingSetupVersion(spec string, step *Step, exec *ExecAction) {
+	idx := strings.IndexRune(spec, '@')
+	if idx == -1 {
+		return
+	}
+	name := strings.ToLower(spec[:idx])
+	input, ok := setupActionVersionInputs[name]
+	if !ok {
+		return
+	}
+	if cfg := rule.Config(); cfg != nil && len(cfg.SetupVersions.Actions) > 0 {
+		enforced := false
+		for _, a := range cfg.SetupVersions.Actions {
+			if strings.ToLower(a) == name {
+				enforced = true
+				break
+			}
+		}
+		if !enforced {
+			return
+		}
+	}
+
+	v := actionInputValue(exec, input)
+	if strings.Contains(v, "${{") {
+		return
+	}
+	// Some setup actions accept multiple versions separated by newlines
+	for _, l := range strings.Split(v, "\n") {
+		l = strings.TrimSpace(l)
+		if l == "" || !reFloatingVersion.MatchString(l) {
+			continue
+		}
+		rule.warnf(
+			step.Pos,
+			"version %q at %q input of %q action is floating. it resolves to the latest matching version and the version may change without any change in the repository. pin the exact version for reproducible builds",
+			l,
+			input,
+			spec[:idx],
+		)
+	}
+}
@@ -0,0 +1,139 @@
+package actionlint
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Grammar of image reference is defined in Docker distribution.
+// https://github.com/distribution/distribution/blob/main/reference/reference.go
+var reImageReference = regexp.MustCompile(
+	`^(?:(?:[a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9-]*[a-zA-Z0-9])(?:\.(?:[a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9-]*[a-zA-Z0-9]))*(?::[0-9]+)?/)?` + // registry
+		`[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*(?:/[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*)*` + // name
+		`(?::([\w][\w.-]{0,127}))?` + // tag
+		`(?:@([A-Za-z][A-Za-z0-9]*(?:[-_+.][A-Za-z][A-Za-z0-9]*)*:[0-9a-fA-F]{32,}))?$`, // digest
+)
+
+// imageDigestLengths is a map from algorithms of image digests to the lengths of their hex-encoded
+// values.
+// https://github.com/opencontainers/image-spec/blob/main/descriptor.md#digests
+var imageDigestLengths = map[string]int{
+	"sha256": 64,
+	"sha512": 128,
+}
+
+// validateImageDigest validates the digest of image reference like "sha256:<hex>".
+func validateImageDigest(d string) error {
+	ss := strings.SplitN(d, ":", 2)
+	if len(ss) != 2 {
+		return fmt.Errorf("digest %q should be in format \"algorithm:hex\" like \"sha256:<64 hex characters>\"", d)
+	}
+	alg, hex := ss[0], ss[1]
+	l, ok := imageDigestLengths[alg]
+	if !ok {
+		return fmt.Errorf("algorithm %q of digest %q is not supported. available algorithms are \"sha256\" and \"sha512\"", alg, d)
+	}
+	if len(hex) != l {
+		return fmt.Errorf("digest %q should have %d hexadecimal characters after %q but it has %d characters", d, l, alg+":", len(hex))
+	}
+	for _, r := range hex {
+		if !('0' <= r && r <= '9' || 'a' <= r && r <= 'f') {
+			return fmt.Errorf("digest %q contains character %q which is not lowercase hexadecimal", d, r)
+		}
+	}
+	return nil
+}
+
+// RuleContainerImage is a rule to check image references of "container" section and services.
+// Using "latest" tag is reported when "container-latest-tag" optional check is enabled. Images not
+// pinned to digests are reported when "pinned-images" optional check is enabled.
+// https://docs.github.com/en/actions/using-workflows/workflow-syntax-for-github-actions#jobsjob_idcontainerimage
+type RuleContainerImage struct {
+	RuleBase
+}
+
+// NewRuleContainerImage creates new RuleContainerImage instance.
+func NewRuleContainerImage() *RuleContainerImage {
+	return &RuleContainerImage{
+		RuleBase: RuleBase{name: "container-image"},
+	}
+}
+
+// VisitJobPre is callback when visiting Job node before visiting its children.
+func (rule *RuleContainerImage) VisitJobPre(n *Job) error {
+	if n.Container != nil {
+		rule.checkImage("\"container\" section", n.Container.Image)
+	}
+	for _, s := range n.Services {
+		if s.Container != nil {
+			rule.checkImage(fmt.Sprintf("%q service", s.Name.Value), s.Container.Image)
+		}
+	}
+	return nil
+}
+
+func (rule *RuleContainerImage) checkImage(where string, image *String) {
+	if image == nil || strings.Contains(image.Value, "${{") {
+		return
+	}
+
+	ref := strings.TrimPrefix(image.Value, "docker://")
+	if i := strings.LastIndexByte(ref, '@'); i >= 0 {
+		if err := validateImageDigest(ref[i+1:]); err != nil {
+			rule.errorf(image.Pos, "image reference %q in %s is malformed. %s", image.Value, where, err.Error())
+			return
+		}
+	}
+
+	m := reImageReference.FindStringSubmatch(ref)
+	if m == nil {
+		rule.errorf(
+			image.Pos,
+			"image reference %q in %s is malformed. it should be in format \"[registry/]name[:tag][@digest]\" where name consists of lowercase letters, digits and separators like \"node:18\" or \"ghcr.io/owner/image:1.0\"",
+			image.Value,
+			where,
+		)
+		return
+	}
+
+	tag, digest := m[1], m[2]
+	if digest != "" {
+		return
+	}
+
+	if rule.isCheckEnabled("pinned-images") {
+		t := tag
+		if t == "" {
+			t = "latest"
+		}
+		rule.warnf(
+			image.Pos,
+			"image %q in %s is not pinned to a digest. tag %q is mutable. pin the image like \"%s@sha256:<digest>\" for reproducibility",
+			image.Value,
+			where,
+			t,
+			ref,
+		)
+	}
+
+	if !rule.isCheckEnabled("container-latest-tag") {
+		return
+	}
+
+	if tag == "latest" {
+		rule.warnf(
+			image.Pos,
+			"image %q in %s uses \"latest\" tag. the image may change unexpectedly and break the job. specify a version tag or digest",
+			image.Value,
+			where,
+		)
+	} else if tag == "" {
+		rule.warnf(
+			image.Pos,
+			"image %q in %s has no tag so \"latest\" tag is used implicitly. the image may change unexpectedly and break the job. specify a version tag or digest",
+			image.Value,
+			where,
+		)
+	}
+}
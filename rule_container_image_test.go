@@ -0,0 +1,204 @@
+package actionlint
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRuleContainerImageReference(t *testing.T) {
+	testCases := []struct {
+		image string
+		ok    bool
+	}{
+		{"node", true},
+		{"node:18", true},
+		{"node:18.12-alpine3.16", true},
+		{"library/node:18", true},
+		{"ghcr.io/owner/image:1.0", true},
+		{"localhost:5000/my_image", true},
+		{"registry.example.com:5000/owner/sub/image-name:v1", true},
+		{"docker://node:18", true},
+		{"node@sha256:5f7fbd6c3fe2e9e4b5ad1d3b6a5b1d08a6b2c7e6c9d8f4a3b2e1d0c9b8a7f6e5", true},
+		{"node:18@sha256:5f7fbd6c3fe2e9e4b5ad1d3b6a5b1d08a6b2c7e6c9d8f4a3b2e1d0c9b8a7f6e5", true},
+		{"my__image:1", true},
+		{"my--image:1", true},
+		{"Node:18", false},
+		{"ghcr.io/Owner/image", false},
+		{"node:", false},
+		{"node:18:alpine", false},
+		{"node 18", false},
+		{"-node", false},
+		{"node/", false},
+		{"node@sha256:abc", false},
+		{"node@sha256:5f7fbd6c3fe2e9e4b5ad1d3b6a5b1d08a6b2c7e6c9d8f4a3b2e1d0c9b8a7f6", false},
+		{"node@sha256:5F7FBD6C3FE2E9E4B5AD1D3B6A5B1D08A6B2C7E6C9D8F4A3B2E1D0C9B8A7F6E5", false},
+		{"node@md5:5f7fbd6c3fe2e9e4b5ad1d3b6a5b1d08", false},
+		{"node@5f7fbd6c3fe2e9e4b5ad1d3b6a5b1d08a6b2c7e6c9d8f4a3b2e1d0c9b8a7f6e5", false},
+		{"node:.18", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.image, func(t *testing.T) {
+			src := "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    container:\n      image: '" + tc.image + "'\n    steps:\n      - run: echo\n"
+			w, errs := Parse([]byte(src))
+			if len(errs) > 0 {
+				t.Fatal(errs)
+			}
+
+			r := NewRuleContainerImage()
+			v := NewVisitor()
+			v.AddPass(r)
+			if err := v.Visit(w); err != nil {
+				t.Fatal(err)
+			}
+
+			errs = r.Errs()
+			if tc.ok {
+				if len(errs) > 0 {
+					t.Fatalf("unexpected errors: %v", errs)
+				}
+				return
+			}
+			if len(errs) != 1 {
+				t.Fatalf("wanted 1 error but got %d errors: %v", len(errs), errs)
+			}
+			want := ":6:14: image reference \"" + tc.image + "\" in \"container\" section is malformed"
+			if msg := errs[0].Error(); !strings.Contains(msg, want) {
+				t.Fatalf("error %q does not contain %q", msg, want)
+			}
+		})
+	}
+}
+
+func TestRuleContainerImageLatestTag(t *testing.T) {
+	testCases := []struct {
+		what string
+		job  string
+		want []string
+	}{
+		{
+			what: "latest tag in container",
+			job:  "container: node:latest",
+			want: []string{`:5:16: image "node:latest" in "container" section uses "latest" tag`},
+		},
+		{
+			what: "no tag in container",
+			job:  "container:\n      image: ghcr.io/owner/image",
+			want: []string{`:6:14: image "ghcr.io/owner/image" in "container" section has no tag so "latest" tag is used implicitly`},
+		},
+		{
+			what: "latest tag and no tag in services",
+			job:  "services:\n      redis:\n        image: redis\n      nginx:\n        image: nginx:latest",
+			want: []string{
+				`:7:16: image "redis" in "redis" service has no tag so "latest" tag is used implicitly`,
+				`:9:16: image "nginx:latest" in "nginx" service uses "latest" tag`,
+			},
+		},
+		{
+			what: "registry with port and no tag",
+			job:  "container: localhost:5000/image",
+			want: []string{`:5:16: image "localhost:5000/image" in "container" section has no tag`},
+		},
+		{
+			what: "version tag",
+			job:  "container: node:18\n    services:\n      redis:\n        image: redis:7",
+		},
+		{
+			what: "digest",
+			job:  "container: node@sha256:5f7fbd6c3fe2e9e4b5ad1d3b6a5b1d08a6b2c7e6c9d8f4a3b2e1d0c9b8a7f6e5",
+		},
+		{
+			what: "latest tag with digest",
+			job:  "container: node:latest@sha256:5f7fbd6c3fe2e9e4b5ad1d3b6a5b1d08a6b2c7e6c9d8f4a3b2e1d0c9b8a7f6e5",
+		},
+		{
+			what: "expression",
+			job:  "container: ${{ inputs.image }}",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			src := "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    " + tc.job + "\n    steps:\n      - run: echo\n"
+			w, errs := Parse([]byte(src))
+			if len(errs) > 0 {
+				t.Fatal(errs)
+			}
+
+			testOptionalCheck(t, "container-latest-tag", SeverityWarning, tc.want, func(cfg *Config) []*Error {
+				return visitWithRule(t, w, NewRuleContainerImage(), cfg)
+			})
+		})
+	}
+}
+
+func TestRuleContainerImageDigest(t *testing.T) {
+	testCases := []struct {
+		image string
+		want  string
+	}{
+		{"node@sha256:abc", `digest "sha256:abc" should have 64 hexadecimal characters after "sha256:" but it has 3 characters`},
+		{"node:18@sha256:5f7fbd6c3fe2e9e4b5ad1d3b6a5b1d08a6b2c7e6c9d8f4a3b2e1d0c9b8a7f6", `should have 64 hexadecimal characters after "sha256:" but it has 62 characters`},
+		{"node@sha256:5F7FBD6C3FE2E9E4B5AD1D3B6A5B1D08A6B2C7E6C9D8F4A3B2E1D0C9B8A7F6E5", `contains character 'F' which is not lowercase hexadecimal`},
+		{"node@md5:5f7fbd6c3fe2e9e4b5ad1d3b6a5b1d08", `algorithm "md5" of digest "md5:5f7fbd6c3fe2e9e4b5ad1d3b6a5b1d08" is not supported`},
+		{"node@latest", `digest "latest" should be in format "algorithm:hex"`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.image, func(t *testing.T) {
+			src := "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    container:\n      image: '" + tc.image + "'\n    steps:\n      - run: echo\n"
+			w, errs := Parse([]byte(src))
+			if len(errs) > 0 {
+				t.Fatal(errs)
+			}
+
+			r := NewRuleContainerImage()
+			v := NewVisitor()
+			v.AddPass(r)
+			if err := v.Visit(w); err != nil {
+				t.Fatal(err)
+			}
+
+			errs = r.Errs()
+			if len(errs) != 1 {
+				t.Fatalf("wanted 1 error but got %d errors: %v", len(errs), errs)
+			}
+			if msg := errs[0].Error(); !strings.Contains(msg, tc.want) {
+				t.Fatalf("error %q does not contain %q", msg, tc.want)
+			}
+			if errs[0].Severity != SeverityError {
+				t.Fatalf("severity should be error but got %s", errs[0].Severity)
+			}
+		})
+	}
+}
+
+func TestRuleContainerImagePinnedImages(t *testing.T) {
+	digest := "sha256:5f7fbd6c3fe2e9e4b5ad1d3b6a5b1d08a6b2c7e6c9d8f4a3b2e1d0c9b8a7f6e5"
+
+	testCases := []struct {
+		image string
+		want  []string
+	}{
+		{"node:18", []string{`image "node:18" in "container" section is not pinned to a digest. tag "18" is mutable. pin the image like "node:18@sha256:<digest>"`}},
+		{"ghcr.io/owner/image", []string{`tag "latest" is mutable`}},
+		{"docker://node:18", []string{`pin the image like "node:18@sha256:<digest>"`}},
+		{"node@" + digest, nil},
+		{"node:18@" + digest, nil},
+		{"${{ inputs.image }}", nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.image, func(t *testing.T) {
+			src := "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    container:\n      image: '" + tc.image + "'\n    steps:\n      - run: echo\n"
+			w, errs := Parse([]byte(src))
+			if len(errs) > 0 {
+				t.Fatal(errs)
+			}
+
+			testOptionalCheck(t, "pinned-images", SeverityWarning, tc.want, func(cfg *Config) []*Error {
+				return visitWithRule(t, w, NewRuleContainerImage(), cfg)
+			})
+		})
+	}
+}
@@ -1,6 +1,10 @@
 package actionlint
 
-import "regexp"
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
 
 var deprecatedCommandsPattern = regexp.MustCompile(`(?:::(save-state|set-output|set-env)\s+name=[a-zA-Z][a-zA-Z_-]*::\S+|::(add-path)::\S+)`)
 
@@ -11,45 +15,133 @@ var deprecatedCommandsPattern = regexp.MustCompile(`(?:::(save-state|set-output|
 // - https://github.blog/changelog/2022-10-11-github-actions-deprecating-save-state-and-set-output-commands/
 type RuleDeprecatedCommands struct {
 	RuleBase
+	lines []string
 }
 
-// NewRuleDeprecatedCommands creates a new RuleDeprecatedCommands instance.
-func NewRuleDeprecatedCommands() *RuleDeprecatedCommands {
+// NewRuleDeprecatedCommands creates a new RuleDeprecatedCommands instance. The src parameter is
+// the source of the workflow. It is used for locating the commands in scripts and for suggesting
+// fixes of the errors. When it is nil, errors are reported at "run:" and no fix is suggested.
+func NewRuleDeprecatedCommands(src []byte) *RuleDeprecatedCommands {
+	var lines []string
+	if src != nil {
+		lines = strings.Split(string(src), "\n")
+	}
 	return &RuleDeprecatedCommands{
 		RuleBase: RuleBase{name: "deprecated-commands"},
+		lines:    lines,
 	}
 }
 
 // VisitStep is callback when visiting Step node.
 func (rule *RuleDeprecatedCommands) VisitStep(n *Step) error {
 	if r, ok := n.Exec.(*ExecRun); ok && r.Run != nil {
-		for _, m := range deprecatedCommandsPattern.FindAllStringSubmatch(r.Run.Value, -1) {
-			c := m[1]
-			if len(c) == 0 {
-				c = m[2]
-			}
+		for i, line := range strings.Split(r.Run.Value, "\n") {
+			for _, m := range deprecatedCommandsPattern.FindAllStringSubmatch(line, -1) {
+				c := m[1]
+				if len(c) == 0 {
+					c = m[2]
+				}
 
-			var a string
-			switch c {
-			case "set-output":
-				a = `echo "{name}={value}" >> $GITHUB_OUTPUT`
-			case "save-state":
-				a = `echo "{name}={value}" >> $GITHUB_STATE`
-			case "set-env":
-				a = `echo "{name}={value}" >> $GITHUB_ENV`
-			case "add-path":
-				a = `echo "{path}" >> $GITHUB_PATH`
-			default:
-				panic("unreachable")
-			}
+				var a string
+				switch c {
+				case "set-output":
+					a = `echo "{name}={value}" >> $GITHUB_OUTPUT`
+				case "save-state":
+					a = `echo "{name}={value}" >> $GITHUB_STATE`
+				case "set-env":
+					a = `echo "{name}={value}" >> $GITHUB_ENV`
+				case "add-path":
+					a = `echo "{path}" >> $GITHUB_PATH`
+				default:
+					panic("unreachable")
+				}
 
-			rule.errorf(
-				r.Run.Pos,
-				"workflow command %q was deprecated. use `%s` instead: https://docs.github.com/en/actions/using-workflows/workflow-commands-for-github-actions",
-				c,
-				a,
-			)
+				pos := r.Run.linePos(rule.lines, i, line)
+				var fix *ErrorFix
+				if pos == nil {
+					pos = r.Run.Pos
+				} else if s := rewriteDeprecatedCommand(line); s != "" {
+					fix = &ErrorFix{
+						Line:        pos.Line,
+						Column:      pos.Col,
+						EndColumn:   pos.Col + len(strings.TrimSpace(line)),
+						Replacement: s,
+					}
+				}
+
+				rule.errorfWithFix(
+					pos,
+					fix,
+					"workflow command %q was deprecated. use `%s` instead: https://docs.github.com/en/actions/using-workflows/workflow-commands-for-github-actions",
+					c,
+					a,
+				)
+			}
 		}
 	}
 	return nil
 }
+
+var (
+	reDeprecatedEchoCommand = regexp.MustCompile(`^(echo|printf)\s+(.+)$`)
+	reDeprecatedNameCommand = regexp.MustCompile(`^::(save-state|set-output|set-env)\s+name=([a-zA-Z][a-zA-Z_-]*)::(.*)$`)
+	reDeprecatedPathCommand = regexp.MustCompile(`^::add-path::(.*)$`)
+)
+
+// rewriteDeprecatedCommand returns the command to replace the line which runs a deprecated workflow
+// command with `echo` or `printf`. The quotes around the argument are preserved. A dynamic value
+// following the quoted argument like `echo "::set-output name=foo::"$BAR` and arguments of `printf`
+// like `printf '::set-output name=foo::%s\n' "$BAR"` are also preserved. When the line cannot be
+// rewritten, this function returns an empty string.
+func rewriteDeprecatedCommand(line string) string {
+	m := reDeprecatedEchoCommand.FindStringSubmatch(strings.TrimSpace(line))
+	if m == nil {
+		return ""
+	}
+
+	cmd, arg := m[1], m[2]
+	q, rest := "", ""
+	if len(arg) >= 2 && (arg[0] == '"' || arg[0] == '\'') {
+		j := strings.IndexByte(arg[1:], arg[0]) + 1
+		if j == 0 || arg[j-1] == '\\' {
+			return "" // Quote is not closed or escaped quote is contained
+		}
+		q, rest = arg[:1], arg[j+1:]
+		arg = arg[1:j]
+		// For echo, the rest must be one word concatenated to the quoted argument like $BAR in
+		// echo "::set-output name=foo::"$BAR
+		if cmd == "echo" && strings.ContainsAny(rest, " \t") {
+			return ""
+		}
+	} else if cmd == "printf" {
+		return "" // Format string of printf is usually quoted
+	}
+	if strings.ContainsAny(arg, "\"'") {
+		return ""
+	}
+	// Value written by printf must end with newline. Otherwise the next value is concatenated
+	if cmd == "printf" && !strings.HasSuffix(arg, "\\n") {
+		return ""
+	}
+
+	if m := reDeprecatedNameCommand.FindStringSubmatch(arg); m != nil {
+		f := "GITHUB_OUTPUT"
+		switch m[1] {
+		case "save-state":
+			f = "GITHUB_STATE"
+		case "set-env":
+			f = "GITHUB_ENV"
+		}
+		return fmt.Sprintf(`%s %s%s=%s%s%s >> "$%s"`, cmd, q, m[2], m[3], q, rest, f)
+	}
+	if m := reDeprecatedPathCommand.FindStringSubmatch(arg); m != nil {
+		if m[1] == "" {
+			if rest == "" || cmd == "printf" {
+				return ""
+			}
+			return fmt.Sprintf(`echo %s >> "$GITHUB_PATH"`, rest) // echo "::add-path::"$DIR
+		}
+		return fmt.Sprintf(`%s %s%s%s%s >> "$GITHUB_PATH"`, cmd, q, m[1], q, rest)
+	}
+	return ""
+}
@@ -101,7 +101,7 @@ func TestRuleDeprecatedCommandsDetectTargetCommands(t *testing.T) {
 					},
 				},
 			}
-			r := NewRuleDeprecatedCommands()
+			r := NewRuleDeprecatedCommands(nil)
 			if err := r.VisitStep(s); err != nil {
 				t.Fatal(err)
 			}
@@ -123,3 +123,290 @@ func TestRuleDeprecatedCommandsDetectTargetCommands(t *testing.T) {
 		})
 	}
 }
+
+func TestRuleDeprecatedCommandsRewriteCommand(t *testing.T) {
+	tests := []struct {
+		what string
+		line string
+		want string
+	}{
+		{
+			what: "set-output with single quotes",
+			line: "echo '::set-output name=foo::bar'",
+			want: `echo 'foo=bar' >> "$GITHUB_OUTPUT"`,
+		},
+		{
+			what: "set-output with double quotes",
+			line: `echo "::set-output name=version::$(cat VERSION)"`,
+			want: `echo "version=$(cat VERSION)" >> "$GITHUB_OUTPUT"`,
+		},
+		{
+			what: "set-output without quotes",
+			line: `echo ::set-output name=dir::$(npm config get cache)`,
+			want: `echo dir=$(npm config get cache) >> "$GITHUB_OUTPUT"`,
+		},
+		{
+			what: "save-state",
+			line: `echo "::save-state name=pid::$PID"`,
+			want: `echo "pid=$PID" >> "$GITHUB_STATE"`,
+		},
+		{
+			what: "set-env",
+			line: `echo "::set-env name=FOO::hello world"`,
+			want: `echo "FOO=hello world" >> "$GITHUB_ENV"`,
+		},
+		{
+			what: "add-path",
+			line: `echo "::add-path::$HOME/.local/bin"`,
+			want: `echo "$HOME/.local/bin" >> "$GITHUB_PATH"`,
+		},
+		{
+			what: "indented command",
+			line: `    echo "::set-output name=foo::$BAR"`,
+			want: `echo "foo=$BAR" >> "$GITHUB_OUTPUT"`,
+		},
+		{
+			what: "dynamic value following quoted argument",
+			line: `echo "::set-output name=foo::"$BAR`,
+			want: `echo "foo="$BAR >> "$GITHUB_OUTPUT"`,
+		},
+		{
+			what: "quoted dynamic value following quoted argument",
+			line: `echo '::set-output name=foo::'"${BAR}-$(date)"`,
+			want: `echo 'foo='"${BAR}-$(date)" >> "$GITHUB_OUTPUT"`,
+		},
+		{
+			what: "dynamic path following quoted argument",
+			line: `echo "::add-path::"$DIR`,
+			want: `echo $DIR >> "$GITHUB_PATH"`,
+		},
+		{
+			what: "printf command",
+			line: `printf '::set-output name=foo::%s\n' "$BAR"`,
+			want: `printf 'foo=%s\n' "$BAR" >> "$GITHUB_OUTPUT"`,
+		},
+		{
+			what: "printf command without newline",
+			line: `printf '::set-output name=foo::%s' "$BAR"`,
+		},
+		{
+			what: "argument separated by space",
+			line: `echo "::set-output name=foo::" $BAR`,
+		},
+		{
+			what: "escaped quote in argument",
+			line: `echo "::set-output name=foo::\"bar\""`,
+		},
+		{
+			what: "multiple commands in line",
+			line: `echo "::set-output name=foo::1" && echo "::set-output name=bar::2"`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.what, func(t *testing.T) {
+			if have := rewriteDeprecatedCommand(tc.line); have != tc.want {
+				t.Fatalf("wanted %q but got %q", tc.want, have)
+			}
+		})
+	}
+}
+
+func TestRuleDeprecatedCommandsErrorPosition(t *testing.T) {
+	tests := []struct {
+		what string
+		src  string
+		want []Pos
+	}{
+		{
+			what: "plain scalar",
+			src: `on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo '::set-output name=foo::bar'
+`,
+			want: []Pos{{Line: 6, Col: 14}},
+		},
+		{
+			what: "literal block",
+			src: `on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: |
+          set -e
+            echo '::set-output name=foo::bar'
+          echo hello
+          echo "::add-path::/opt/bin"
+`,
+			want: []Pos{{Line: 8, Col: 13}, {Line: 10, Col: 11}},
+		},
+		{
+			what: "folded block",
+			src: `on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: >
+          echo hello
+          echo "::set-output name=foo::bar"
+`,
+			want: []Pos{{Line: 6, Col: 14}},
+		},
+		{
+			what: "quoted scalar",
+			src: `on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: "echo hello\necho ::set-output name=foo::bar"
+`,
+			want: []Pos{{Line: 6, Col: 14}},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.what, func(t *testing.T) {
+			w, errs := Parse([]byte(tc.src))
+			if len(errs) > 0 {
+				t.Fatal(errs)
+			}
+			r := NewRuleDeprecatedCommands([]byte(tc.src))
+			for _, s := range w.Jobs["test"].Steps {
+				if err := r.VisitStep(s); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			have := []Pos{}
+			for _, err := range r.Errs() {
+				have = append(have, Pos{Line: err.Line, Col: err.Column})
+			}
+			if !cmp.Equal(have, tc.want) {
+				t.Fatal(cmp.Diff(tc.want, have))
+			}
+		})
+	}
+}
+
+func TestRuleDeprecatedCommandsFix(t *testing.T) {
+	tests := []struct {
+		what string
+		src  string
+		want string
+	}{
+		{
+			what: "literal block",
+			src: `on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: |
+          if true; then
+            echo "::set-output name=foo::bar"
+          fi
+`,
+			want: `on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: |
+          if true; then
+            echo "foo=bar" >> "$GITHUB_OUTPUT"
+          fi
+`,
+		},
+		{
+			what: "plain scalar",
+			src: `on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo '::add-path::/opt/bin' # comment
+`,
+			want: `on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo '/opt/bin' >> "$GITHUB_PATH" # comment
+`,
+		},
+		{
+			what: "folded block",
+			src: `on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: >
+          echo "::set-output name=foo::bar"
+`,
+		},
+		{
+			what: "quoted scalar",
+			src: `on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: "echo ::set-output name=foo::bar"
+`,
+		},
+		{
+			what: "command cannot be rewritten",
+			src: `on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo "::set-output name=foo::" $BAR
+`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.what, func(t *testing.T) {
+			w, errs := Parse([]byte(tc.src))
+			if len(errs) > 0 {
+				t.Fatal(errs)
+			}
+			r := NewRuleDeprecatedCommands([]byte(tc.src))
+			for _, s := range w.Jobs["test"].Steps {
+				if err := r.VisitStep(s); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			errs = r.Errs()
+			if len(errs) != 1 {
+				t.Fatalf("wanted 1 error but got %d errors: %v", len(errs), errs)
+			}
+			fix := errs[0].Fix
+			if tc.want == "" {
+				if fix != nil {
+					t.Fatalf("wanted no fix but got %+v", fix)
+				}
+				return
+			}
+			if fix == nil {
+				t.Fatal("wanted fix but got nil")
+			}
+			have := fix.Apply([]byte(tc.src))
+			if have == nil {
+				t.Fatalf("fix could not be applied: %+v", fix)
+			}
+			if string(have) != tc.want {
+				t.Fatal(cmp.Diff(tc.want, string(have)))
+			}
+		})
+	}
+}
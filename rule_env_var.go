@@ -1,22 +1,38 @@
 package actionlint
 
-import "strings"
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
 
 // RuleEnvVar is a rule checker to check environment variables setup.
 type RuleEnvVar struct {
 	RuleBase
+	// Names of environment variables assigned from secrets per scope for "echo-secret" optional
+	// check. Keys are names in lower case and values are names in original case.
+	workflowSecretEnv map[string]string
+	jobSecretEnv      map[string]string
 }
 
 // NewRuleEnvVar creates new RuleEnvVar instance.
 func NewRuleEnvVar() *RuleEnvVar {
 	return &RuleEnvVar{
-		RuleBase: RuleBase{name: "env-var"},
+		RuleBase:          RuleBase{name: "env-var"},
+		workflowSecretEnv: nil,
+		jobSecretEnv:      nil,
 	}
 }
 
 // VisitStep is callback when visiting Step node.
 func (rule *RuleEnvVar) VisitStep(n *Step) error {
 	rule.checkEnv(n.Env)
+	if rule.isCheckEnabled("echo-secret") {
+		if r, ok := n.Exec.(*ExecRun); ok {
+			rule.checkEchoSecret(r, collectSecretEnv(rule.jobSecretEnv, n.Env))
+		}
+	}
 	return nil
 }
 
@@ -29,12 +45,30 @@ func (rule *RuleEnvVar) VisitJobPre(n *Job) error {
 	for _, s := range n.Services {
 		rule.checkEnv(s.Container.Env)
 	}
+	if rule.isCheckEnabled("echo-secret") {
+		rule.jobSecretEnv = collectSecretEnv(rule.workflowSecretEnv, n.Env)
+	}
+	return nil
+}
+
+// VisitJobPost is callback when visiting Job node after visiting its children.
+func (rule *RuleEnvVar) VisitJobPost(n *Job) error {
+	rule.jobSecretEnv = nil
 	return nil
 }
 
 // VisitWorkflowPre is callback when visiting Workflow node before visiting its children.
 func (rule *RuleEnvVar) VisitWorkflowPre(n *Workflow) error {
 	rule.checkEnv(n.Env)
+	if rule.isCheckEnabled("echo-secret") {
+		rule.workflowSecretEnv = collectSecretEnv(nil, n.Env)
+	}
+	return nil
+}
+
+// VisitWorkflowPost is callback when visiting Workflow node after visiting its children.
+func (rule *RuleEnvVar) VisitWorkflowPost(n *Workflow) error {
+	rule.workflowSecretEnv = nil
 	return nil
 }
 
@@ -49,6 +83,120 @@ func (rule *RuleEnvVar) checkEnv(env *Env) {
 				"environment variable name %q is invalid. '&', '=' and spaces should not be contained",
 				v.Name.Value,
 			)
+			continue
+		}
+		rule.checkEnvVarIdent(v.Name)
+	}
+}
+
+var reEnvVarIdent = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// checkEnvVarIdent checks the environment variable name is a valid identifier. Variables whose
+// names are not identifiers like "my-var" cannot be referred as $NAME in shell scripts. It is
+// reported as a warning since such variables are still available to programs run by the step.
+func (rule *RuleEnvVar) checkEnvVarIdent(name *String) {
+	if strings.Contains(name.Value, "${{") || reEnvVarIdent.MatchString(name.Value) {
+		return
+	}
+	note := ""
+	if s := strings.ReplaceAll(name.Value, "-", "_"); reEnvVarIdent.MatchString(s) {
+		note = fmt.Sprintf(" did you mean %q?", s)
+	}
+	rule.warnf(
+		name.Pos,
+		"environment variable name %q is not a valid identifier. it should match [A-Za-z_][A-Za-z0-9_]* to be referred in shell scripts.%s",
+		name.Value,
+		note,
+	)
+}
+
+var reSecretsInExpr = regexp.MustCompile(`\$\{\{[^}]*\bsecrets\s*[.\[]`)
+
+// collectSecretEnv returns a new map which merges names of environment variables in the parent
+// scope and the env section. Variables which are not assigned from secrets in the env section
+// shadow the same variables in the parent scope.
+func collectSecretEnv(parent map[string]string, env *Env) map[string]string {
+	ret := make(map[string]string, len(parent))
+	for k, v := range parent {
+		ret[k] = v
+	}
+	if env == nil || env.Vars == nil {
+		return ret
+	}
+	for k, v := range env.Vars {
+		if v.Value != nil && reSecretsInExpr.MatchString(v.Value.Value) {
+			ret[k] = v.Name.Value
+		} else {
+			delete(ret, k)
+		}
+	}
+	return ret
+}
+
+var (
+	reShellCommandSep = regexp.MustCompile(`;|&&|\|\|`)
+	reEchoCommand     = regexp.MustCompile(`^(echo|printf)\s`)
+)
+
+// checkEchoSecret checks `echo` or `printf` commands in the script which directly print
+// environment variables assigned from secrets. This is an optional check enabled by "echo-secret".
+func (rule *RuleEnvVar) checkEchoSecret(exec *ExecRun, secrets map[string]string) {
+	if exec.Run == nil || len(secrets) == 0 {
+		return
+	}
+
+	names := make([]string, 0, len(secrets))
+	for _, n := range secrets {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	for i, line := range strings.Split(exec.Run.Value, "\n") {
+		for _, cmd := range reShellCommandSep.Split(line, -1) {
+			cmd = strings.TrimSpace(cmd)
+			m := reEchoCommand.FindStringSubmatch(cmd)
+			if m == nil {
+				continue
+			}
+			// Output is not printed to logs when it is piped or redirected. Masking the value
+			// with ::add-mask:: is intended.
+			if strings.ContainsAny(cmd, "|>") || strings.Contains(cmd, "::add-mask::") {
+				continue
+			}
+			for _, name := range names {
+				if !containsShellVarRef(cmd, name) {
+					continue
+				}
+				rule.warnf(
+					exec.Run.Pos,
+					"environment variable %q assigned from secrets is printed by %q command at line %d of the script. it may leak the secret to logs",
+					name,
+					m[1],
+					i+1,
+				)
+			}
+		}
+	}
+}
+
+// containsShellVarRef returns if the command refers the shell variable as $NAME or ${NAME}.
+func containsShellVarRef(cmd, name string) bool {
+	if strings.Contains(cmd, "${"+name+"}") {
+		return true
+	}
+	v := "$" + name
+	for {
+		i := strings.Index(cmd, v)
+		if i < 0 {
+			return false
+		}
+		cmd = cmd[i+len(v):]
+		if len(cmd) == 0 {
+			return true
+		}
+		c := cmd[0]
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '_') {
+			return true
 		}
 	}
 }
@@ -0,0 +1,282 @@
+package actionlint
+
+import (
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestRuleEnvVarEchoSecret(t *testing.T) {
+	testCases := []struct {
+		what  string
+		input string
+		want  []string
+	}{
+		{
+			what: "echo secret at step env",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: |
+          echo 'deploying'
+          echo "$DEPLOY_KEY"
+        env:
+          DEPLOY_KEY: ${{ secrets.DEPLOY_KEY }}`,
+			want: []string{`:6:14: environment variable "DEPLOY_KEY" assigned from secrets is printed by "echo" command at line 2 of the script`},
+		},
+		{
+			what: "echo secret with braces",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo "key is ${DEPLOY_KEY}"
+        env:
+          DEPLOY_KEY: ${{ secrets.DEPLOY_KEY }}`,
+			want: []string{`environment variable "DEPLOY_KEY" assigned from secrets is printed by "echo" command at line 1 of the script`},
+		},
+		{
+			what: "printf secret at job env",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    env:
+      TOKEN: ${{ secrets['TOKEN'] }}
+    steps:
+      - run: printf '%s\n' $TOKEN`,
+			want: []string{`environment variable "TOKEN" assigned from secrets is printed by "printf" command`},
+		},
+		{
+			what: "echo secret at workflow env after other command",
+			input: `
+env:
+  TOKEN: ${{ secrets.TOKEN }}
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: cd dir && echo $TOKEN`,
+			want: []string{`environment variable "TOKEN" assigned from secrets is printed by "echo" command`},
+		},
+		{
+			what: "multiple secrets",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo $FOO $BAR
+        env:
+          FOO: ${{ secrets.FOO }}
+          BAR: ${{ secrets.BAR }}`,
+			want: []string{
+				`environment variable "BAR" assigned from secrets`,
+				`environment variable "FOO" assigned from secrets`,
+			},
+		},
+		{
+			what: "secret is piped",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo "$TOKEN" | docker login --password-stdin
+        env:
+          TOKEN: ${{ secrets.TOKEN }}`,
+		},
+		{
+			what: "secret is redirected",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo "$TOKEN" > token.txt
+        env:
+          TOKEN: ${{ secrets.TOKEN }}`,
+		},
+		{
+			what: "secret is masked",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo "::add-mask::$TOKEN"
+        env:
+          TOKEN: ${{ secrets.TOKEN }}`,
+		},
+		{
+			what: "other variable with the same prefix",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo "$TOKEN_NAME"
+        env:
+          TOKEN: ${{ secrets.TOKEN }}`,
+		},
+		{
+			what: "secret is used by other command",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: ./deploy.sh "$TOKEN"
+        env:
+          TOKEN: ${{ secrets.TOKEN }}`,
+		},
+		{
+			what: "env var not from secrets",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo "$NAME"
+        env:
+          NAME: ${{ github.actor }}`,
+		},
+		{
+			what: "secret env var is shadowed",
+			input: `
+env:
+  TOKEN: ${{ secrets.TOKEN }}
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    env:
+      TOKEN: dummy
+    steps:
+      - run: echo "$TOKEN"`,
+		},
+		{
+			what: "secret env var at other step",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: ./deploy.sh
+        env:
+          TOKEN: ${{ secrets.TOKEN }}
+      - run: echo "$TOKEN"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			w, errs := Parse([]byte("on: push" + tc.input + "\n"))
+			if len(errs) > 0 {
+				t.Fatal(errs)
+			}
+
+			testOptionalCheck(t, "echo-secret", SeverityWarning, tc.want, func(cfg *Config) []*Error {
+				return visitWithRule(t, w, NewRuleEnvVar(), cfg)
+			})
+		})
+	}
+}
+
+func TestRuleEnvVarInvalidIdentifier(t *testing.T) {
+	testCases := []struct {
+		what  string
+		input string
+		want  []string
+	}{
+		{
+			what: "dashes in workflow env",
+			input: `
+env:
+  my-var: foo
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo`,
+			want: []string{`:3:3: environment variable name "my-var" is not a valid identifier. it should match [A-Za-z_][A-Za-z0-9_]* to be referred in shell scripts. did you mean "my_var"?`},
+		},
+		{
+			what: "leading digit in job env",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    env:
+      1foo: foo
+    steps:
+      - run: echo`,
+			want: []string{`:6:7: environment variable name "1foo" is not a valid identifier. it should match [A-Za-z_][A-Za-z0-9_]* to be referred in shell scripts. [env-var]`},
+		},
+		{
+			what: "dashes in step env",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo
+        env:
+          MY-VAR: foo
+          1-foo: bar`,
+			want: []string{
+				`:8:11: environment variable name "MY-VAR" is not a valid identifier`,
+				`:9:11: environment variable name "1-foo" is not a valid identifier. it should match [A-Za-z_][A-Za-z0-9_]* to be referred in shell scripts. [env-var]`,
+			},
+		},
+		{
+			what: "valid names",
+			input: `
+env:
+  FOO: foo
+  _foo_123: foo
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    env:
+      Foo_Bar: foo
+    steps:
+      - run: echo
+        env:
+          _: foo`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			w, errs := Parse([]byte("on: push" + tc.input + "\n"))
+			if len(errs) > 0 {
+				t.Fatal(errs)
+			}
+
+			r := NewRuleEnvVar()
+			r.SetConfig(&Config{})
+			v := NewVisitor()
+			v.AddPass(r)
+			if err := v.Visit(w); err != nil {
+				t.Fatal(err)
+			}
+
+			errs = r.Errs()
+			if len(errs) != len(tc.want) {
+				t.Fatalf("wanted %d errors but got %d errors: %v", len(tc.want), len(errs), errs)
+			}
+			sort.Slice(errs, func(i, j int) bool { return errs[i].Line < errs[j].Line })
+			for i, err := range errs {
+				if !strings.Contains(err.Error(), tc.want[i]) {
+					t.Errorf("error %q does not contain %q", err.Error(), tc.want[i])
+				}
+				if err.Severity != SeverityWarning {
+					t.Errorf("severity of error should be warning but got %s: %s", err.Severity, err)
+				}
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package actionlint
 
 import (
+	"fmt"
 	"strconv"
 	"strings"
 	"time"
@@ -28,9 +29,34 @@ func (rule *RuleEvents) VisitWorkflowPre(n *Workflow) error {
 	for _, e := range n.On {
 		rule.checkEvent(e)
 	}
+	if rule.isCheckEnabled("concurrency-cancel-in-progress") {
+		rule.checkConcurrencyCancelInProgress(n)
+	}
 	return nil
 }
 
+// checkConcurrencyCancelInProgress reports the workflow-level concurrency group without
+// "cancel-in-progress" in the workflow triggered by "push" or "pull_request" event. In the case,
+// a new run is queued until the running one in the same group finishes instead of canceling it.
+// https://docs.github.com/en/actions/using-jobs/using-concurrency
+func (rule *RuleEvents) checkConcurrencyCancelInProgress(n *Workflow) {
+	c := n.Concurrency
+	if c == nil || c.Group == nil || c.CancelInProgress != nil {
+		return
+	}
+	for _, e := range n.On {
+		if e, ok := e.(*WebhookEvent); ok && (e.Hook.Value == "push" || e.Hook.Value == "pull_request") {
+			rule.infof(
+				c.Pos,
+				"concurrency group %q has no \"cancel-in-progress\" in the workflow triggered by %q event. a new run waits until the running one in the same group finishes instead of canceling it. set \"cancel-in-progress\" explicitly to make the intention clear",
+				c.Group.Value,
+				e.Hook.Value,
+			)
+			return
+		}
+	}
+}
+
 func (rule *RuleEvents) checkEvent(event Event) {
 	switch e := event.(type) {
 	case *ScheduledEvent:
@@ -151,6 +177,34 @@ func (rule *RuleEvents) checkWebhookEvent(event *WebhookEvent) {
 		hook,
 		[]string{"push"},
 	)
+
+	rule.checkRefPrefixInFilter(event.Branches, "refs/heads/")
+	rule.checkRefPrefixInFilter(event.BranchesIgnore, "refs/heads/")
+	rule.checkRefPrefixInFilter(event.Tags, "refs/tags/")
+	rule.checkRefPrefixInFilter(event.TagsIgnore, "refs/tags/")
+}
+
+// checkRefPrefixInFilter checks patterns in branches and tags filters which start with the full
+// ref prefix like "refs/heads/main". These filters are matched against branch or tag names so such
+// patterns never match.
+func (rule *RuleEvents) checkRefPrefixInFilter(filter *WebhookEventFilter, prefix string) {
+	if filter.IsEmpty() {
+		return
+	}
+	for _, v := range filter.Values {
+		p := strings.TrimPrefix(v.Value, "!")
+		if !strings.HasPrefix(p, prefix) {
+			continue
+		}
+		rule.errorf(
+			v.Pos,
+			"pattern %q in %q filter starts with %q. the filter matches names without the prefix so this pattern never matches. use %q instead",
+			v.Value,
+			filter.Name.Value,
+			prefix,
+			v.Value[:len(v.Value)-len(p)]+strings.TrimPrefix(p, prefix),
+		)
+	}
 }
 
 func (rule *RuleEvents) checkTypes(hook *String, types []*String, expected []string) {
@@ -168,11 +222,16 @@ func (rule *RuleEvents) checkTypes(hook *String, types []*String, expected []str
 			}
 		}
 		if !valid {
+			msg := ""
+			if similar := findSimilarStrings(ty.Value, expected); len(similar) > 0 {
+				msg = fmt.Sprintf(" did you mean %s?", quotes(similar))
+			}
 			rule.errorf(
 				ty.Pos,
-				"invalid activity type %q for %q Webhook event. available types are %s",
+				"invalid activity type %q for %q Webhook event.%s available types are %s",
 				ty.Value,
 				hook.Value,
+				msg,
 				sortedQuotes(expected),
 			)
 		}
@@ -257,6 +316,12 @@ func (rule *RuleEvents) checkWorkflowDispatchEvent(event *WorkflowDispatchEvent)
 						rule.errorf(i.Default.Pos, "type of %q input is \"boolean\". its default value %q must be \"true\" or \"false\"", n, i.Default.Value)
 					}
 				}
+			case WorkflowDispatchEventInputTypeNumber:
+				if i.Default != nil {
+					if _, err := strconv.ParseFloat(i.Default.Value, 64); err != nil {
+						rule.errorf(i.Default.Pos, "type of %q input is \"number\". its default value %q cannot be parsed as a float number: %s", n, i.Default.Value, err)
+					}
+				}
 			default:
 				// TODO: Can some check be done for WorkflowDispatchEventInputTypeEnvironment?
 				// What is suitable for default value of the type? (Or is a default value never suitable?)
@@ -0,0 +1,63 @@
+package actionlint
+
+import "testing"
+
+func TestRuleEventsConcurrencyCancelInProgress(t *testing.T) {
+	testCases := []struct {
+		what        string
+		on          string
+		concurrency string
+		want        []string
+	}{
+		{
+			what:        "string form on push",
+			on:          "push",
+			concurrency: "concurrency: ci-${{ github.ref }}",
+			want:        []string{`:2:1: concurrency group "ci-${{ github.ref }}" has no "cancel-in-progress" in the workflow triggered by "push" event`},
+		},
+		{
+			what:        "object form on pull_request",
+			on:          "[workflow_dispatch, pull_request]",
+			concurrency: "concurrency:\n  group: ci",
+			want:        []string{`:2:1: concurrency group "ci" has no "cancel-in-progress" in the workflow triggered by "pull_request" event`},
+		},
+		{
+			what:        "cancel-in-progress is true",
+			on:          "push",
+			concurrency: "concurrency:\n  group: ci\n  cancel-in-progress: true",
+		},
+		{
+			what:        "cancel-in-progress is false",
+			on:          "push",
+			concurrency: "concurrency:\n  group: ci\n  cancel-in-progress: false",
+		},
+		{
+			what:        "cancel-in-progress is expression",
+			on:          "push",
+			concurrency: "concurrency:\n  group: ci\n  cancel-in-progress: ${{ github.ref != 'refs/heads/main' }}",
+		},
+		{
+			what:        "other events",
+			on:          "[release, workflow_dispatch]",
+			concurrency: "concurrency: deploy",
+		},
+		{
+			what: "no concurrency",
+			on:   "push",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			src := "on: " + tc.on + "\n" + tc.concurrency + "\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo\n"
+			w, errs := Parse([]byte(src))
+			if len(errs) > 0 {
+				t.Fatal(errs)
+			}
+
+			testOptionalCheck(t, "concurrency-cancel-in-progress", SeverityInfo, tc.want, func(cfg *Config) []*Error {
+				return visitWithRule(t, w, NewRuleEvents(), cfg)
+			})
+		})
+	}
+}
@@ -2,6 +2,8 @@ package actionlint
 
 import (
 	"fmt"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -35,32 +37,86 @@ type RuleExpression struct {
 	workflow         *Workflow
 	localActions     *LocalActionsCache
 	localWorkflows   *LocalReusableWorkflowCache
+	exprCache        *exprCache
+	// Sets of environment variable names (in lower case) for "undefined-env" optional check. nil
+	// means the names cannot be known statically. For example, env is set with ${{ }} expression.
+	workflowEnv map[string]struct{}
+	jobEnv      map[string]struct{}
+	stepEnv     map[string]struct{}
+	// Map from step IDs to sets of output names set by the steps for "undefined-step-output"
+	// optional check. Both are in lower case. nil set means the output names cannot be known
+	// statically. For example, the step runs an action.
+	stepOutputs map[string]map[string]struct{}
+	// Map from IDs of steps which are not run yet in the current job to the steps. IDs are in lower
+	// case. They are used to report steps referenced before they run.
+	laterSteps map[string]*Step
+	// Steps which set their outputs in the current job and map from step IDs to sets of output
+	// names referred in the job for "unused-step-output" optional check. Both are in lower case. nil
+	// set means all outputs of the step are referred. nil map means all outputs of all steps may be
+	// referred. For example, the whole "steps" object is passed to toJSON().
+	outputSteps     []*Step
+	usedStepOutputs map[string]map[string]struct{}
+	// First step which sets "continue-on-error: true" in the current job
+	continueOnErrorStep *Step
+	// Node of "pull_request" event when the workflow is triggered by it. Secrets are not passed to
+	// the workflow when it is triggered by a pull request from a fork.
+	pullRequestEvent *WebhookEvent
+	// Search tree of untrusted inputs configured by "untrusted-inputs" configuration. nil means the
+	// built-in untrusted inputs are used.
+	untrustedInputs UntrustedInputSearchRoots
+	// Sets of names of inputs and secrets (in lower case) referred in the workflow for
+	// "unused-workflow-call-input" optional check. nil set means all of them may be referred. For
+	// example, the whole "inputs" object is passed to toJSON().
+	usedInputs  map[string]struct{}
+	usedSecrets map[string]struct{}
+	// Rules whose VisitExpr methods are called with all expressions checked by this rule
+	exprVisitors []Rule
 }
 
 // NewRuleExpression creates new RuleExpression instance.
 func NewRuleExpression(actionsCache *LocalActionsCache, workflowCache *LocalReusableWorkflowCache) *RuleExpression {
 	return &RuleExpression{
-		RuleBase:         RuleBase{name: "expression"},
-		matrixTy:         nil,
-		stepsTy:          nil,
-		needsTy:          nil,
-		secretsTy:        nil,
-		inputsTy:         nil,
-		dispatchInputsTy: nil,
-		jobsTy:           nil,
-		workflow:         nil,
-		localActions:     actionsCache,
-		localWorkflows:   workflowCache,
+		RuleBase:            RuleBase{name: "expression"},
+		matrixTy:            nil,
+		stepsTy:             nil,
+		needsTy:             nil,
+		secretsTy:           nil,
+		inputsTy:            nil,
+		dispatchInputsTy:    nil,
+		jobsTy:              nil,
+		workflow:            nil,
+		localActions:        actionsCache,
+		localWorkflows:      workflowCache,
+		exprCache:           nil,
+		workflowEnv:         nil,
+		jobEnv:              nil,
+		stepEnv:             nil,
+		stepOutputs:         nil,
+		continueOnErrorStep: nil,
+		pullRequestEvent:    nil,
+		untrustedInputs:     nil,
+		usedInputs:          nil,
+		usedSecrets:         nil,
 	}
 }
 
 // VisitWorkflowPre is callback when visiting Workflow node before visiting its children.
 func (rule *RuleExpression) VisitWorkflowPre(n *Workflow) error {
+	if cfg := rule.Config(); cfg != nil && rule.untrustedInputs == nil {
+		rule.untrustedInputs = cfg.untrustedInputs()
+	}
+	if _, ok := n.FindWorkflowCallEvent(); ok && rule.isCheckEnabled("unused-workflow-call-input") {
+		rule.usedInputs = map[string]struct{}{}
+		rule.usedSecrets = map[string]struct{}{}
+	}
 	rule.checkString(n.Name, "")
 
 	for _, e := range n.On {
 		switch e := e.(type) {
 		case *WebhookEvent:
+			if e.Hook.Value == "pull_request" {
+				rule.pullRequestEvent = e
+			}
 			rule.checkStrings(e.Types, "")
 			rule.checkWebhookEventFilter(e.Branches)
 			rule.checkWebhookEventFilter(e.BranchesIgnore)
@@ -83,6 +139,8 @@ func (rule *RuleExpression) VisitWorkflowPre(n *Workflow) error {
 				switch i.Type {
 				case WorkflowDispatchEventInputTypeBoolean:
 					ty = BoolType{}
+				case WorkflowDispatchEventInputTypeNumber:
+					ty = NumberType{}
 				case WorkflowDispatchEventInputTypeString, WorkflowDispatchEventInputTypeChoice, WorkflowDispatchEventInputTypeEnvironment:
 					ty = StringType{}
 				default:
@@ -169,6 +227,10 @@ func (rule *RuleExpression) VisitWorkflowPre(n *Workflow) error {
 	rule.checkDefaults(n.Defaults, "")
 	rule.checkConcurrency(n.Concurrency, "concurrency")
 
+	if rule.isCheckEnabled("undefined-env") {
+		rule.workflowEnv = addEnvVarNames(map[string]struct{}{}, n.Env)
+	}
+
 	rule.workflow = n
 	return nil
 }
@@ -177,8 +239,13 @@ func (rule *RuleExpression) VisitWorkflowPre(n *Workflow) error {
 func (rule *RuleExpression) VisitWorkflowPost(n *Workflow) error {
 	if e, ok := n.FindWorkflowCallEvent(); ok {
 		rule.checkWorkflowCallOutputs(e.Outputs, n.Jobs)
+		rule.checkUnusedWorkflowCallInputs(e)
 	}
 	rule.workflow = nil
+	rule.usedInputs = nil
+	rule.usedSecrets = nil
+	rule.workflowEnv = nil
+	rule.pullRequestEvent = nil
 	return nil
 }
 
@@ -234,7 +301,7 @@ func (rule *RuleExpression) VisitJobPre(n *Job) error {
 					rule.checkRawYAMLValue(v)
 				}
 			}
-			rule.checkMatrixCombinations(n.Strategy.Matrix.Include, "include")
+			// Note: 'include' section is checked while guessing type of matrix in VisitJobPre()
 			rule.checkMatrixCombinations(n.Strategy.Matrix.Exclude, "exclude")
 		}
 		rule.checkBool(n.Strategy.FailFast, "jobs.<job_id>.strategy")
@@ -252,6 +319,28 @@ func (rule *RuleExpression) VisitJobPre(n *Job) error {
 	rule.checkWorkflowCall(n.WorkflowCall)
 
 	rule.stepsTy = NewEmptyStrictObjectType()
+	rule.laterSteps = map[string]*Step{}
+	for _, s := range n.Steps {
+		if s.ID == nil || strings.Contains(s.ID.Value, "${{") {
+			continue
+		}
+		id := strings.ToLower(s.ID.Value)
+		if _, ok := rule.laterSteps[id]; !ok {
+			rule.laterSteps[id] = s
+		}
+	}
+
+	if rule.workflowEnv != nil {
+		rule.jobEnv = addEnvVarNames(copyEnvVarNames(rule.workflowEnv), n.Env)
+	}
+
+	if rule.isCheckEnabled("undefined-step-output") {
+		rule.stepOutputs = map[string]map[string]struct{}{}
+	}
+
+	if rule.isCheckEnabled("unused-step-output") {
+		rule.usedStepOutputs = map[string]map[string]struct{}{}
+	}
 
 	return nil
 }
@@ -267,15 +356,27 @@ func (rule *RuleExpression) VisitJobPost(n *Job) error {
 		rule.checkString(output.Value, "jobs.<job_id>.outputs.<output_id>")
 	}
 
+	rule.checkUnusedStepOutputs()
+
 	rule.matrixTy = nil
 	rule.stepsTy = nil
 	rule.needsTy = nil
+	rule.jobEnv = nil
+	rule.stepOutputs = nil
+	rule.laterSteps = nil
+	rule.outputSteps = nil
+	rule.usedStepOutputs = nil
+	rule.continueOnErrorStep = nil
 
 	return nil
 }
 
 // VisitStep is callback when visiting Step node.
 func (rule *RuleExpression) VisitStep(n *Step) error {
+	if rule.jobEnv != nil {
+		rule.stepEnv = addEnvVarNames(copyEnvVarNames(rule.jobEnv), n.Env)
+	}
+
 	rule.checkString(n.Name, "jobs.<job_id>.steps.name")
 	rule.checkIfCondition(n.If, "jobs.<job_id>.steps.if")
 
@@ -309,12 +410,31 @@ func (rule *RuleExpression) VisitStep(n *Step) error {
 		if strings.Contains(id, "${{") && strings.Contains(id, "}}") {
 			rule.checkString(n.ID, "")
 			rule.stepsTy.Loose()
+			rule.stepOutputs = nil
+			rule.usedStepOutputs = nil
 		}
 		rule.stepsTy.Props[id] = NewStrictObjectType(map[string]ExprType{
 			"outputs":    rule.getActionOutputsType(spec),
 			"conclusion": StringType{},
 			"outcome":    StringType{},
 		})
+		if rule.stepOutputs != nil {
+			rule.stepOutputs[id] = stepOutputNames(n.Exec)
+		}
+		delete(rule.laterSteps, id)
+		if rule.usedStepOutputs != nil && len(stepOutputNames(n.Exec)) > 0 {
+			rule.outputSteps = append(rule.outputSteps, n)
+		}
+	}
+
+	rule.stepEnv = nil
+	if rule.jobEnv != nil {
+		rule.jobEnv = addExportedEnvVarNames(rule.jobEnv, n.Exec)
+	}
+
+	rule.checkFailureAfterContinueOnError(n)
+	if rule.continueOnErrorStep == nil && n.ContinueOnError != nil && n.ContinueOnError.Expression == nil && n.ContinueOnError.Value {
+		rule.continueOnErrorStep = n
 	}
 
 	return nil
@@ -540,6 +660,9 @@ func (rule *RuleExpression) checkWorkflowCall(c *WorkflowCall) {
 	}
 
 	rule.checkString(c.Uses, "")
+	if c.InheritSecrets {
+		rule.usedSecrets = nil // All secrets are passed to the called workflow
+	}
 
 	m, err := rule.localWorkflows.FindMetadata(c.Uses.Value)
 	if err != nil {
@@ -643,25 +766,42 @@ func (rule *RuleExpression) checkIfCondition(str *String, workflowKey string) {
 	if strings.Contains(str.Value, "${{") && strings.Contains(str.Value, "}}") {
 		ts := rule.checkString(str, workflowKey)
 
-		if len(ts) == 1 {
-			if isExprAssigned(str) {
-				condTy = ts[0].ty
+		if len(ts) == 1 && isExprAssigned(str) {
+			condTy = ts[0].ty
+			v := strings.TrimSpace(str.Value)
+			if expr, _, err := rule.exprCache.parse(v[len("${{"):len(v)-len("}}")] + "}}"); err == nil {
+				rule.checkEnvAsCondition(expr, str)
+				rule.checkStatusFuncCombination(expr, str)
 			}
+		} else if len(ts) > 0 {
+			// When other characters are around ${{ }}, the condition is evaluated as a string after
+			// placeholders are replaced. Since the string is not empty, the condition is always true.
+			// For example, `if: ${{ false }} && true` is evaluated as string "false && true".
+			rule.errorf(
+				str.Pos,
+				"\"if\" condition %q is always evaluated to true because characters other than one ${{ }} placeholder are contained. the condition is evaluated as a non-empty string after the placeholders are replaced. remove ${{ }} or wrap the whole condition with one ${{ }}",
+				str.Value,
+			)
 		}
 	} else {
 		src := str.Value + "}}" // }} is necessary since lexer lexes it as end of tokens
 		line, col := str.Pos.Line, str.Pos.Col
 
-		p := NewExprParser()
-		expr, err := p.Parse(NewExprLexer(src))
+		expr, _, err := rule.exprCache.parse(src)
 		if err != nil {
 			rule.exprError(err, line, col)
 			return
 		}
+		rule.checkNotOpPrecedence(expr, src, line, col)
+		rule.checkUndefinedEnv(expr, src, line, col)
+		rule.checkEventNameComparison(expr, line, col)
+		rule.checkFromJSONToJSONRoundTrip(expr, line, col)
 
 		if ty, ok := rule.checkSemanticsOfExprNode(expr, line, col, false, workflowKey); ok {
 			condTy = ty
 		}
+		rule.checkEnvAsCondition(expr, str)
+		rule.checkStatusFuncCombination(expr, str)
 	}
 
 	if condTy != nil && !(BoolType{}).Assignable(condTy) {
@@ -669,6 +809,34 @@ func (rule *RuleExpression) checkIfCondition(str *String, workflowKey string) {
 	}
 }
 
+// checkEnvAsCondition checks environment variables in `env` context are not used as boolean
+// conditions directly like `if: env.DEBUG`. Environment variables are always strings so the
+// condition is true whenever the variable is set to non-empty string including "false" and "0".
+// Operands of !, && and || operators are also checked since they are evaluated as booleans.
+func (rule *RuleExpression) checkEnvAsCondition(expr ExprNode, cond *String) {
+	switch n := expr.(type) {
+	case *NotOpNode:
+		rule.checkEnvAsCondition(n.Operand, cond)
+	case *LogicalOpNode:
+		rule.checkEnvAsCondition(n.Left, cond)
+		rule.checkEnvAsCondition(n.Right, cond)
+	case *ObjectDerefNode, *IndexAccessNode:
+		var recv ExprNode
+		if d, ok := n.(*ObjectDerefNode); ok {
+			recv = d.Receiver
+		} else {
+			recv = n.(*IndexAccessNode).Operand
+		}
+		if v, ok := recv.(*VariableNode); ok && strings.EqualFold(v.Name, "env") {
+			rule.warnf(
+				cond.Pos,
+				"\"if\" condition %q uses environment variable in \"env\" context as a boolean. environment variables are strings so it is true whenever the variable is set to a non-empty value even if the value is \"false\" or \"0\". compare the value explicitly like \"env.FOO == 'true'\"",
+				cond.Value,
+			)
+		}
+	}
+}
+
 func (rule *RuleExpression) checkTemplateEvaluatedType(ts []typedExpr) {
 	for _, t := range ts {
 		switch t.ty.(type) {
@@ -796,12 +964,34 @@ func (rule *RuleExpression) exprError(err *ExprError, lineBase, colBase int) {
 }
 
 func (rule *RuleExpression) checkSemanticsOfExprNode(expr ExprNode, line, col int, checkUntrusted bool, workflowKey string) (ExprType, bool) {
+	if len(rule.exprVisitors) > 0 {
+		pos := &Pos{Line: line, Col: col}
+		for _, r := range rule.exprVisitors {
+			r.VisitExpr(expr, pos)
+		}
+	}
+
 	c := NewExprSemanticsChecker(checkUntrusted)
+	if checkUntrusted && rule.untrustedInputs != nil {
+		c.SetUntrustedInputs(rule.untrustedInputs)
+	}
 	if rule.matrixTy != nil {
 		c.UpdateMatrix(rule.matrixTy)
 	}
 	if rule.stepsTy != nil {
-		c.UpdateSteps(rule.stepsTy)
+		ty := rule.stepsTy
+		if ids := rule.checkStepReferencedBeforeRun(expr, line, col); len(ids) > 0 {
+			// Add the steps to the type not to report them as undefined steps again
+			ty = rule.stepsTy.DeepCopy().(*ObjectType)
+			for _, id := range ids {
+				ty.Props[id] = NewStrictObjectType(map[string]ExprType{
+					"outputs":    NewMapObjectType(StringType{}),
+					"conclusion": StringType{},
+					"outcome":    StringType{},
+				})
+			}
+		}
+		c.UpdateSteps(ty)
 	}
 	if rule.needsTy != nil {
 		c.UpdateNeeds(rule.needsTy)
@@ -826,25 +1016,889 @@ func (rule *RuleExpression) checkSemanticsOfExprNode(expr ExprNode, line, col in
 		c.SetContextAvailability(ctx)
 		c.SetSpecialFunctionAvailability(sp)
 	}
+	if rule.isCheckEnabled("mixed-type-comparison") {
+		c.EnableMixedTypeComparisonCheck()
+	}
 
 	ty, errs := c.Check(expr)
 	for _, err := range errs {
 		rule.exprError(err, line, col)
 	}
+	for _, err := range c.MixedTypeComparisons() {
+		pos := convertExprLineColToPos(err.Line, err.Column, line, col)
+		rule.warnf(pos, "%s", err.Message)
+	}
+	for _, err := range c.LiteralComparisons() {
+		pos := convertExprLineColToPos(err.Line, err.Column, line, col)
+		rule.infof(pos, "%s", err.Message)
+	}
+
+	rule.collectUsedStepOutputs(expr)
+	rule.collectUsedInputsAndSecrets(expr)
 
 	return ty, len(errs) == 0
 }
 
 func (rule *RuleExpression) checkSemantics(src string, line, col int, checkUntrusted bool, workflowKey string) (ExprType, int, bool) {
-	l := NewExprLexer(src)
-	p := NewExprParser()
-	expr, err := p.Parse(l)
+	expr, offset, err := rule.exprCache.parse(src)
 	if err != nil {
 		rule.exprError(err, line, col)
-		return nil, l.Offset(), false
+		return nil, offset, false
+	}
+	rule.checkNotOpPrecedence(expr, src, line, col)
+	rule.checkUndefinedEnv(expr, src, line, col)
+	rule.checkEventNameComparison(expr, line, col)
+	rule.checkFromJSONToJSONRoundTrip(expr, line, col)
+	if rule.isCheckEnabled("secrets-in-pull-request") {
+		rule.checkSecretsInPullRequest(expr, line, col)
+	}
+	if workflowKey == "jobs.<job_id>.outputs.<output_id>" {
+		rule.checkUndefinedStepOutput(expr, line, col)
 	}
 	t, ok := rule.checkSemanticsOfExprNode(expr, line, col, checkUntrusted, workflowKey)
-	return t, l.Offset(), ok
+	return t, offset, ok
+}
+
+// checkStepReferencedBeforeRun checks "steps.<step_id>" refers to the current step or a step
+// which runs after the current step. Outputs and results of steps are not available until the
+// steps run. It returns IDs of the reported steps. Steps which are not defined in the job at all
+// are reported by type check.
+func (rule *RuleExpression) checkStepReferencedBeforeRun(expr ExprNode, line, col int) []string {
+	if len(rule.laterSteps) == 0 {
+		return nil
+	}
+
+	ids := []string{}
+	VisitExprNode(expr, func(n, _ ExprNode, entering bool) {
+		if !entering {
+			return
+		}
+
+		var recv ExprNode
+		var id string
+		switch n := n.(type) {
+		case *ObjectDerefNode:
+			recv, id = n.Receiver, n.Property
+		case *IndexAccessNode:
+			s, ok := n.Index.(*StringNode)
+			if !ok {
+				return
+			}
+			recv, id = n.Operand, s.Value
+		default:
+			return
+		}
+		if v, ok := recv.(*VariableNode); !ok || v.Name != "steps" {
+			return
+		}
+
+		id = strings.ToLower(id)
+		s, ok := rule.laterSteps[id]
+		if !ok {
+			return
+		}
+
+		t := n.Token()
+		rule.errorf(
+			convertExprLineColToPos(t.Line, t.Column, line, col),
+			"step %q is referenced before it runs. the step is defined at line %d and its \"outputs\", \"outcome\", and \"conclusion\" are only available in the steps after it",
+			s.ID.Value,
+			s.ID.Pos.Line,
+		)
+		ids = append(ids, id)
+	})
+	return ids
+}
+
+// checkNotOpPrecedence checks "!" operator applied to the left operand of comparison like
+// "!a == b". It is parsed as "(!a) == b" due to operator precedence, but "!(a == b)" is often
+// intended. This is an optional check enabled by "not-compare-precedence".
+func (rule *RuleExpression) checkNotOpPrecedence(expr ExprNode, src string, line, col int) {
+	if !rule.isCheckEnabled("not-compare-precedence") {
+		return
+	}
+
+	VisitExprNode(expr, func(n, _ ExprNode, entering bool) {
+		if !entering {
+			return
+		}
+		c, ok := n.(*CompareOpNode)
+		if !ok {
+			return
+		}
+		not, ok := c.Left.(*NotOpNode)
+		if !ok {
+			return
+		}
+		switch not.Operand.(type) {
+		case *VariableNode, *ObjectDerefNode, *IndexAccessNode, *ArrayDerefNode:
+		default:
+			return
+		}
+		if isNotOpParenthesized(src, not, c) {
+			return
+		}
+
+		t := not.Token()
+		rule.infof(
+			convertExprLineColToPos(t.Line, t.Column, line, col),
+			"\"!\" operator is applied only to the left operand of %q operator due to operator precedence. use \"!(a %s b)\" to negate the comparison result, or use \"(!a) %s b\" to clarify the precedence",
+			c.Kind.String(),
+			c.Kind.String(),
+			c.Kind.String(),
+		)
+	})
+}
+
+// isNotOpParenthesized returns if "!" operator at the left operand of the comparison is enclosed
+// with parentheses like "(!a) == b". Parentheses are not remained in the syntax tree so the source
+// is lexed again to find them.
+func isNotOpParenthesized(src string, not *NotOpNode, cmp *CompareOpNode) bool {
+	ts, _, err := LexExpression(src)
+	if err != nil {
+		return false
+	}
+
+	start := not.Token().Offset
+	end := cmp.Right.Token().Offset
+	for i, t := range ts {
+		if t.Offset != start {
+			continue
+		}
+		if i == 0 || ts[i-1].Kind != TokenKindLeftParen {
+			return false
+		}
+		// Find the closing parenthesis matching to the one before "!"
+		depth := 0
+		for _, t := range ts[i-1:] {
+			switch t.Kind {
+			case TokenKindLeftParen:
+				depth++
+			case TokenKindRightParen:
+				depth--
+				if depth == 0 {
+					return t.Offset < end
+				}
+			}
+		}
+		return false
+	}
+	return false
+}
+
+// checkStatusFuncCombination checks status check functions always(), success(), failure() and
+// cancelled() combined illogically with && and || operators in "if" condition. For example,
+// "x || always()" is always true and "success() && always()" is the same as "success()".
+// https://docs.github.com/en/actions/learn-github-actions/expressions#status-check-functions
+func (rule *RuleExpression) checkStatusFuncCombination(expr ExprNode, cond *String) {
+	VisitExprNode(expr, func(n, p ExprNode, entering bool) {
+		if !entering {
+			return
+		}
+		l, ok := n.(*LogicalOpNode)
+		if !ok {
+			return
+		}
+		// Operands of chained operators like "a && b && c" are checked at the root of the chain
+		if pl, ok := p.(*LogicalOpNode); ok && pl.Kind == l.Kind {
+			return
+		}
+
+		funcs := map[string]struct{}{}
+		collectStatusFuncOperands(l, l.Kind, funcs)
+		if _, ok := funcs["always"]; ok {
+			if l.Kind == LogicalOpNodeKindOr {
+				rule.warnf(
+					cond.Pos,
+					"\"if\" condition %q is always true because always() is an operand of \"||\" operator. other operands of the \"||\" operator are meaningless. use \"always() && ...\" to run regardless of the status only when the other condition is satisfied",
+					cond.Value,
+				)
+				return
+			}
+			for _, f := range []string{"success", "failure", "cancelled"} {
+				if _, ok := funcs[f]; ok {
+					rule.warnf(
+						cond.Pos,
+						"always() in \"if\" condition %q is meaningless because it is combined with %s() by \"&&\" operator. %s() decides the status to run. remove always()",
+						cond.Value,
+						f,
+						f,
+					)
+					return
+				}
+			}
+			return
+		}
+
+		if l.Kind != LogicalOpNodeKindAnd {
+			return
+		}
+		if _, ok := funcs["success"]; !ok {
+			return
+		}
+		for _, f := range []string{"failure", "cancelled"} {
+			if _, ok := funcs[f]; ok {
+				rule.warnf(
+					cond.Pos,
+					"\"if\" condition %q is never true because success() and %s() combined by \"&&\" operator are never true at the same time",
+					cond.Value,
+					f,
+				)
+				return
+			}
+		}
+	})
+}
+
+// collectStatusFuncOperands collects names of status check functions which are direct operands of
+// the chain of the logical operators of the given kind.
+func collectStatusFuncOperands(n ExprNode, kind LogicalOpNodeKind, funcs map[string]struct{}) {
+	switch n := n.(type) {
+	case *LogicalOpNode:
+		if n.Kind == kind {
+			collectStatusFuncOperands(n.Left, kind, funcs)
+			collectStatusFuncOperands(n.Right, kind, funcs)
+		}
+	case *FuncCallNode:
+		switch f := strings.ToLower(n.Callee); f {
+		case "always", "success", "failure", "cancelled":
+			funcs[f] = struct{}{}
+		}
+	}
+}
+
+// checkFailureAfterContinueOnError checks failure() in "if" condition of the step after a step
+// which sets "continue-on-error: true". failure() does not detect failure of such step since the
+// failure is masked.
+func (rule *RuleExpression) checkFailureAfterContinueOnError(n *Step) {
+	if rule.continueOnErrorStep == nil || n.If == nil {
+		return
+	}
+
+	src := strings.TrimSpace(n.If.Value)
+	if strings.HasPrefix(src, "${{") && strings.HasSuffix(src, "}}") {
+		src = src[3:]
+	} else {
+		src += "}}" // }} is necessary since lexer lexes it as end of tokens
+	}
+	expr, _, err := rule.exprCache.parse(src)
+	if err != nil {
+		return // Syntax error was already reported by checkIfCondition
+	}
+
+	found := false
+	VisitExprNode(expr, func(n, _ ExprNode, entering bool) {
+		if f, ok := n.(*FuncCallNode); ok && entering && strings.EqualFold(f.Callee, "failure") {
+			found = true
+		}
+	})
+	if !found {
+		return
+	}
+
+	rule.infof(
+		n.If.Pos,
+		"failure() in this condition does not detect failure of the step at line %d since the step sets \"continue-on-error: true\". check the result of the step with \"steps.<step_id>.outcome == 'failure'\" instead",
+		rule.continueOnErrorStep.Pos.Line,
+	)
+}
+
+// checkSecretsInPullRequest checks secrets referred in the workflow triggered by "pull_request"
+// event. Secrets other than GITHUB_TOKEN are not passed to the workflow when it is triggered by a
+// pull request from a forked repository so they are evaluated to empty strings. This is an
+// optional check enabled by "secrets-in-pull-request".
+// https://docs.github.com/en/actions/security-guides/using-secrets-in-github-actions#using-secrets-in-a-workflow
+func (rule *RuleExpression) checkSecretsInPullRequest(expr ExprNode, line, col int) {
+	if rule.pullRequestEvent == nil {
+		return
+	}
+
+	VisitExprNode(expr, func(n, _ ExprNode, entering bool) {
+		if !entering {
+			return
+		}
+
+		var recv ExprNode
+		var name string
+		switch n := n.(type) {
+		case *ObjectDerefNode:
+			recv, name = n.Receiver, n.Property
+		case *IndexAccessNode:
+			s, ok := n.Index.(*StringNode)
+			if !ok {
+				return
+			}
+			recv, name = n.Operand, s.Value
+		default:
+			return
+		}
+
+		if v, ok := recv.(*VariableNode); !ok || !strings.EqualFold(v.Name, "secrets") || strings.EqualFold(name, "github_token") {
+			return
+		}
+
+		t := n.Token()
+		rule.infof(
+			convertExprLineColToPos(t.Line, t.Column, line, col),
+			"%q is referred in the workflow triggered by \"pull_request\" event at line %d. secrets other than GITHUB_TOKEN are not passed to workflows triggered by pull requests from forked repositories so the secret will be empty for them",
+			"secrets."+strings.ToLower(name),
+			rule.pullRequestEvent.Pos.Line,
+		)
+	})
+}
+
+// checkEventNameComparison checks string literals compared with github.event_name are known event
+// names. Such comparison with unknown event name is always false (or true with "!="). Note that
+// string comparison in expressions is case insensitive so "Push" matches to "push".
+// https://docs.github.com/en/actions/learn-github-actions/expressions#operators
+func (rule *RuleExpression) checkEventNameComparison(expr ExprNode, line, col int) {
+	VisitExprNode(expr, func(n, _ ExprNode, entering bool) {
+		if !entering {
+			return
+		}
+
+		c, ok := n.(*CompareOpNode)
+		if !ok || (c.Kind != CompareOpNodeKindEq && c.Kind != CompareOpNodeKindNotEq) {
+			return
+		}
+
+		lit, ok := c.Right.(*StringNode)
+		other := c.Left
+		if !ok {
+			lit, ok = c.Left.(*StringNode)
+			other = c.Right
+		}
+		if !ok || !isGitHubEventNameNode(other) {
+			return
+		}
+
+		name := strings.ToLower(lit.Value)
+		if _, ok := AllWebhookTypes[name]; ok || name == "schedule" || name == "workflow_call" {
+			return
+		}
+
+		ss := make([]string, 0, len(AllWebhookTypes)+2)
+		for n := range AllWebhookTypes {
+			ss = append(ss, n)
+		}
+		ss = append(ss, "schedule", "workflow_call")
+
+		msg := ""
+		if similar := findSimilarStrings(name, ss); len(similar) > 0 {
+			msg = fmt.Sprintf(" did you mean %s?", quotes(similar))
+		}
+
+		t := lit.Token()
+		rule.warnf(
+			convertExprLineColToPos(t.Line, t.Column, line, col),
+			"%q compared with \"github.event_name\" is not a known event name. \"github.event_name\" is never equal to it.%s see https://docs.github.com/en/actions/using-workflows/events-that-trigger-workflows for list of all event names",
+			lit.Value,
+			msg,
+		)
+	})
+}
+
+// checkFromJSONToJSONRoundTrip checks fromJSON(toJSON(...)) calls. Converting a value into JSON
+// string and parsing it again results in the same value so the calls are redundant.
+func (rule *RuleExpression) checkFromJSONToJSONRoundTrip(expr ExprNode, line, col int) {
+	VisitExprNode(expr, func(n, _ ExprNode, entering bool) {
+		if !entering {
+			return
+		}
+
+		outer, ok := n.(*FuncCallNode)
+		if !ok || !strings.EqualFold(outer.Callee, "fromJSON") || len(outer.Args) != 1 {
+			return
+		}
+		inner, ok := outer.Args[0].(*FuncCallNode)
+		if !ok || !strings.EqualFold(inner.Callee, "toJSON") || len(inner.Args) != 1 {
+			return
+		}
+
+		t := outer.Token()
+		rule.warnf(
+			convertExprLineColToPos(t.Line, t.Column, line, col),
+			"%s(%s(...)) is redundant since it converts the value into JSON string and parses it again. remove both %s() and %s() calls and use the argument as-is",
+			outer.Callee,
+			inner.Callee,
+			outer.Callee,
+			inner.Callee,
+		)
+	})
+}
+
+func isGitHubEventNameNode(n ExprNode) bool {
+	d, ok := n.(*ObjectDerefNode)
+	if !ok || d.Property != "event_name" {
+		return false
+	}
+	v, ok := d.Receiver.(*VariableNode)
+	return ok && v.Name == "github"
+}
+
+// checkUndefinedEnv checks "env" context properties which are not defined in any "env" section and
+// not exported to $GITHUB_ENV by previous steps. They are evaluated to empty strings. This is an
+// optional check enabled by "undefined-env".
+func (rule *RuleExpression) checkUndefinedEnv(expr ExprNode, src string, line, col int) {
+	if rule.stepEnv == nil {
+		return
+	}
+
+	VisitExprNode(expr, func(n, _ ExprNode, entering bool) {
+		if !entering {
+			return
+		}
+
+		var name string
+		switch n := n.(type) {
+		case *ObjectDerefNode:
+			if v, ok := n.Receiver.(*VariableNode); !ok || v.Name != "env" {
+				return
+			}
+			name = n.Property
+			if m := reEnvDerefSource.FindStringSubmatch(src[n.Token().Offset:]); m != nil {
+				name = m[1] // Property name in the node is in lower case. Use the name in source instead
+			}
+		case *IndexAccessNode:
+			if v, ok := n.Operand.(*VariableNode); !ok || v.Name != "env" {
+				return
+			}
+			s, ok := n.Index.(*StringNode)
+			if !ok {
+				return
+			}
+			name = s.Value
+		default:
+			return
+		}
+
+		if _, ok := rule.stepEnv[strings.ToLower(name)]; ok {
+			return
+		}
+
+		t := n.Token()
+		rule.warnf(
+			convertExprLineColToPos(t.Line, t.Column, line, col),
+			"environment variable %q is not defined in \"env\" sections of workflow, job, and step, and is not exported to $GITHUB_ENV by previous steps. it is evaluated to an empty string",
+			name,
+		)
+	})
+}
+
+var reEnvDerefSource = regexp.MustCompile(`^(?i:env)\s*\.\s*([a-zA-Z_][a-zA-Z0-9_-]*)`)
+
+// reExportedEnvVar matches environment variable names exported to $GITHUB_ENV like
+// `echo "NAME=value" >> $GITHUB_ENV` or `echo "NAME<<EOF" >> $GITHUB_ENV`.
+var reExportedEnvVar = regexp.MustCompile(`\b(?:echo|printf)\s+(?:-[a-zA-Z]+\s+)*["']?([a-zA-Z_][a-zA-Z0-9_]*)(?:=|<<)`)
+
+func copyEnvVarNames(names map[string]struct{}) map[string]struct{} {
+	ret := make(map[string]struct{}, len(names))
+	for n := range names {
+		ret[n] = struct{}{}
+	}
+	return ret
+}
+
+// addEnvVarNames adds names of environment variables in the env section to the set. It returns nil
+// when the names cannot be known statically.
+func addEnvVarNames(names map[string]struct{}, env *Env) map[string]struct{} {
+	if env == nil {
+		return names
+	}
+	if env.Expression != nil {
+		return nil
+	}
+	for n := range env.Vars {
+		names[n] = struct{}{}
+	}
+	return names
+}
+
+// addExportedEnvVarNames adds names of environment variables exported to $GITHUB_ENV by the step.
+// It returns nil when the names cannot be known statically. Since actions may export arbitrary
+// environment variables, names cannot be known after running an action.
+func addExportedEnvVarNames(names map[string]struct{}, exec Exec) map[string]struct{} {
+	r, ok := exec.(*ExecRun)
+	if !ok {
+		return nil
+	}
+	if r.Run == nil || !strings.Contains(r.Run.Value, "GITHUB_ENV") {
+		return names
+	}
+	ms := reExportedEnvVar.FindAllStringSubmatch(r.Run.Value, -1)
+	if len(ms) == 0 {
+		return nil // e.g. cat file >> "$GITHUB_ENV"
+	}
+	for _, m := range ms {
+		names[strings.ToLower(m[1])] = struct{}{}
+	}
+	return names
+}
+
+// checkUndefinedStepOutput checks step outputs referred at "outputs" section of job which are never
+// set by the steps. Outputs set by `run:` steps are detected from their scripts heuristically. When
+// the step never sets any output, referring the entire outputs object like `toJSON(steps.foo.outputs)`
+// is also reported. This is an optional check enabled by "undefined-step-output".
+func (rule *RuleExpression) checkUndefinedStepOutput(expr ExprNode, line, col int) {
+	if rule.stepOutputs == nil {
+		return
+	}
+
+	VisitExprNode(expr, func(n, parent ExprNode, entering bool) {
+		if !entering {
+			return
+		}
+
+		var recv ExprNode
+		var name string
+		switch n := n.(type) {
+		case *ObjectDerefNode:
+			recv, name = n.Receiver, n.Property
+		case *IndexAccessNode:
+			s, ok := n.Index.(*StringNode)
+			if !ok {
+				return
+			}
+			recv, name = n.Operand, s.Value
+		default:
+			return
+		}
+
+		// Find steps.<step_id>.outputs
+		if id, ok := stepOutputsObjectID(n); ok {
+			names := rule.stepOutputs[id]
+			if names == nil || len(names) > 0 || isStepOutputAccess(n, parent) {
+				return
+			}
+			t := n.Token()
+			rule.warnf(
+				convertExprLineColToPos(t.Line, t.Column, line, col),
+				"outputs of step %q are referred but the step never sets any output. the outputs object is always empty. set outputs like `echo \"name=value\" >> $GITHUB_OUTPUT` in the step's script",
+				id,
+			)
+			return
+		}
+		id, ok := stepOutputsObjectID(recv)
+		if !ok {
+			return
+		}
+
+		names, ok := rule.stepOutputs[id]
+		if !ok || names == nil {
+			return // Unknown step ID is reported by type check. Or outputs cannot be known statically
+		}
+		if _, ok := names[strings.ToLower(name)]; ok {
+			return
+		}
+
+		var note string
+		if len(names) == 0 {
+			note = " the step never sets any output."
+		}
+		t := n.Token()
+		rule.warnf(
+			convertExprLineColToPos(t.Line, t.Column, line, col),
+			"output %q of step %q is never set by the step.%s it is evaluated to an empty string. set the output like `echo \"%s=value\" >> $GITHUB_OUTPUT` in the step's script",
+			name,
+			id,
+			note,
+			name,
+		)
+	})
+}
+
+// stepOutputsObjectID returns the step ID when the node is "steps.<step_id>.outputs".
+func stepOutputsObjectID(n ExprNode) (string, bool) {
+	o, ok := n.(*ObjectDerefNode)
+	if !ok || o.Property != "outputs" {
+		return "", false
+	}
+	d, ok := o.Receiver.(*ObjectDerefNode)
+	if !ok {
+		return "", false
+	}
+	if v, ok := d.Receiver.(*VariableNode); !ok || v.Name != "steps" {
+		return "", false
+	}
+	return d.Property, true
+}
+
+// isStepOutputAccess returns true when the parent node accesses a specific output of the outputs
+// object like "steps.foo.outputs.bar" or "steps.foo.outputs['bar']".
+func isStepOutputAccess(outputs, parent ExprNode) bool {
+	switch p := parent.(type) {
+	case *ObjectDerefNode:
+		return p.Receiver == outputs
+	case *IndexAccessNode:
+		_, ok := p.Index.(*StringNode)
+		return ok && p.Operand == outputs
+	default:
+		return false
+	}
+}
+
+// collectUsedStepOutputs collects outputs of steps referred in the expression like
+// "steps.<step_id>.outputs.<name>" for "unused-step-output" optional check.
+func (rule *RuleExpression) collectUsedStepOutputs(expr ExprNode) {
+	if rule.usedStepOutputs == nil {
+		return
+	}
+
+	useAll := func(id string) {
+		rule.usedStepOutputs[id] = nil
+	}
+	use := func(id, name string) {
+		names, ok := rule.usedStepOutputs[id]
+		if !ok {
+			names = map[string]struct{}{}
+			rule.usedStepOutputs[id] = names
+		}
+		if names != nil {
+			names[name] = struct{}{}
+		}
+	}
+
+	// Receivers of property accesses which were already handled. Since parent nodes are visited
+	// before their children, `steps.foo` in `steps.foo.outcome` should not be handled as the whole
+	// step object.
+	handled := map[ExprNode]struct{}{}
+	VisitExprNode(expr, func(n, _ ExprNode, entering bool) {
+		if rule.usedStepOutputs == nil || !entering {
+			return
+		}
+		if _, ok := handled[n]; ok {
+			return
+		}
+
+		recv, prop, ok := accessedProperty(n)
+		if !ok {
+			if v, ok := n.(*VariableNode); ok && v.Name == "steps" {
+				// The whole "steps" object is used like `toJSON(steps)` or `steps.*.outputs`
+				rule.usedStepOutputs = nil
+			}
+			return
+		}
+
+		// steps.<step_id>.<prop>
+		if r, id, ok := accessedProperty(recv); ok && isStepsVariable(r) {
+			handled[recv] = struct{}{}
+			handled[r] = struct{}{}
+			if prop == "outputs" {
+				useAll(id)
+			}
+			return
+		}
+
+		// steps.<step_id>.outputs.<prop>
+		if o, p, ok := accessedProperty(recv); ok && p == "outputs" {
+			if r, id, ok := accessedProperty(o); ok && isStepsVariable(r) {
+				handled[recv] = struct{}{}
+				handled[o] = struct{}{}
+				handled[r] = struct{}{}
+				use(id, prop)
+				return
+			}
+		}
+
+		// steps.<step_id>
+		if isStepsVariable(recv) {
+			handled[recv] = struct{}{}
+			useAll(prop)
+		}
+	})
+}
+
+// checkUnusedStepOutputs checks outputs set by steps in the current job are referred by some
+// expressions in the job. Outputs of steps are not available outside the job so outputs which are
+// not referred in the job are never used. Outputs set by `run:` steps are detected from their
+// scripts heuristically. This is an optional check enabled by "unused-step-output".
+func (rule *RuleExpression) checkUnusedStepOutputs() {
+	if rule.usedStepOutputs == nil {
+		return
+	}
+
+	for _, s := range rule.outputSteps {
+		id := strings.ToLower(s.ID.Value)
+		used, ok := rule.usedStepOutputs[id]
+		if ok && used == nil {
+			continue
+		}
+
+		names := make([]string, 0, len(used))
+		for n := range stepOutputNames(s.Exec) {
+			if _, ok := used[n]; !ok {
+				names = append(names, n)
+			}
+		}
+		sort.Strings(names)
+
+		for _, n := range names {
+			rule.warnf(
+				s.ID.Pos,
+				"output %q set by step %q is never used in the job. outputs of steps are only available in the same job. remove the output or refer it with \"steps.%s.outputs.%s\"",
+				n,
+				s.ID.Value,
+				s.ID.Value,
+				n,
+			)
+		}
+	}
+}
+
+// collectUsedInputsAndSecrets collects names of inputs and secrets referred in the expression like
+// "inputs.<name>" or "secrets.<name>" for "unused-workflow-call-input" optional check.
+func (rule *RuleExpression) collectUsedInputsAndSecrets(expr ExprNode) {
+	if rule.usedInputs == nil && rule.usedSecrets == nil {
+		return
+	}
+
+	// Receivers of property accesses which were already handled. Since parent nodes are visited
+	// before their children, `inputs` in `inputs.foo` should not be handled as the whole object.
+	handled := map[ExprNode]struct{}{}
+	VisitExprNode(expr, func(n, _ ExprNode, entering bool) {
+		if !entering {
+			return
+		}
+		if _, ok := handled[n]; ok {
+			return
+		}
+
+		if v, ok := n.(*VariableNode); ok {
+			// The whole object is used like `toJSON(inputs)` or `secrets.*`
+			switch v.Name {
+			case "inputs":
+				rule.usedInputs = nil
+			case "secrets":
+				rule.usedSecrets = nil
+			}
+			return
+		}
+
+		recv, prop, ok := accessedProperty(n)
+		if !ok {
+			return
+		}
+		v, ok := recv.(*VariableNode)
+		if !ok {
+			return
+		}
+		handled[recv] = struct{}{}
+		switch v.Name {
+		case "inputs":
+			if rule.usedInputs != nil {
+				rule.usedInputs[prop] = struct{}{}
+			}
+		case "secrets":
+			if rule.usedSecrets != nil {
+				rule.usedSecrets[prop] = struct{}{}
+			}
+		}
+	})
+}
+
+// checkUnusedWorkflowCallInputs checks inputs and secrets declared at "on.workflow_call" are referred
+// by some expressions in the workflow. This is an optional check enabled by
+// "unused-workflow-call-input".
+func (rule *RuleExpression) checkUnusedWorkflowCallInputs(e *WorkflowCallEvent) {
+	if rule.usedInputs != nil {
+		for _, i := range e.Inputs {
+			if _, ok := rule.usedInputs[i.ID]; ok {
+				continue
+			}
+			note := ""
+			if i.IsRequired() {
+				note = " callers must pass the value though it is not used."
+			}
+			rule.infof(
+				i.Name.Pos,
+				"input %q of workflow_call event is never used in the workflow.%s remove the input or refer it with \"inputs.%s\"",
+				i.Name.Value,
+				note,
+				i.Name.Value,
+			)
+		}
+	}
+
+	if rule.usedSecrets != nil {
+		ids := make([]string, 0, len(e.Secrets))
+		for id := range e.Secrets {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+
+		for _, id := range ids {
+			if _, ok := rule.usedSecrets[id]; ok {
+				continue
+			}
+			s := e.Secrets[id]
+			note := ""
+			if s.Required != nil && s.Required.Value {
+				note = " callers must pass the value though it is not used."
+			}
+			rule.infof(
+				s.Name.Pos,
+				"secret %q of workflow_call event is never used in the workflow.%s remove the secret or refer it with \"secrets.%s\"",
+				s.Name.Value,
+				note,
+				s.Name.Value,
+			)
+		}
+	}
+}
+
+// accessedProperty returns the receiver and the property name in lower case when the node is
+// property access like `a.b` or `a['b']`.
+func accessedProperty(n ExprNode) (ExprNode, string, bool) {
+	switch n := n.(type) {
+	case *ObjectDerefNode:
+		return n.Receiver, strings.ToLower(n.Property), true
+	case *IndexAccessNode:
+		if s, ok := n.Index.(*StringNode); ok {
+			return n.Operand, strings.ToLower(s.Value), true
+		}
+	}
+	return nil, "", false
+}
+
+func isStepsVariable(n ExprNode) bool {
+	v, ok := n.(*VariableNode)
+	return ok && v.Name == "steps"
+}
+
+// reStepOutputName matches output names written to $GITHUB_OUTPUT like `echo "name=value" >> $GITHUB_OUTPUT`
+// or `echo "name<<EOF" >> $GITHUB_OUTPUT`, or set by deprecated `::set-output name=name::value` command.
+// Values written to other files like $GITHUB_ENV are not matched.
+var reStepOutputName = regexp.MustCompile(`\b(?:echo|printf)\s+(?:-[a-zA-Z]+\s+)*["']?([a-zA-Z_][a-zA-Z0-9_-]*)(?:=|<<)[^>]*>>?\s*"?\$(?:GITHUB_OUTPUT\b|\{GITHUB_OUTPUT\})|::set-output\s+name=([a-zA-Z_][a-zA-Z0-9_-]*)::`)
+
+// stepOutputNames returns a set of output names set by the step. It returns nil when the names
+// cannot be known statically. Since actions may set arbitrary outputs, names cannot be known for
+// steps running actions.
+func stepOutputNames(exec Exec) map[string]struct{} {
+	r, ok := exec.(*ExecRun)
+	if !ok {
+		return nil
+	}
+	names := map[string]struct{}{}
+	if r.Run == nil {
+		return names
+	}
+	for _, line := range strings.Split(r.Run.Value, "\n") {
+		if !strings.Contains(line, "GITHUB_OUTPUT") && !strings.Contains(line, "::set-output") {
+			continue
+		}
+		ms := reStepOutputName.FindAllStringSubmatch(line, -1)
+		if len(ms) == 0 {
+			return nil // e.g. cat file >> "$GITHUB_OUTPUT"
+		}
+		for _, m := range ms {
+			n := m[1]
+			if n == "" {
+				n = m[2]
+			}
+			names[strings.ToLower(n)] = struct{}{}
+		}
+	}
+	return names
 }
 
 func (rule *RuleExpression) calcNeedsType(job *Job) *ObjectType {
@@ -897,11 +1951,24 @@ func (rule *RuleExpression) guessTypeOfMatrixExpression(expr *String) *ObjectTyp
 	if !ok {
 		return NewEmptyObjectType()
 	}
+	matTy = matTy.DeepCopy().(*ObjectType) // The type may be shared with other contexts
 
 	// Consider properties in include section elements since 'include' section adds matrix values
-	incTy, ok := matTy.Props["include"]
-	if ok {
-		delete(matTy.Props, "include")
+	incTy, hasInc := matTy.Props["include"]
+	delete(matTy.Props, "include")
+	delete(matTy.Props, "exclude")
+
+	// Each row is an array of values of the matrix. For example, the type of `matrix.os` is string
+	// when the row is `"os": ["ubuntu-latest", "macos-latest"]`
+	for n, t := range matTy.Props {
+		if a, ok := t.(*ArrayType); ok {
+			matTy.Props[n] = a.Elem
+		} else {
+			matTy.Props[n] = AnyType{}
+		}
+	}
+
+	if hasInc {
 		if a, ok := incTy.(*ArrayType); ok {
 			if o, ok := a.Elem.(*ObjectType); ok {
 				for n, p := range o.Props {
@@ -916,8 +1983,6 @@ func (rule *RuleExpression) guessTypeOfMatrixExpression(expr *String) *ObjectTyp
 		}
 	}
 
-	delete(matTy.Props, "exclude")
-
 	return matTy
 }
 
@@ -932,16 +1997,18 @@ func (rule *RuleExpression) guessTypeOfMatrix(m *Matrix) *ObjectType {
 		o.Props[n] = rule.guessTypeOfMatrixRow(r)
 	}
 
-	// Note: Type check in 'include' section duplicates with checkMatrixCombinations() method
-
 	if m.Include == nil {
 		return o
 	}
 
+	// Expressions in 'include' section are checked here instead of checkMatrixCombinations() not to
+	// report the same errors twice
 	if m.Include.Expression != nil {
-		if a, ok := rule.checkOneExpression(m.Include.Expression, "include", "jobs.<job_id>.strategy").(*ArrayType); ok {
-			if ret, ok := o.Merge(a.Elem).(*ObjectType); ok {
-				return ret
+		if a, ok := rule.checkArrayExpression(m.Include.Expression, "include", "jobs.<job_id>.strategy").(*ArrayType); ok {
+			if elem := rule.checkObjectTy(a.Elem, m.Include.Expression.Pos, "include"); elem != nil {
+				if ret, ok := o.Merge(elem).(*ObjectType); ok {
+					return ret
+				}
 			}
 		}
 		return NewEmptyObjectType()
@@ -949,7 +2016,7 @@ func (rule *RuleExpression) guessTypeOfMatrix(m *Matrix) *ObjectType {
 
 	for _, combi := range m.Include.Combinations {
 		if combi.Expression != nil {
-			ty := rule.checkOneExpression(m.Include.Expression, "matrix combination at element of include section", "jobs.<job_id>.strategy")
+			ty := rule.checkObjectExpression(combi.Expression, "matrix combination at element of include section", "jobs.<job_id>.strategy")
 			if ty == nil {
 				continue
 			}
@@ -962,6 +2029,7 @@ func (rule *RuleExpression) guessTypeOfMatrix(m *Matrix) *ObjectType {
 		}
 
 		for n, assign := range combi.Assigns {
+			rule.checkRawYAMLValue(assign.Value)
 			ty := guessTypeOfRawYAMLValue(assign.Value)
 			if t, ok := o.Props[n]; ok {
 				// When the combination exists in 'matrix' section, merge type with existing one
@@ -978,8 +2046,10 @@ func (rule *RuleExpression) guessTypeOfMatrix(m *Matrix) *ObjectType {
 
 func (rule *RuleExpression) guessTypeOfMatrixRow(r *MatrixRow) ExprType {
 	if r.Expression != nil {
+		// The type of matrix value is the element type of the row. For example, the type of `matrix.os`
+		// is string when the row is `os: ${{ fromJSON('["ubuntu-latest", "macos-latest"]') }}`
 		if a, ok := rule.checkArrayExpression(r.Expression, "matrix row", "jobs.<job_id>.strategy").(*ArrayType); ok {
-			return a
+			return a.Elem
 		}
 		return AnyType{}
 	}
@@ -0,0 +1,1488 @@
+package actionlint
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRuleExpressionNotOpPrecedence(t *testing.T) {
+	testCases := []struct {
+		what  string
+		input string
+		want  []string
+	}{
+		{
+			what:  "not operator at left operand of ==",
+			input: "!github.event.pull_request.draft == true",
+			want:  []string{`"!" operator is applied only to the left operand of "==" operator`},
+		},
+		{
+			what:  "not operator at left operand of !=",
+			input: "!env.FOO != 'true'",
+			want:  []string{`"!" operator is applied only to the left operand of "!=" operator`},
+		},
+		{
+			what:  "not operator to variable",
+			input: "!github == null",
+			want:  []string{`"!" operator is applied only to the left operand of "==" operator`},
+		},
+		{
+			what:  "not operator to index access",
+			input: "!github.event['action'] == 'opened'",
+			want:  []string{`"!" operator is applied only to the left operand of "==" operator`},
+		},
+		{
+			what:  "whole comparison is enclosed with parens",
+			input: "(!env.FOO == 'true')",
+			want:  []string{`"!" operator is applied only to the left operand of "==" operator`},
+		},
+		{
+			what:  "nested comparison",
+			input: "github.event_name == 'push' && !env.FOO == 'true'",
+			want:  []string{`"!" operator is applied only to the left operand of "==" operator`},
+		},
+		{
+			what:  "comparison is negated",
+			input: "!(env.FOO == 'true')",
+		},
+		{
+			what:  "not operator is enclosed with parens",
+			input: "(!env.FOO) == true",
+		},
+		{
+			what:  "not operator is enclosed with nested parens",
+			input: "((!env.FOO)) == (true)",
+		},
+		{
+			what:  "not operator is applied to function call",
+			input: "!contains(github.ref, 'foo') == true",
+		},
+		{
+			what:  "not operator at right operand",
+			input: "true == !env.FOO",
+		},
+		{
+			what:  "no comparison",
+			input: "!env.FOO",
+		},
+	}
+
+	for _, tc := range testCases {
+		for _, inIf := range []bool{true, false} {
+			what := tc.what
+			src := "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo\n"
+			line := 7
+			if inIf {
+				// Quote the condition since "!" at the beginning of value is a tag in YAML
+				what += " in if condition"
+				src += "        if: \"" + tc.input + "\"\n"
+			} else {
+				src += "        env:\n          FOO: ${{ " + tc.input + " }}\n"
+				line = 8
+			}
+
+			t.Run(what, func(t *testing.T) {
+				w, errs := Parse([]byte(src))
+				if len(errs) > 0 {
+					t.Fatal(errs)
+				}
+
+				errs = testOptionalCheck(t, "not-compare-precedence", SeverityInfo, tc.want, func(cfg *Config) []*Error {
+					return visitWithRule(t, w, NewRuleExpression(nil, nil), cfg)
+				})
+				for _, err := range errs {
+					if err.Line != line {
+						t.Errorf("error should be reported at line %d: %s", line, err)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestRuleExpressionUndefinedEnv(t *testing.T) {
+	testCases := []struct {
+		what  string
+		input string
+		want  []string
+	}{
+		{
+			what: "undefined env var",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo "$KEY"
+        env:
+          KEY: ${{ env.DEPLOY_KEY }}`,
+			want: []string{`:8:20: environment variable "DEPLOY_KEY" is not defined`},
+		},
+		{
+			what: "undefined env var with index access",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo ${{ env['DEPLOY_KEY'] }}`,
+			want: []string{`:6:23: environment variable "DEPLOY_KEY" is not defined`},
+		},
+		{
+			what: "undefined env var in if condition",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo
+        if: env.DEPLOY_KEY != ''`,
+			want: []string{`:7:13: environment variable "DEPLOY_KEY" is not defined`},
+		},
+		{
+			what: "multiple undefined env vars",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo ${{ env.FOO }} ${{ env.BAR }}`,
+			want: []string{
+				`environment variable "FOO" is not defined`,
+				`environment variable "BAR" is not defined`,
+			},
+		},
+		{
+			what: "env var at workflow, job and step",
+			input: `
+env:
+  FOO: foo
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    env:
+      BAR: bar
+    steps:
+      - run: echo ${{ env.FOO }} ${{ env.BAR }} ${{ env.PIYO }}
+        env:
+          PIYO: piyo`,
+		},
+		{
+			what: "env var names are case insensitive",
+			input: `
+env:
+  FOO: foo
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo ${{ env.foo }}`,
+		},
+		{
+			what: "env var of other step",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo
+        env:
+          FOO: foo
+      - run: echo ${{ env.FOO }}`,
+			want: []string{`:9:23: environment variable "FOO" is not defined`},
+		},
+		{
+			what: "env var of other job",
+			input: `
+jobs:
+  test1:
+    runs-on: ubuntu-latest
+    env:
+      FOO: foo
+    steps:
+      - run: echo
+  test2:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo ${{ env.FOO }}`,
+			want: []string{`:12:23: environment variable "FOO" is not defined`},
+		},
+		{
+			what: "env var exported to GITHUB_ENV",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: |
+          echo "FOO=foo" >> "$GITHUB_ENV"
+          echo 'BAR<<EOF' >> $GITHUB_ENV
+          echo bar >> $GITHUB_ENV
+          echo EOF >> $GITHUB_ENV
+      - run: echo ${{ env.FOO }} ${{ env.BAR }} ${{ env.PIYO }}`,
+			want: []string{`environment variable "PIYO" is not defined`},
+		},
+		{
+			what: "env var exported to GITHUB_ENV in the same step",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: |
+          echo "FOO=foo" >> "$GITHUB_ENV"
+          echo ${{ env.FOO }}`,
+			want: []string{`environment variable "FOO" is not defined`},
+		},
+		{
+			what: "env vars exported dynamically",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: cat .env >> "$GITHUB_ENV"
+      - run: echo ${{ env.FOO }}`,
+		},
+		{
+			what: "env vars exported by action",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo ${{ env.FOO }}
+      - uses: actions/checkout@v3
+      - run: echo ${{ env.FOO }}`,
+			want: []string{`:6:23: environment variable "FOO" is not defined`},
+		},
+		{
+			what: "workflow env is set by expression",
+			input: `
+env: ${{ fromJSON(github.event.inputs.env) }}
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo ${{ env.FOO }}`,
+		},
+		{
+			what: "job env is set by expression",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    env: ${{ fromJSON(github.event.inputs.env) }}
+    steps:
+      - run: echo ${{ env.FOO }}`,
+		},
+		{
+			what: "step env is set by expression",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo ${{ env.FOO }}
+        env: ${{ fromJSON(github.event.inputs.env) }}`,
+		},
+		{
+			what: "env context itself",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo ${{ toJSON(env) }}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			w, errs := Parse([]byte("on: push" + tc.input + "\n"))
+			if len(errs) > 0 {
+				t.Fatal(errs)
+			}
+
+			testOptionalCheck(t, "undefined-env", SeverityWarning, tc.want, func(cfg *Config) []*Error {
+				return visitWithRule(t, w, NewRuleExpression(nil, nil), cfg)
+			})
+		})
+	}
+}
+
+func TestRuleExpressionUndefinedStepOutput(t *testing.T) {
+	testCases := []struct {
+		what  string
+		input string
+		want  []string
+	}{
+		{
+			what: "output not set by step",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    outputs:
+      version: ${{ steps.get.outputs.version }}
+    steps:
+      - id: get
+        run: echo "ver=1.0.0" >> "$GITHUB_OUTPUT"`,
+			want: []string{`:6:20: output "version" of step "get" is never set by the step`},
+		},
+		{
+			what: "output not set with index access",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    outputs:
+      version: ${{ steps.get.outputs['version'] }}
+    steps:
+      - id: get
+        run: echo "ver=1.0.0" >> "$GITHUB_OUTPUT"`,
+			want: []string{`:6:20: output "version" of step "get" is never set by the step`},
+		},
+		{
+			what: "step does not write to $GITHUB_OUTPUT",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    outputs:
+      version: ${{ steps.get.outputs.version }}
+    steps:
+      - id: get
+        run: echo "version=1.0.0"`,
+			want: []string{`output "version" of step "get" is never set by the step. the step never sets any output.`},
+		},
+		{
+			what: "entire outputs of step which never sets output",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    outputs:
+      all: ${{ toJSON(steps.build.outputs) }}
+    steps:
+      - id: build
+        run: make`,
+			want: []string{`:6:23: outputs of step "build" are referred but the step never sets any output`},
+		},
+		{
+			what: "dynamic index access to outputs of step which never sets output",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    outputs:
+      out: ${{ steps.build.outputs[github.ref_name] }}
+    steps:
+      - id: build
+        run: make`,
+			want: []string{`:6:16: outputs of step "build" are referred but the step never sets any output`},
+		},
+		{
+			what: "entire outputs of step which sets output",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    outputs:
+      all: ${{ toJSON(steps.build.outputs) }}
+      out: ${{ steps.build.outputs[github.ref_name] }}
+    steps:
+      - id: build
+        run: echo "foo=1" >> "$GITHUB_OUTPUT"`,
+		},
+		{
+			what: "multiple outputs",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    outputs:
+      foo: ${{ steps.a.outputs.foo }}
+      bar: ${{ steps.a.outputs.bar }}-${{ steps.b.outputs.baz }}
+    steps:
+      - id: a
+        run: echo "foo=1" >> "$GITHUB_OUTPUT"
+      - id: b
+        run: echo "qux=1" >> "$GITHUB_OUTPUT"`,
+			want: []string{
+				`:7:16: output "bar" of step "a" is never set by the step`,
+				`:7:43: output "baz" of step "b" is never set by the step`,
+			},
+		},
+		{
+			what: "output is set",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    outputs:
+      version: ${{ steps.get.outputs.version }}
+    steps:
+      - id: get
+        run: |
+          v="$(cat VERSION)"
+          echo "version=${v}" >> "$GITHUB_OUTPUT"`,
+		},
+		{
+			what: "output is set with delimiter",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    outputs:
+      log: ${{ steps.get.outputs.log }}
+    steps:
+      - id: get
+        run: |
+          echo 'log<<EOF' >> "$GITHUB_OUTPUT"
+          git log --oneline >> "$GITHUB_OUTPUT"
+          echo 'EOF' >> "$GITHUB_OUTPUT"`,
+		},
+		{
+			what: "output is set by deprecated command",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    outputs:
+      version: ${{ steps.get.outputs.version }}
+    steps:
+      - id: get
+        run: echo "::set-output name=version::1.0.0"`,
+		},
+		{
+			what: "output names are case insensitive",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    outputs:
+      version: ${{ steps.get.outputs.VERSION }}
+    steps:
+      - id: get
+        run: echo "Version=1.0.0" >> $GITHUB_OUTPUT`,
+		},
+		{
+			what: "environment variable written to GITHUB_ENV is not output",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    outputs:
+      foo: ${{ steps.get.outputs.foo }}
+    steps:
+      - id: get
+        run: |
+          echo "FOO=1" >> "$GITHUB_ENV"
+          echo "bar=2" >> "$GITHUB_OUTPUT"`,
+			want: []string{`:6:16: output "foo" of step "get" is never set by the step`},
+		},
+		{
+			what: "outputs cannot be known statically",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    outputs:
+      version: ${{ steps.get.outputs.version }}
+    steps:
+      - id: get
+        run: cat outputs.txt >> "$GITHUB_OUTPUT"`,
+		},
+		{
+			what: "outputs of action",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    outputs:
+      foo: ${{ steps.act.outputs.foo }}
+    steps:
+      - id: act
+        uses: owner/repo@v1`,
+		},
+		{
+			what: "step ID is not checked",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    outputs:
+      foo: ${{ toJSON(steps) }}
+    steps:
+      - id: get
+        run: echo`,
+		},
+		{
+			what: "outputs referred in steps are not checked",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - id: get
+        run: echo
+      - run: echo ${{ steps.get.outputs.foo }}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			w, errs := Parse([]byte("on: push" + tc.input + "\n"))
+			if len(errs) > 0 {
+				t.Fatal(errs)
+			}
+
+			testOptionalCheck(t, "undefined-step-output", SeverityWarning, tc.want, func(cfg *Config) []*Error {
+				return visitWithRule(t, w, NewRuleExpression(nil, nil), cfg)
+			})
+		})
+	}
+}
+
+func TestRuleExpressionStepReferencedBeforeRun(t *testing.T) {
+	testCases := []struct {
+		what  string
+		input string
+		want  []string
+	}{
+		{
+			what: "step defined later",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo '${{ steps.build.outputs.path }}'
+      - id: build
+        run: echo "path=dist" >> "$GITHUB_OUTPUT"`,
+			want: []string{`:6:24: step "build" is referenced before it runs. the step is defined at line 7`},
+		},
+		{
+			what: "current step",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - id: build
+        if: steps.build.outcome == 'success'
+        run: echo`,
+			want: []string{`:7:13: step "build" is referenced before it runs. the step is defined at line 6`},
+		},
+		{
+			what: "index access to step whose ID is in upper case",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo '${{ steps['build'].conclusion }}'
+      - id: Build
+        run: echo`,
+			want: []string{`:6:24: step "Build" is referenced before it runs`},
+		},
+		{
+			what: "undefined step",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo '${{ steps.build.outputs.path }}'
+      - id: test
+        run: echo`,
+			want: []string{`:6:24: property "build" is not defined in object type {}`},
+		},
+		{
+			what: "step defined in other job",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo '${{ steps.build.outputs.path }}'
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - id: build
+        run: echo`,
+			want: []string{`:6:24: property "build" is not defined in object type {}`},
+		},
+		{
+			what: "step defined earlier",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - id: build
+        run: echo "path=dist" >> "$GITHUB_OUTPUT"
+      - run: echo '${{ steps.build.outputs.path }}'`,
+		},
+		{
+			what: "job outputs",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    outputs:
+      path: ${{ steps.build.outputs.path }}
+    steps:
+      - id: build
+        run: echo "path=dist" >> "$GITHUB_OUTPUT"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			w, errs := Parse([]byte("on: push" + tc.input + "\n"))
+			if len(errs) > 0 {
+				t.Fatal(errs)
+			}
+
+			r := NewRuleExpression(nil, nil)
+			v := NewVisitor()
+			v.AddPass(r)
+			if err := v.Visit(w); err != nil {
+				t.Fatal(err)
+			}
+
+			errs = r.Errs()
+			if len(errs) != len(tc.want) {
+				t.Fatalf("wanted %d errors but got %d errors: %v", len(tc.want), len(errs), errs)
+			}
+			for i, err := range errs {
+				if !strings.Contains(err.Error(), tc.want[i]) {
+					t.Errorf("error %q does not contain %q", err.Error(), tc.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRuleExpressionUnusedStepOutput(t *testing.T) {
+	testCases := []struct {
+		what  string
+		input string
+		want  []string
+	}{
+		{
+			what: "output never used",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - id: get
+        run: echo "version=1.0.0" >> "$GITHUB_OUTPUT"
+      - run: echo done`,
+			want: []string{`:6:13: output "version" set by step "get" is never used in the job`},
+		},
+		{
+			what: "some outputs never used",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - id: get
+        run: |
+          echo "foo=1" >> "$GITHUB_OUTPUT"
+          echo "bar=2" >> "$GITHUB_OUTPUT"
+          echo "piyo=3" >> "$GITHUB_OUTPUT"
+      - run: echo '${{ steps.get.outputs.foo }}'`,
+			want: []string{
+				`:6:13: output "bar" set by step "get" is never used in the job`,
+				`:6:13: output "piyo" set by step "get" is never used in the job`,
+			},
+		},
+		{
+			what: "output used by other job",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - id: get
+        run: echo "version=1.0.0" >> "$GITHUB_OUTPUT"
+  other:
+    runs-on: ubuntu-latest
+    steps:
+      - id: get
+        run: echo
+      - run: echo '${{ steps.get.outputs.version }}'`,
+			want: []string{`:6:13: output "version" set by step "get" is never used in the job`},
+		},
+		{
+			what: "output used by later step",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - id: get
+        run: echo "version=1.0.0" >> "$GITHUB_OUTPUT"
+      - run: echo '${{ steps.get.outputs.version }}'`,
+		},
+		{
+			what: "output used by index access",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - id: get
+        run: echo "version=1.0.0" >> "$GITHUB_OUTPUT"
+      - run: echo '${{ steps['get'].outputs['VERSION'] }}'`,
+		},
+		{
+			what: "output used by if condition",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - id: check
+        run: echo "changed=true" >> "$GITHUB_OUTPUT"
+      - run: echo changed
+        if: steps.check.outputs.changed == 'true'`,
+		},
+		{
+			what: "output used by job outputs",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    outputs:
+      version: ${{ steps.get.outputs.version }}
+    steps:
+      - id: get
+        run: echo "version=1.0.0" >> "$GITHUB_OUTPUT"`,
+		},
+		{
+			what: "whole outputs object is used",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - id: get
+        run: echo "version=1.0.0" >> "$GITHUB_OUTPUT"
+      - run: echo '${{ toJSON(steps.get.outputs) }}'`,
+		},
+		{
+			what: "whole step object is used",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - id: get
+        run: echo "version=1.0.0" >> "$GITHUB_OUTPUT"
+      - run: echo '${{ toJSON(steps.get) }}'`,
+		},
+		{
+			what: "whole steps object is used",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - id: get
+        run: echo "version=1.0.0" >> "$GITHUB_OUTPUT"
+      - run: echo '${{ toJSON(steps) }}'`,
+		},
+		{
+			what: "other property of step is used",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - id: get
+        run: echo "version=1.0.0" >> "$GITHUB_OUTPUT"
+      - run: echo '${{ steps.get.outcome }}'`,
+			want: []string{`:6:13: output "version" set by step "get" is never used in the job`},
+		},
+		{
+			what: "environment variable written to GITHUB_ENV is not output",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - id: get
+        run: |
+          echo "FOO=1" >> "$GITHUB_ENV"
+          echo "bar=2" >> "$GITHUB_OUTPUT"`,
+			want: []string{`:6:13: output "bar" set by step "get" is never used in the job`},
+		},
+		{
+			what: "GITHUB_ENV and GITHUB_OUTPUT written in the same line",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - id: get
+        run: echo "FOO=1" >> "$GITHUB_ENV"; echo "bar=2" >> "$GITHUB_OUTPUT"`,
+			want: []string{`:6:13: output "bar" set by step "get" is never used in the job`},
+		},
+		{
+			what: "outputs cannot be known statically",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - id: get
+        run: cat outputs.txt >> "$GITHUB_OUTPUT"
+      - id: action
+        uses: actions/cache@v3
+        with:
+          path: ~/.cache
+          key: ${{ runner.os }}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			w, errs := Parse([]byte("on: push" + tc.input + "\n"))
+			if len(errs) > 0 {
+				t.Fatal(errs)
+			}
+
+			testOptionalCheck(t, "unused-step-output", SeverityWarning, tc.want, func(cfg *Config) []*Error {
+				return visitWithRule(t, w, NewRuleExpression(nil, nil), cfg)
+			})
+		})
+	}
+}
+
+func TestRuleExpressionMixedTypeComparison(t *testing.T) {
+	src := `on:
+  workflow_dispatch:
+    inputs:
+      retries:
+        type: number
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    strategy:
+      matrix:
+        os: [ubuntu-latest, macos-latest]
+    steps:
+      - run: echo retry
+        if: ${{ inputs.retries == '3' }}
+      - run: echo first
+        if: ${{ strategy.job-index == 0 && inputs.retries > 0 }}
+      - run: echo ${{ '1' < strategy.job-total }}
+`
+	w, errs := Parse([]byte(src))
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+
+	want := []string{
+		`:14:17: number value is compared with string value by "==" operator`,
+		`:17:23: string value is compared with number value by "<" operator`,
+	}
+
+	testOptionalCheck(t, "mixed-type-comparison", SeverityWarning, want, func(cfg *Config) []*Error {
+		return visitWithRule(t, w, NewRuleExpression(nil, nil), cfg)
+	})
+}
+
+func TestRuleExpressionEnvAsCondition(t *testing.T) {
+	testCases := []struct {
+		cond string
+		want bool
+	}{
+		{"env.FLAG", true},
+		{"${{ env.FLAG }}", true},
+		{"'!env.FLAG'", true},
+		{"always() && env.FLAG", true},
+		{"env.FLAG || env.OTHER", true},
+		{"env['FLAG']", true},
+		{"ENV.FLAG", true},
+		{"env.FLAG == 'true'", false},
+		{"${{ env.FLAG != 'false' }}", false},
+		{"contains(env.FLAG, 'yes')", false},
+		{"github.event.inputs.flag", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.cond, func(t *testing.T) {
+			src := "on: push\nenv:\n  FLAG: false\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo\n        if: " + tc.cond + "\n"
+			w, errs := Parse([]byte(src))
+			if len(errs) > 0 {
+				t.Fatal(errs)
+			}
+
+			r := NewRuleExpression(nil, nil)
+			v := NewVisitor()
+			v.AddPass(r)
+			if err := v.Visit(w); err != nil {
+				t.Fatal(err)
+			}
+
+			errs = []*Error{}
+			for _, err := range r.Errs() {
+				if strings.Contains(err.Message, "uses environment variable in \"env\" context as a boolean") {
+					errs = append(errs, err)
+				}
+			}
+
+			if !tc.want {
+				if len(errs) > 0 {
+					t.Fatalf("unexpected errors: %v", errs)
+				}
+				return
+			}
+			if len(errs) == 0 {
+				t.Fatalf("no error was reported for condition %q: %v", tc.cond, r.Errs())
+			}
+			for _, err := range errs {
+				if err.Line != 9 || err.Column != 13 {
+					t.Errorf("error should be reported at the condition at line:9,col:13 but got %s", err)
+				}
+				if err.Severity != SeverityWarning {
+					t.Errorf("severity of error should be warning but got %s: %s", err.Severity, err)
+				}
+			}
+		})
+	}
+}
+
+func TestRuleExpressionEventNameComparison(t *testing.T) {
+	testCases := []struct {
+		cond string
+		want string
+	}{
+		{"github.event_name == 'pull-request'", `"pull-request" compared with "github.event_name" is not a known event name. "github.event_name" is never equal to it. did you mean "pull_request"?`},
+		{"${{ 'pushh' != github.event_name }}", `"pushh" compared with "github.event_name" is not a known event name`},
+		{"github.event_name == 'push'", ""},
+		{"github.event_name == 'Push'", ""},
+		{"github.event_name == 'PULL_REQUEST_TARGET'", ""},
+		{"github.event_name == 'schedule' || github.event_name == 'workflow_call'", ""},
+		{"github.ref_name == 'pull-request'", ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.cond, func(t *testing.T) {
+			src := "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo\n        if: " + tc.cond + "\n"
+			w, errs := Parse([]byte(src))
+			if len(errs) > 0 {
+				t.Fatal(errs)
+			}
+
+			r := NewRuleExpression(nil, nil)
+			v := NewVisitor()
+			v.AddPass(r)
+			if err := v.Visit(w); err != nil {
+				t.Fatal(err)
+			}
+			errs = r.Errs()
+
+			if tc.want == "" {
+				if len(errs) > 0 {
+					t.Fatalf("unexpected errors: %v", errs)
+				}
+				return
+			}
+			if len(errs) != 1 {
+				t.Fatalf("wanted exactly one error but got %d errors: %v", len(errs), errs)
+			}
+			err := errs[0]
+			if !strings.Contains(err.Message, tc.want) {
+				t.Fatalf("error message %q does not contain %q", err.Message, tc.want)
+			}
+			if err.Severity != SeverityWarning {
+				t.Errorf("severity of error should be warning but got %s: %s", err.Severity, err)
+			}
+		})
+	}
+}
+
+func TestRuleExpressionStatusFuncCombination(t *testing.T) {
+	testCases := []struct {
+		cond string
+		want string
+	}{
+		{"github.ref == 'refs/heads/main' || always()", "is always true because always() is an operand of \"||\" operator"},
+		{"always() || github.ref == 'refs/heads/main'", "is always true because always() is an operand of \"||\" operator"},
+		{"${{ failure() || always() }}", "is always true because always() is an operand of \"||\" operator"},
+		{"github.event_name == 'push' && (failure() || always())", "is always true because always() is an operand of \"||\" operator"},
+		{"success() && always()", "is meaningless because it is combined with success() by \"&&\" operator"},
+		{"always() && failure()", "is meaningless because it is combined with failure() by \"&&\" operator"},
+		{"Always() && github.ref == 'refs/heads/main' && cancelled()", "is meaningless because it is combined with cancelled() by \"&&\" operator"},
+		{"success() && failure()", "is never true because success() and failure() combined by \"&&\" operator"},
+		{"${{ cancelled() && success() }}", "is never true because success() and cancelled() combined by \"&&\" operator"},
+		{"always()", ""},
+		{"always() && github.ref == 'refs/heads/main'", ""},
+		{"always() && (failure() || github.ref == 'refs/heads/main')", ""},
+		{"success() || failure()", ""},
+		{"failure() && !cancelled()", ""},
+		{"'!always() || success()'", ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.cond, func(t *testing.T) {
+			src := "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo\n        if: " + tc.cond + "\n"
+			w, errs := Parse([]byte(src))
+			if len(errs) > 0 {
+				t.Fatal(errs)
+			}
+
+			r := NewRuleExpression(nil, nil)
+			v := NewVisitor()
+			v.AddPass(r)
+			if err := v.Visit(w); err != nil {
+				t.Fatal(err)
+			}
+			errs = r.Errs()
+
+			if tc.want == "" {
+				if len(errs) > 0 {
+					t.Fatalf("unexpected errors: %v", errs)
+				}
+				return
+			}
+			if len(errs) != 1 {
+				t.Fatalf("wanted exactly one error but got %d errors: %v", len(errs), errs)
+			}
+			err := errs[0]
+			if !strings.Contains(err.Message, tc.want) {
+				t.Fatalf("error message %q does not contain %q", err.Message, tc.want)
+			}
+			if err.Line != 7 || err.Column != 13 {
+				t.Errorf("error should be reported at the condition at line:7,col:13 but got %s", err)
+			}
+			if err.Severity != SeverityWarning {
+				t.Errorf("severity of error should be warning but got %s: %s", err.Severity, err)
+			}
+		})
+	}
+}
+
+func TestRuleExpressionFailureAfterContinueOnErrorIsInfo(t *testing.T) {
+	src := `on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make test
+        continue-on-error: true
+      - run: echo 'tests failed'
+        if: failure()
+`
+	w, errs := Parse([]byte(src))
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+
+	r := NewRuleExpression(nil, nil)
+	v := NewVisitor()
+	v.AddPass(r)
+	if err := v.Visit(w); err != nil {
+		t.Fatal(err)
+	}
+
+	errs = r.Errs()
+	if len(errs) != 1 {
+		t.Fatalf("wanted 1 error but got %d errors: %v", len(errs), errs)
+	}
+	if !strings.Contains(errs[0].Message, "does not detect failure of the step at line 6") {
+		t.Fatalf("unexpected error message: %s", errs[0])
+	}
+	if errs[0].Severity != SeverityInfo {
+		t.Fatalf("severity should be info but got %s", errs[0].Severity)
+	}
+}
+
+func TestRuleExpressionSecretsInPullRequest(t *testing.T) {
+	testCases := []struct {
+		what  string
+		on    string
+		input string
+		want  []string
+	}{
+		{
+			what:  "secret in pull_request workflow",
+			on:    "pull_request",
+			input: "${{ secrets.DEPLOY_KEY }}",
+			want:  []string{`:6:24: "secrets.deploy_key" is referred in the workflow triggered by "pull_request" event at line 1`},
+		},
+		{
+			what:  "index access",
+			on:    "pull_request",
+			input: "${{ secrets['NPM_TOKEN'] }}",
+			want:  []string{`:6:24: "secrets.npm_token" is referred in the workflow triggered by "pull_request" event`},
+		},
+		{
+			what:  "multiple events",
+			on:    "[push, pull_request]",
+			input: "${{ secrets.TOKEN || 'default' }}",
+			want:  []string{`"secrets.token" is referred`},
+		},
+		{
+			what:  "multiple secrets",
+			on:    "pull_request",
+			input: "${{ secrets.FOO }} ${{ format('{0}', secrets.BAR) }}",
+			want: []string{
+				`:6:24: "secrets.foo" is referred`,
+				`:6:57: "secrets.bar" is referred`,
+			},
+		},
+		{
+			what:  "GITHUB_TOKEN",
+			on:    "pull_request",
+			input: "${{ secrets.GITHUB_TOKEN }} ${{ secrets['github_token'] }}",
+		},
+		{
+			what:  "pull_request_target",
+			on:    "pull_request_target",
+			input: "${{ secrets.DEPLOY_KEY }}",
+		},
+		{
+			what:  "push",
+			on:    "push",
+			input: "${{ secrets.DEPLOY_KEY }}",
+		},
+		{
+			what:  "dynamic index",
+			on:    "pull_request",
+			input: "${{ secrets[github.event.inputs.name] }}",
+		},
+		{
+			what:  "no secret",
+			on:    "pull_request",
+			input: "${{ github.event.pull_request.number }}",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			src := "on: " + tc.on + "\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo \"" + tc.input + "\"\n"
+			w, errs := Parse([]byte(src))
+			if len(errs) > 0 {
+				t.Fatal(errs)
+			}
+
+			testOptionalCheck(t, "secrets-in-pull-request", SeverityInfo, tc.want, func(cfg *Config) []*Error {
+				return visitWithRule(t, w, NewRuleExpression(nil, nil), cfg)
+			})
+		})
+	}
+}
+
+func TestRuleExpressionConfiguredUntrustedInputs(t *testing.T) {
+	cfg := &Config{}
+	cfg.UntrustedInputs.Add = []string{"github.event.release.name"}
+	cfg.UntrustedInputs.Remove = []string{"github.head_ref"}
+
+	testCases := []struct {
+		what  string
+		input string
+		want  string
+	}{
+		{
+			what:  "added path",
+			input: "${{ github.event.release.name }}",
+			want:  `:6:24: "github.event.release.name" is potentially untrusted`,
+		},
+		{
+			what:  "removed built-in path",
+			input: "${{ github.head_ref }}",
+		},
+		{
+			what:  "other built-in path",
+			input: "${{ github.event.pull_request.head.ref }}",
+			want:  `:6:24: "github.event.pull_request.head.ref" is potentially untrusted`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			src := "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo \"" + tc.input + "\"\n"
+			w, errs := Parse([]byte(src))
+			if len(errs) > 0 {
+				t.Fatal(errs)
+			}
+
+			r := NewRuleExpression(nil, nil)
+			r.SetConfig(cfg)
+			v := NewVisitor()
+			v.AddPass(r)
+			if err := v.Visit(w); err != nil {
+				t.Fatal(err)
+			}
+
+			errs = r.Errs()
+			if tc.want == "" {
+				if len(errs) > 0 {
+					t.Fatalf("wanted no error but got %v", errs)
+				}
+				return
+			}
+			if len(errs) != 1 {
+				t.Fatalf("wanted 1 error but got %d errors: %v", len(errs), errs)
+			}
+			if msg := errs[0].Error(); !strings.Contains(msg, tc.want) {
+				t.Fatalf("error %q does not contain %q", msg, tc.want)
+			}
+		})
+	}
+}
+
+func TestRuleExpressionRunNameContextAvailability(t *testing.T) {
+	testCases := []struct {
+		input string
+		want  string
+	}{
+		{input: "${{ github.actor }}"},
+		{input: "Deploy ${{ inputs.target }} by ${{ github.actor }}"},
+		{
+			input: "${{ env.X }}",
+			want:  `:6:16: context "env" is not allowed here. available contexts are "github", "inputs"`,
+		},
+		{
+			input: "${{ secrets.TOKEN }}",
+			want:  `:6:16: context "secrets" is not allowed here. available contexts are "github", "inputs"`,
+		},
+		{
+			input: "${{ matrix.os }}",
+			want:  `:6:16: context "matrix" is not allowed here. available contexts are "github", "inputs"`,
+		},
+		{
+			input: "Deploy by ${{ github.actor }} on ${{ runner.os }}",
+			want:  `:6:49: context "runner" is not allowed here. available contexts are "github", "inputs"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			src := "on:\n  workflow_dispatch:\n    inputs:\n      target:\n        type: string\n" +
+				"run-name: \"" + tc.input + "\"\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo\n"
+			w, errs := Parse([]byte(src))
+			if len(errs) > 0 {
+				t.Fatal(errs)
+			}
+
+			r := NewRuleExpression(nil, nil)
+			v := NewVisitor()
+			v.AddPass(r)
+			if err := v.Visit(w); err != nil {
+				t.Fatal(err)
+			}
+
+			errs = r.Errs()
+			if tc.want == "" {
+				if len(errs) > 0 {
+					t.Fatalf("wanted no error but got %v", errs)
+				}
+				return
+			}
+			if len(errs) == 0 {
+				t.Fatal("wanted an error but got no error")
+			}
+			if msg := errs[0].Error(); !strings.Contains(msg, tc.want) {
+				t.Fatalf("error %q does not contain %q", msg, tc.want)
+			}
+		})
+	}
+}
+
+func TestRuleExpressionWorkflowCallInputsTypes(t *testing.T) {
+	testCases := []struct {
+		what string
+		step string
+		want string
+	}{
+		{
+			what: "bool and number inputs in condition",
+			step: "if: ${{ inputs.flag && inputs.count > 1 }}",
+		},
+		{
+			what: "number input as number",
+			step: "timeout-minutes: ${{ inputs.count }}",
+		},
+		{
+			what: "string input as number",
+			step: "timeout-minutes: ${{ inputs.name }}",
+			want: "must be number but found type string",
+		},
+		{
+			what: "bool input as bool",
+			step: "continue-on-error: ${{ inputs.flag }}",
+		},
+		{
+			what: "number input as bool",
+			step: "continue-on-error: ${{ inputs.count }}",
+			want: "must be bool but found type number",
+		},
+		{
+			what: "string input as function argument",
+			step: "if: ${{ startsWith(inputs.name, 'v') }}",
+		},
+		{
+			what: "property of number input",
+			step: "run: echo ${{ inputs.count.foo }}",
+			want: `receiver of object dereference "foo" must be type of object but got "number"`,
+		},
+		{
+			what: "property of bool input",
+			step: "run: echo ${{ inputs.flag.foo }}",
+			want: `receiver of object dereference "foo" must be type of object but got "bool"`,
+		},
+		{
+			what: "property of input without type",
+			step: "run: echo ${{ inputs.untyped.foo.bar }}",
+		},
+		{
+			what: "undefined input",
+			step: "run: echo ${{ inputs.unknown }}",
+			want: `property "unknown" is not defined in object type {count: number; flag: bool; name: string; untyped: any}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			src := `on:
+  workflow_call:
+    inputs:
+      count:
+        type: number
+      flag:
+        type: boolean
+      name:
+        type: string
+      untyped:
+        description: type is missing
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - ` + tc.step + "\n"
+			if !strings.HasPrefix(tc.step, "run:") {
+				src += "        run: echo\n"
+			}
+			// Ignore the syntax error of the input without type
+			w, _ := Parse([]byte(src))
+			if w == nil {
+				t.Fatal("workflow was not parsed")
+			}
+
+			r := NewRuleExpression(nil, nil)
+			v := NewVisitor()
+			v.AddPass(r)
+			if err := v.Visit(w); err != nil {
+				t.Fatal(err)
+			}
+
+			errs := r.Errs()
+			if tc.want == "" {
+				if len(errs) > 0 {
+					t.Fatalf("wanted no error but got %v", errs)
+				}
+				return
+			}
+			if len(errs) != 1 {
+				t.Fatalf("wanted 1 error but got %d errors: %v", len(errs), errs)
+			}
+			if msg := errs[0].Error(); !strings.Contains(msg, tc.want) {
+				t.Fatalf("error %q does not contain %q", msg, tc.want)
+			}
+		})
+	}
+}
+
+func TestRuleExpressionUnusedWorkflowCallInput(t *testing.T) {
+	testCases := []struct {
+		what    string
+		inputs  string
+		secrets string
+		job     string
+		want    []string
+	}{
+		{
+			what:    "all used",
+			inputs:  "foo:\n        type: string\n      bar:\n        type: number\n        required: true",
+			secrets: "token:\n        required: true",
+			job:     "steps:\n      - run: echo ${{ inputs.foo }}\n        if: inputs.bar > 0\n        env:\n          TOKEN: ${{ secrets.TOKEN }}",
+		},
+		{
+			what:    "unused input and secret",
+			inputs:  "foo:\n        type: string\n      bar:\n        type: number",
+			secrets: "token:\n        required: false",
+			job:     "steps:\n      - run: echo ${{ inputs.foo }}",
+			want: []string{
+				`input "bar" of workflow_call event is never used in the workflow. remove the input`,
+				`secret "token" of workflow_call event is never used in the workflow. remove the secret`,
+			},
+		},
+		{
+			what:    "unused required input and secret",
+			inputs:  "foo:\n        type: string\n        required: true",
+			secrets: "token:\n        required: true",
+			job:     "steps:\n      - run: echo",
+			want: []string{
+				`input "foo" of workflow_call event is never used in the workflow. callers must pass the value though it is not used.`,
+				`secret "token" of workflow_call event is never used in the workflow. callers must pass the value though it is not used.`,
+			},
+		},
+		{
+			what:   "input used by default value of other input",
+			inputs: "foo:\n        type: string\n      bar:\n        type: string\n        default: ${{ inputs.foo }}",
+			job:    "steps:\n      - run: echo ${{ inputs.bar }}",
+		},
+		{
+			what:   "input used by index access",
+			inputs: "foo:\n        type: string",
+			job:    "steps:\n      - run: echo ${{ inputs['foo'] }}",
+		},
+		{
+			what:    "whole objects are used",
+			inputs:  "foo:\n        type: string",
+			secrets: "token:\n        required: true",
+			job:     "steps:\n      - run: echo '${{ toJSON(inputs) }}'\n        env:\n          ALL: ${{ toJSON(secrets) }}",
+		},
+		{
+			what:    "secrets are inherited",
+			secrets: "token:\n        required: true",
+			job:     "uses: owner/repo/.github/workflows/x.yml@main\n    secrets: inherit",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			src := "on:\n  workflow_call:\n"
+			if tc.inputs != "" {
+				src += "    inputs:\n      " + tc.inputs + "\n"
+			}
+			if tc.secrets != "" {
+				src += "    secrets:\n      " + tc.secrets + "\n"
+			}
+			src += "jobs:\n  test:\n"
+			if !strings.HasPrefix(tc.job, "uses:") {
+				src += "    runs-on: ubuntu-latest\n"
+			}
+			src += "    " + tc.job + "\n"
+
+			w, errs := Parse([]byte(src))
+			if len(errs) > 0 {
+				t.Fatal(errs)
+			}
+
+			testOptionalCheck(t, "unused-workflow-call-input", SeverityInfo, tc.want, func(cfg *Config) []*Error {
+				return visitWithRule(t, w, NewRuleExpression(nil, NewLocalReusableWorkflowCache(nil, "", nil)), cfg)
+			})
+		})
+	}
+}
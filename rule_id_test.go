@@ -104,3 +104,38 @@ func TestCheckValidJobNames(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckDuplicateStepIDs(t *testing.T) {
+	job := &Job{
+		ID: &String{Value: "test", Pos: &Pos{Line: 3, Col: 3}},
+		Steps: []*Step{
+			{ID: &String{Value: "build", Pos: &Pos{Line: 6, Col: 13}}},
+			{ID: &String{Value: "test", Pos: &Pos{Line: 8, Col: 13}}},
+			{ID: &String{Value: "BUILD", Pos: &Pos{Line: 10, Col: 13}}},
+		},
+	}
+	other := &Job{
+		ID: &String{Value: "other", Pos: &Pos{Line: 12, Col: 3}},
+		Steps: []*Step{
+			{ID: &String{Value: "build", Pos: &Pos{Line: 15, Col: 13}}},
+		},
+	}
+
+	r := NewRuleID()
+	for _, j := range []*Job{job, other} {
+		r.VisitJobPre(j)
+		for _, s := range j.Steps {
+			r.VisitStep(s)
+		}
+		r.VisitJobPost(j)
+	}
+
+	errs := r.Errs()
+	if len(errs) != 1 {
+		t.Fatalf("Wanted exactly one error but got %d errors: %v", len(errs), errs)
+	}
+	want := `:10:13: step ID "BUILD" duplicates. previously defined at line:6,col:13`
+	if msg := errs[0].Error(); !strings.Contains(msg, want) {
+		t.Errorf("Error message %q should contain %q", msg, want)
+	}
+}
@@ -1,7 +1,8 @@
 package actionlint
 
 import (
-	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -21,11 +22,6 @@ type jobNode struct {
 	pos      *Pos
 }
 
-type edge struct {
-	from *jobNode
-	to   *jobNode
-}
-
 // RuleJobNeeds is a rule to check 'needs' field in each job configuration. For more details, see
 // https://docs.github.com/en/actions/learn-github-actions/workflow-syntax-for-github-actions#jobsjob_idneeds
 type RuleJobNeeds struct {
@@ -86,84 +82,77 @@ func (rule *RuleJobNeeds) VisitJobPre(n *Job) error {
 
 // VisitWorkflowPost is callback when visiting Workflow node after visiting its children.
 func (rule *RuleJobNeeds) VisitWorkflowPost(n *Workflow) error {
-	// Resolve nodes
-	valid := true
+	// Resolve nodes. Undefined jobs are skipped so that cyclic dependencies among the other jobs
+	// are still detected.
 	for id, node := range rule.nodes {
 		node.resolved = make([]*jobNode, 0, len(node.needs))
 		for _, dep := range node.needs {
 			n, ok := rule.nodes[dep]
 			if !ok {
 				rule.errorf(node.pos, "job %q needs job %q which does not exist in this workflow", id, dep)
-				valid = false
 				continue
 			}
 			node.resolved = append(node.resolved, n)
 		}
 	}
-	if !valid {
-		return nil
-	}
-
-	if edge := detectCyclic(rule.nodes); edge != nil {
-		edges := map[string]string{}
-		edges[edge.from.id] = edge.to.id
-		collectCyclic(edge.to, edges)
 
-		desc := make([]string, 0, len(edges))
-		for from, to := range edges {
-			desc = append(desc, fmt.Sprintf("%q -> %q", from, to))
+	if cycle := detectCyclic(rule.nodes); cycle != nil {
+		desc := make([]string, 0, len(cycle))
+		for _, n := range cycle {
+			desc = append(desc, strconv.Quote(n.id))
 		}
 
+		// Report the error at the job which closes the cycle
 		rule.errorf(
-			edge.from.pos,
+			cycle[len(cycle)-2].pos,
 			"cyclic dependencies in \"needs\" configurations of jobs are detected. detected cycle is %s",
-			strings.Join(desc, ", "),
+			strings.Join(desc, " -> "),
 		)
 	}
 
 	return nil
 }
 
-func collectCyclic(src *jobNode, edges map[string]string) bool {
-	for _, dest := range src.resolved {
-		if dest.status != nodeStatusActive {
-			continue
-		}
-		edges[src.id] = dest.id
-		if _, ok := edges[dest.id]; ok {
-			return true
-		}
-		if collectCyclic(dest, edges) {
-			return true
-		}
-		delete(edges, src.id)
-	}
-	return false
-}
-
 // Detect cyclic dependencies
 // https://inzkyk.xyz/algorithms/depth_first_search/detecting_cycles/
 
-func detectCyclic(nodes map[string]*jobNode) *edge {
-	for _, v := range nodes {
-		if v.status == nodeStatusNew {
-			if e := detectCyclicNode(v); e != nil {
-				return e
+// detectCyclic returns the first detected cycle as a path of job nodes. The first and the last
+// elements of the path are the same node. Job "a" which needs itself is a cycle "a" -> "a".
+func detectCyclic(nodes map[string]*jobNode) []*jobNode {
+	// Visit nodes in order of job IDs to make the detected cycle stable
+	ids := make([]string, 0, len(nodes))
+	for id := range nodes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		if v := nodes[id]; v.status == nodeStatusNew {
+			if c := detectCyclicNode(v, []*jobNode{}); c != nil {
+				return c
 			}
 		}
 	}
 	return nil
 }
 
-func detectCyclicNode(v *jobNode) *edge {
+func detectCyclicNode(v *jobNode, path []*jobNode) []*jobNode {
 	v.status = nodeStatusActive
+	path = append(path, v)
 	for _, w := range v.resolved {
 		switch w.status {
 		case nodeStatusActive:
-			return &edge{v, w}
+			// Active node is always in the current path
+			for i, n := range path {
+				if n == w {
+					c := make([]*jobNode, 0, len(path)-i+1)
+					c = append(c, path[i:]...)
+					return append(c, w)
+				}
+			}
 		case nodeStatusNew:
-			if e := detectCyclicNode(w); e != nil {
-				return e
+			if c := detectCyclicNode(w, path); c != nil {
+				return c
 			}
 		}
 	}
@@ -0,0 +1,74 @@
+package actionlint
+
+import (
+	"sort"
+)
+
+// Maximum number of jobs in one workflow on GitHub Actions.
+// https://docs.github.com/en/actions/learn-github-actions/usage-limits-billing-and-administration#usage-limits
+const maxJobsInWorkflow = 256
+
+// RuleLimits is a rule to check the workflow does not exceed limits on GitHub Actions such as the
+// number of jobs in a workflow. The maximum number of steps in a job can also be checked by
+// configuring "limits.max-steps" in config file.
+type RuleLimits struct {
+	RuleBase
+}
+
+// NewRuleLimits creates new RuleLimits instance.
+func NewRuleLimits() *RuleLimits {
+	return &RuleLimits{
+		RuleBase: RuleBase{name: "limits"},
+	}
+}
+
+// VisitWorkflowPre is callback when visiting Workflow node before visiting its children.
+func (rule *RuleLimits) VisitWorkflowPre(n *Workflow) error {
+	if len(n.Jobs) <= maxJobsInWorkflow {
+		return nil
+	}
+
+	// Report the first job exceeding the limit in source order
+	jobs := make([]*Job, 0, len(n.Jobs))
+	for _, j := range n.Jobs {
+		if j.ID != nil {
+			jobs = append(jobs, j)
+		}
+	}
+	if len(jobs) <= maxJobsInWorkflow {
+		return nil
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].ID.Pos.IsBefore(jobs[j].ID.Pos)
+	})
+
+	rule.errorf(
+		jobs[maxJobsInWorkflow].ID.Pos,
+		"this workflow has %d jobs. it exceeds the maximum number of jobs in a workflow %d. split the workflow into multiple workflows",
+		len(n.Jobs),
+		maxJobsInWorkflow,
+	)
+	return nil
+}
+
+// VisitJobPre is callback when visiting Job node before visiting its children.
+func (rule *RuleLimits) VisitJobPre(n *Job) error {
+	cfg := rule.Config()
+	if cfg == nil || cfg.Limits.MaxSteps <= 0 || n.ID == nil {
+		return nil
+	}
+
+	max := cfg.Limits.MaxSteps
+	if len(n.Steps) <= max {
+		return nil
+	}
+
+	rule.warnf(
+		n.ID.Pos,
+		"job %q has %d steps. it exceeds the maximum number of steps in a job %d configured at \"limits.max-steps\". split the job into multiple jobs or move the steps into scripts or composite actions",
+		n.ID.Value,
+		len(n.Steps),
+		max,
+	)
+	return nil
+}
@@ -0,0 +1,126 @@
+package actionlint
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestRuleLimitsJobs(t *testing.T) {
+	testCases := []struct {
+		jobs int
+		want string
+	}{
+		{jobs: 1},
+		{jobs: 256},
+		{jobs: 257, want: "this workflow has 257 jobs. it exceeds the maximum number of jobs in a workflow 256"},
+		{jobs: 300, want: "this workflow has 300 jobs. it exceeds the maximum number of jobs in a workflow 256"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("%d jobs", tc.jobs), func(t *testing.T) {
+			var b strings.Builder
+			b.WriteString("on: push\njobs:\n")
+			for i := 0; i < tc.jobs; i++ {
+				fmt.Fprintf(&b, "  job%d:\n    runs-on: ubuntu-latest\n    steps:\n      - run: echo\n", i)
+			}
+			w, errs := Parse([]byte(b.String()))
+			if len(errs) > 0 {
+				t.Fatal(errs)
+			}
+
+			r := NewRuleLimits()
+			v := NewVisitor()
+			v.AddPass(r)
+			if err := v.Visit(w); err != nil {
+				t.Fatal(err)
+			}
+
+			errs = r.Errs()
+			if tc.want == "" {
+				if len(errs) > 0 {
+					t.Fatalf("wanted no error but got %v", errs)
+				}
+				return
+			}
+			if len(errs) != 1 {
+				t.Fatalf("wanted 1 error but got %v", errs)
+			}
+			err := errs[0]
+			if !strings.Contains(err.Message, tc.want) {
+				t.Fatalf("error %q does not contain %q", err.Message, tc.want)
+			}
+			// The 257th job is reported
+			if want := 3 + 256*4; err.Line != want {
+				t.Fatalf("wanted the error at line %d but got line %d", want, err.Line)
+			}
+		})
+	}
+}
+
+func TestRuleLimitsSteps(t *testing.T) {
+	testCases := []struct {
+		what  string
+		steps int
+		max   int
+		want  string
+	}{
+		{
+			what:  "not configured",
+			steps: 100,
+		},
+		{
+			what:  "equal to maximum",
+			steps: 10,
+			max:   10,
+		},
+		{
+			what:  "exceeds maximum",
+			steps: 11,
+			max:   10,
+			want:  `:3:3: job "test" has 11 steps. it exceeds the maximum number of steps in a job 10 configured at "limits.max-steps"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			var b strings.Builder
+			b.WriteString("on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n")
+			for i := 0; i < tc.steps; i++ {
+				fmt.Fprintf(&b, "      - run: echo %d\n", i)
+			}
+			w, errs := Parse([]byte(b.String()))
+			if len(errs) > 0 {
+				t.Fatal(errs)
+			}
+
+			r := NewRuleLimits()
+			cfg := &Config{}
+			cfg.Limits.MaxSteps = tc.max
+			r.SetConfig(cfg)
+
+			v := NewVisitor()
+			v.AddPass(r)
+			if err := v.Visit(w); err != nil {
+				t.Fatal(err)
+			}
+
+			errs = r.Errs()
+			if tc.want == "" {
+				if len(errs) > 0 {
+					t.Fatalf("wanted no error but got %v", errs)
+				}
+				return
+			}
+			if len(errs) != 1 {
+				t.Fatalf("wanted 1 error but got %v", errs)
+			}
+			if msg := errs[0].Error(); !strings.Contains(msg, tc.want) {
+				t.Fatalf("error %q does not contain %q", msg, tc.want)
+			}
+			if errs[0].Severity != SeverityWarning {
+				t.Fatalf("severity should be warning but got %s", errs[0].Severity)
+			}
+		})
+	}
+}
@@ -1,6 +1,10 @@
 package actionlint
 
-import "strings"
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
 
 // RuleMatrix is a rule checker to check 'matrix' field of job.
 type RuleMatrix struct {
@@ -16,7 +20,21 @@ func NewRuleMatrix() *RuleMatrix {
 
 // VisitJobPre is callback when visiting Job node before visiting its children.
 func (rule *RuleMatrix) VisitJobPre(n *Job) error {
-	if n.Strategy == nil || n.Strategy.Matrix == nil || n.Strategy.Matrix.Expression != nil {
+	if n.Strategy == nil {
+		return nil
+	}
+
+	rule.checkMaxParallel(n.Strategy)
+
+	if n.Strategy.Matrix == nil {
+		return nil
+	}
+
+	if rule.isCheckEnabled("matrix-environment") {
+		rule.checkMatrixEnvironment(n)
+	}
+
+	if n.Strategy.Matrix.Expression != nil {
 		return nil
 	}
 
@@ -37,6 +55,11 @@ func (rule *RuleMatrix) VisitJobPre(n *Job) error {
 	//       sh: pwsh
 
 	rule.checkExclude(m)
+	rule.checkIncludeKeys(m)
+	rule.checkJobCount(m)
+	if rule.isCheckEnabled("matrix-include") {
+		rule.checkIncludeWithExistingValues(m)
+	}
 	return nil
 }
 
@@ -124,10 +147,15 @@ func (rule *RuleMatrix) checkExclude(m *Matrix) {
 				for k := range vals {
 					ss = append(ss, k)
 				}
+				note := ""
+				if similar := findSimilarStrings(k, ss); len(similar) > 0 {
+					note = fmt.Sprintf(" did you mean %s?", quotes(similar))
+				}
 				rule.errorf(
 					a.Key.Pos,
-					"%q in \"exclude\" section does not exist in matrix. available matrix configurations are %s",
+					"%q in \"exclude\" section does not exist in matrix.%s available matrix configurations are %s",
 					k,
+					note,
 					sortedQuotes(ss),
 				)
 				continue
@@ -151,3 +179,365 @@ func (rule *RuleMatrix) checkExclude(m *Matrix) {
 		}
 	}
 }
+
+// checkIncludeKeys checks keys in "include" section which do not exist in the matrix but are similar
+// to existing keys like "platfrom" for "platform". "include" section can add new keys to the
+// matrix so such keys are not errors, but they are likely typos. The entries add new combinations
+// instead of extending the existing combinations.
+func (rule *RuleMatrix) checkIncludeKeys(m *Matrix) {
+	if m.Include == nil || len(m.Rows) == 0 {
+		return
+	}
+
+	keys := make([]string, 0, len(m.Rows))
+	for k := range m.Rows {
+		keys = append(keys, k)
+	}
+
+	for _, combi := range m.Include.Combinations {
+		if combi.Expression != nil {
+			continue
+		}
+		for k, a := range combi.Assigns {
+			if _, ok := m.Rows[k]; ok {
+				continue
+			}
+			similar := findSimilarStrings(k, keys)
+			if len(similar) == 0 {
+				continue
+			}
+			rule.warnf(
+				a.Key.Pos,
+				"%q in \"include\" section does not exist in matrix and it is added as a new key. did you mean %s? available matrix configurations are %s",
+				a.Key.Value,
+				quotes(similar),
+				sortedQuotes(keys),
+			)
+		}
+	}
+}
+
+// checkIncludeWithExistingValues checks entries in "include" section whose keys and values all exist
+// in the base matrix. Such entries only match existing combinations and neither add new
+// combinations nor add new values to existing ones. Authors often expect that they add new
+// combinations. This is an optional check enabled by "matrix-include".
+func (rule *RuleMatrix) checkIncludeWithExistingValues(m *Matrix) {
+	if m.Include == nil || len(m.Rows) == 0 {
+		return
+	}
+
+Entries:
+	for _, combi := range m.Include.Combinations {
+		if combi.Expression != nil || len(combi.Assigns) == 0 {
+			continue
+		}
+
+		var first *MatrixAssign
+		for k, a := range combi.Assigns {
+			row, ok := m.Rows[k]
+			if !ok || row.Expression != nil || !findYAMLValueInArray(row.Values, a.Value) {
+				continue Entries
+			}
+			if first == nil || a.Key.Pos.IsBefore(first.Key.Pos) {
+				first = a
+			}
+		}
+
+		rule.warnf(
+			first.Key.Pos,
+			"all keys and values of this entry in \"include\" section already exist in the base matrix. the entry only matches the existing combinations and neither adds a new combination nor adds new values to them. add a new key or a new value if it is not intended",
+		)
+	}
+}
+
+// Maximum number of jobs generated by one matrix on GitHub Actions.
+// https://docs.github.com/en/actions/using-jobs/using-a-matrix-for-your-jobs
+const defaultMaxMatrixJobs = 256
+
+// Matrices whose base combinations are more than this number are not expanded to count jobs.
+const maxExpandedMatrixCombinations = 65536
+
+// checkJobCount checks the number of jobs generated by the matrix does not exceed the maximum. The
+// maximum is 256 by default and can be configured with "matrix.max-jobs" in config file.
+func (rule *RuleMatrix) checkJobCount(m *Matrix) {
+	max := defaultMaxMatrixJobs
+	if cfg := rule.Config(); cfg != nil && cfg.Matrix.MaxJobs > 0 {
+		max = cfg.Matrix.MaxJobs
+	}
+
+	if matrixContainsExpression(m) {
+		rule.debug("Skip counting jobs of matrix at %s since it is constructed with expression", m.Pos)
+		return
+	}
+
+	count, exact := countMatrixJobs(m)
+	if count <= max {
+		return
+	}
+
+	at := ""
+	if !exact {
+		at = "at least "
+	}
+	rule.warnf(
+		m.Pos,
+		"this matrix generates %s%d jobs. it exceeds the maximum number of jobs per matrix %d. reduce the matrix values or split the job",
+		at,
+		count,
+		max,
+	)
+}
+
+// checkMaxParallel checks "max-parallel" is a positive integer and it does not exceed the number of
+// jobs generated by the matrix. Non-integer values and types of expressions are checked by parser
+// and "expression" rule.
+// https://docs.github.com/en/actions/using-workflows/workflow-syntax-for-github-actions#jobsjob_idstrategymax-parallel
+func (rule *RuleMatrix) checkMaxParallel(s *Strategy) {
+	p := s.MaxParallel
+	if p == nil || p.Expression != nil {
+		return
+	}
+
+	if p.Value == 0 {
+		rule.errorf(p.Pos, "\"max-parallel\" must be a positive integer but got 0. no job would be run")
+		return
+	}
+	if p.Value < 0 {
+		rule.errorf(p.Pos, "\"max-parallel\" must be a positive integer but got negative value %d", p.Value)
+		return
+	}
+
+	m := s.Matrix
+	if m == nil || m.Expression != nil || matrixContainsExpression(m) {
+		return
+	}
+	count, exact := countMatrixJobs(m)
+	if !exact || count == 0 || p.Value <= count {
+		return
+	}
+	rule.warnf(
+		p.Pos,
+		"\"max-parallel\" is %d but the matrix generates only %d jobs. limiting number of parallel jobs is meaningless. lower the value or remove \"max-parallel\"",
+		p.Value,
+		count,
+	)
+}
+
+// countMatrixJobs counts the number of jobs generated by the matrix considering "include" and
+// "exclude" sections. The matrix must not contain any expression. When the matrix is too large
+// to expand, this function returns a lower bound of the count and false as the second return value.
+func countMatrixJobs(m *Matrix) (int, bool) {
+	if len(m.Rows) == 0 {
+		if m.Include == nil {
+			return 0, true
+		}
+		return len(m.Include.Combinations), true
+	}
+
+	names := make([]string, 0, len(m.Rows))
+	for n := range m.Rows {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	total := 1
+	for _, n := range names {
+		total *= len(m.Rows[n].Values)
+		if total > maxExpandedMatrixCombinations {
+			return estimateMatrixJobs(m), false
+		}
+	}
+
+	var exclude, include []*MatrixCombination
+	if m.Exclude != nil {
+		exclude = m.Exclude.Combinations
+	}
+	if m.Include != nil {
+		include = m.Include.Combinations
+	}
+	included := make([]bool, len(include))
+
+	// Expand all combinations and apply "exclude" and "include" to each of them
+	count := 0
+	combi := make(map[string]RawYAMLValue, len(names))
+	for i := 0; i < total; i++ {
+		idx := i
+		for _, n := range names {
+			vs := m.Rows[n].Values
+			combi[n] = vs[idx%len(vs)]
+			idx /= len(vs)
+		}
+
+		excluded := false
+		for _, e := range exclude {
+			if matchMatrixCombination(combi, e, false) {
+				excluded = true
+				break
+			}
+		}
+		if excluded {
+			continue
+		}
+
+		count++
+		for j, c := range include {
+			if !included[j] && matchMatrixCombination(combi, c, true) {
+				included[j] = true
+			}
+		}
+	}
+
+	// Entries in "include" which match to no combination are added as new combinations
+	for _, ok := range included {
+		if !ok {
+			count++
+		}
+	}
+
+	return count, true
+}
+
+// matchMatrixCombination returns if the combination of "include" or "exclude" matches to the
+// expanded combination. When ignoreNewKeys is true, keys which do not exist in the matrix are
+// ignored since "include" can add new values to the combination.
+func matchMatrixCombination(combi map[string]RawYAMLValue, c *MatrixCombination, ignoreNewKeys bool) bool {
+	for k, a := range c.Assigns {
+		v, ok := combi[k]
+		if !ok {
+			if ignoreNewKeys {
+				continue
+			}
+			return false
+		}
+		if !v.Equals(a.Value) {
+			return false
+		}
+	}
+	return true
+}
+
+// estimateMatrixJobs returns a lower bound of the number of jobs generated by the large matrix
+// without expanding it. Every entry in "exclude" is assumed to remove all combinations it can
+// match and only entries in "include" with values not in the matrix are counted as new
+// combinations.
+func estimateMatrixJobs(m *Matrix) int {
+	const limit = 1 << 30 // Avoid overflow
+
+	total := 1
+	for _, r := range m.Rows {
+		total *= len(r.Values)
+		if total > limit {
+			total = limit
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+
+	count := total
+	if m.Exclude != nil {
+	Exclude:
+		for _, c := range m.Exclude.Combinations {
+			matched := total
+			for k, a := range c.Assigns {
+				r, ok := m.Rows[k]
+				if !ok || !findYAMLValueInArray(r.Values, a.Value) {
+					continue Exclude // This entry matches to no combination
+				}
+				matched /= len(r.Values)
+			}
+			count -= matched
+		}
+	}
+	if count < 0 {
+		count = 0
+	}
+
+	if m.Include != nil {
+	Include:
+		for _, c := range m.Include.Combinations {
+			for k, a := range c.Assigns {
+				if r, ok := m.Rows[k]; ok && !findYAMLValueInArray(r.Values, a.Value) {
+					count++
+					continue Include
+				}
+			}
+		}
+	}
+
+	return count
+}
+
+// checkMatrixEnvironment checks the environment of the job with matrix varies per matrix combination.
+// When all jobs generated by the matrix target the same environment, they deploy to the environment
+// in parallel. This is an optional check enabled by "matrix-environment".
+// https://docs.github.com/en/actions/deployment/targeting-different-environments/using-environments-for-deployment
+func (rule *RuleMatrix) checkMatrixEnvironment(job *Job) {
+	if job.Environment == nil || job.Environment.Name == nil {
+		return
+	}
+	if job.Concurrency != nil {
+		return // Parallel deployments are controlled by concurrency group
+	}
+	if p := job.Strategy.MaxParallel; p != nil && p.Expression == nil && p.Value == 1 {
+		return // Jobs run one by one
+	}
+
+	m := job.Strategy.Matrix
+	if m.Expression == nil && !matrixContainsExpression(m) {
+		if c, _ := countMatrixJobs(m); c <= 1 {
+			return
+		}
+	}
+
+	env := job.Environment.Name
+	if referencesContext(env.Value, "matrix") {
+		return
+	}
+
+	rule.warnf(
+		job.ID.Pos,
+		"all jobs generated by the matrix of job %q deploy to the same environment %q in parallel. it may conflict with the protection rules of the environment. refer matrix values in the environment name like \"${{ matrix.env }}\", or set \"concurrency\" or \"max-parallel: 1\"",
+		job.ID.Value,
+		env.Value,
+	)
+}
+
+// matrixContainsExpression returns if the matrix contains ${{ }} in its rows, "include" or "exclude".
+func matrixContainsExpression(m *Matrix) bool {
+	for _, row := range m.Rows {
+		if row.Expression != nil {
+			return true
+		}
+	}
+	return (m.Include != nil && m.Include.ContainsExpression()) || (m.Exclude != nil && m.Exclude.ContainsExpression())
+}
+
+// referencesContext returns if any ${{ }} placeholder in the string refers the context. When an
+// expression cannot be parsed, this function conservatively returns true.
+func referencesContext(s, ctx string) bool {
+	for {
+		i := strings.Index(s, "${{")
+		if i < 0 {
+			return false
+		}
+		s = s[i+3:]
+
+		l := NewExprLexer(s)
+		expr, err := NewExprParser().Parse(l)
+		if err != nil {
+			return true
+		}
+
+		found := false
+		VisitExprNode(expr, func(n, p ExprNode, entering bool) {
+			if v, ok := n.(*VariableNode); ok && entering && v.Name == ctx {
+				found = true
+			}
+		})
+		if found {
+			return true
+		}
+		s = s[l.Offset():]
+	}
+}
@@ -0,0 +1,640 @@
+package actionlint
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRuleMatrixIncludeWithExistingValues(t *testing.T) {
+	testCases := []struct {
+		what   string
+		matrix string
+		want   []string
+	}{
+		{
+			what: "include modifies existing combination",
+			matrix: `
+          os: [ubuntu-latest, windows-latest]
+          node: [14, 16]
+          include:
+            - os: windows-latest
+              node: 16`,
+			want: []string{":10:13: all keys and values of this entry in \"include\" section already exist in the base matrix"},
+		},
+		{
+			what: "include with part of keys",
+			matrix: `
+          os: [ubuntu-latest, windows-latest]
+          node: [14, 16]
+          include:
+            - node: 14`,
+			want: []string{":10:13: all keys and values of this entry"},
+		},
+		{
+			what: "multiple includes",
+			matrix: `
+          os: [ubuntu-latest, windows-latest]
+          include:
+            - os: ubuntu-latest
+            - os: macos-latest
+            - os: windows-latest`,
+			want: []string{
+				":9:13: all keys and values of this entry",
+				":11:13: all keys and values of this entry",
+			},
+		},
+		{
+			what: "include adds new combination with new value",
+			matrix: `
+          os: [ubuntu-latest, windows-latest]
+          node: [14, 16]
+          include:
+            - os: macos-latest
+              node: 16`,
+		},
+		{
+			what: "include adds new key to existing combinations",
+			matrix: `
+          os: [ubuntu-latest, windows-latest]
+          include:
+            - os: windows-latest
+              experimental: true`,
+		},
+		{
+			what: "include without base matrix",
+			matrix: `
+          include:
+            - os: ubuntu-latest
+            - os: windows-latest`,
+		},
+		{
+			what: "row is constructed with expression",
+			matrix: `
+          os: ${{ fromJSON(github.event.inputs.os) }}
+          include:
+            - os: ubuntu-latest`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			src := "on: push\njobs:\n  test:\n    runs-on: ${{ matrix.os }}\n    strategy:\n      matrix:" + tc.matrix + "\n    steps:\n      - run: echo\n"
+			// Remove the extra indentation in test cases
+			src = strings.ReplaceAll(src, "\n          ", "\n        ")
+			w, errs := Parse([]byte(src))
+			if len(errs) > 0 {
+				t.Fatal(errs)
+			}
+
+			testOptionalCheck(t, "matrix-include", SeverityWarning, tc.want, func(cfg *Config) []*Error {
+				return visitWithRule(t, w, NewRuleMatrix(), cfg)
+			})
+		})
+	}
+}
+
+func TestRuleMatrixUnknownKeys(t *testing.T) {
+	testCases := []struct {
+		what   string
+		matrix string
+		want   []string
+	}{
+		{
+			what: "typo in exclude key",
+			matrix: `
+          platform: [ubuntu-latest, windows-latest]
+          node: [14, 16]
+          exclude:
+            - platfrom: windows-latest
+              node: 14`,
+			want: []string{`:10:13: "platfrom" in "exclude" section does not exist in matrix. did you mean "platform"? available matrix configurations are "node", "platform" [matrix]`},
+		},
+		{
+			what: "unknown exclude key without similar key",
+			matrix: `
+          os: [ubuntu-latest, windows-latest]
+          exclude:
+            - arch: arm64`,
+			want: []string{`:9:13: "arch" in "exclude" section does not exist in matrix. available matrix configurations are "os" [matrix]`},
+		},
+		{
+			what: "exclude key added by include",
+			matrix: `
+          os: [ubuntu-latest, windows-latest]
+          include:
+            - os: macos-latest
+              arch: arm64
+          exclude:
+            - arch: arm64`,
+		},
+		{
+			what: "typo in include key",
+			matrix: `
+          platform: [ubuntu-latest, windows-latest]
+          node: [14, 16]
+          include:
+            - platfrom: macos-latest
+              node: 16`,
+			want: []string{`:10:13: "platfrom" in "include" section does not exist in matrix and it is added as a new key. did you mean "platform"? available matrix configurations are "node", "platform" [matrix]`},
+		},
+		{
+			what: "include adds new key",
+			matrix: `
+          os: [ubuntu-latest, windows-latest]
+          include:
+            - os: windows-latest
+              experimental: true`,
+		},
+		{
+			what: "include without base matrix",
+			matrix: `
+          include:
+            - os: ubuntu-latest
+            - oss: windows-latest`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			src := "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    strategy:\n      matrix:" + tc.matrix + "\n    steps:\n      - run: echo\n"
+			// Remove the extra indentation in test cases
+			src = strings.ReplaceAll(src, "\n          ", "\n        ")
+			w, errs := Parse([]byte(src))
+			if len(errs) > 0 {
+				t.Fatal(errs)
+			}
+
+			r := NewRuleMatrix()
+			v := NewVisitor()
+			v.AddPass(r)
+			if err := v.Visit(w); err != nil {
+				t.Fatal(err)
+			}
+
+			errs = r.Errs()
+			if len(errs) != len(tc.want) {
+				t.Fatalf("wanted %d errors but got %d errors: %v", len(tc.want), len(errs), errs)
+			}
+			for i, err := range errs {
+				if !strings.Contains(err.Error(), tc.want[i]) {
+					t.Errorf("error %q does not contain %q", err.Error(), tc.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRuleMatrixCountJobs(t *testing.T) {
+	testCases := []struct {
+		what   string
+		matrix string
+		count  int
+		exact  bool
+	}{
+		{
+			what: "single row",
+			matrix: `
+          os: [ubuntu-latest, windows-latest, macos-latest]`,
+			count: 3,
+			exact: true,
+		},
+		{
+			what: "multiple rows",
+			matrix: `
+          os: [ubuntu-latest, windows-latest, macos-latest]
+          node: [14, 16]
+          arch: [x64, arm64]`,
+			count: 12,
+			exact: true,
+		},
+		{
+			what: "exclude",
+			matrix: `
+          os: [ubuntu-latest, windows-latest]
+          node: [14, 16]
+          exclude:
+            - os: windows-latest
+              node: 14`,
+			count: 3,
+			exact: true,
+		},
+		{
+			what: "exclude with part of keys",
+			matrix: `
+          os: [ubuntu-latest, windows-latest]
+          node: [14, 16]
+          exclude:
+            - os: windows-latest`,
+			count: 2,
+			exact: true,
+		},
+		{
+			what: "overlapping excludes",
+			matrix: `
+          os: [ubuntu-latest, windows-latest]
+          node: [14, 16]
+          exclude:
+            - os: windows-latest
+            - node: 14`,
+			count: 1,
+			exact: true,
+		},
+		{
+			what: "include adds new combination",
+			matrix: `
+          os: [ubuntu-latest, windows-latest]
+          node: [14, 16]
+          include:
+            - os: macos-latest
+              node: 16`,
+			count: 5,
+			exact: true,
+		},
+		{
+			what: "include adds values to existing combinations",
+			matrix: `
+          os: [ubuntu-latest, windows-latest]
+          node: [14, 16]
+          include:
+            - os: windows-latest
+              experimental: true`,
+			count: 4,
+			exact: true,
+		},
+		{
+			what: "include matches to excluded combination",
+			matrix: `
+          os: [ubuntu-latest, windows-latest]
+          node: [14, 16]
+          exclude:
+            - os: windows-latest
+          include:
+            - os: windows-latest
+              node: 16`,
+			count: 3,
+			exact: true,
+		},
+		{
+			what: "include only",
+			matrix: `
+          include:
+            - os: ubuntu-latest
+            - os: windows-latest`,
+			count: 2,
+			exact: true,
+		},
+		{
+			what: "too large matrix to expand",
+			matrix: `
+          a: [1, 2, 3, 4, 5, 6, 7, 8, 9, 10]
+          b: [1, 2, 3, 4, 5, 6, 7, 8, 9, 10]
+          c: [1, 2, 3, 4, 5, 6, 7, 8, 9, 10]
+          d: [1, 2, 3, 4, 5, 6, 7, 8, 9, 10]
+          e: [1, 2, 3, 4, 5, 6, 7, 8, 9, 10]
+          exclude:
+            - a: 1
+          include:
+            - a: 11`,
+			count: 90001,
+			exact: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			src := "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    strategy:\n      matrix:" + tc.matrix + "\n    steps:\n      - run: echo\n"
+			src = strings.ReplaceAll(src, "\n          ", "\n        ")
+			w, errs := Parse([]byte(src))
+			if len(errs) > 0 {
+				t.Fatal(errs)
+			}
+
+			count, exact := countMatrixJobs(w.Jobs["test"].Strategy.Matrix)
+			if count != tc.count || exact != tc.exact {
+				t.Fatalf("wanted count=%d,exact=%v but got count=%d,exact=%v", tc.count, tc.exact, count, exact)
+			}
+		})
+	}
+}
+
+func TestRuleMatrixJobCountExceedsMax(t *testing.T) {
+	testCases := []struct {
+		what   string
+		matrix string
+		max    int
+		want   string
+	}{
+		{
+			what: "exceeds default maximum",
+			matrix: `
+          a: [1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17]
+          b: [1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16]`,
+			want: ":6:7: this matrix generates 272 jobs. it exceeds the maximum number of jobs per matrix 256",
+		},
+		{
+			what: "does not exceed default maximum",
+			matrix: `
+          a: [1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16]
+          b: [1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16]`,
+		},
+		{
+			what: "exceeds configured maximum",
+			matrix: `
+          os: [ubuntu-latest, windows-latest, macos-latest]
+          node: [14, 16, 18]`,
+			max:  8,
+			want: "this matrix generates 9 jobs. it exceeds the maximum number of jobs per matrix 8",
+		},
+		{
+			what: "exclude reduces jobs",
+			matrix: `
+          os: [ubuntu-latest, windows-latest, macos-latest]
+          node: [14, 16, 18]
+          exclude:
+            - os: macos-latest
+              node: 14`,
+			max: 8,
+		},
+		{
+			what: "large matrix",
+			matrix: `
+          a: [1, 2, 3, 4, 5, 6, 7, 8, 9, 10]
+          b: [1, 2, 3, 4, 5, 6, 7, 8, 9, 10]
+          c: [1, 2, 3, 4, 5, 6, 7, 8, 9, 10]
+          d: [1, 2, 3, 4, 5, 6, 7, 8, 9, 10]
+          e: [1, 2, 3, 4, 5, 6, 7, 8, 9, 10]`,
+			want: "this matrix generates at least 100000 jobs",
+		},
+		{
+			what: "row with expression",
+			matrix: `
+          a: ${{ fromJSON(inputs.a) }}
+          b: [1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17]`,
+			max: 10,
+		},
+		{
+			what: "include with expression",
+			matrix: `
+          a: [1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17]
+          include: ${{ fromJSON(inputs.include) }}`,
+			max: 10,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			src := "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    strategy:\n      matrix:" + tc.matrix + "\n    steps:\n      - run: echo\n"
+			src = strings.ReplaceAll(src, "\n          ", "\n        ")
+			w, errs := Parse([]byte(src))
+			if len(errs) > 0 {
+				t.Fatal(errs)
+			}
+
+			r := NewRuleMatrix()
+			cfg := &Config{}
+			cfg.Matrix.MaxJobs = tc.max
+			r.SetConfig(cfg)
+
+			v := NewVisitor()
+			v.AddPass(r)
+			if err := v.Visit(w); err != nil {
+				t.Fatal(err)
+			}
+
+			errs = r.Errs()
+			if tc.want == "" {
+				if len(errs) > 0 {
+					t.Fatalf("wanted no error but got %v", errs)
+				}
+				return
+			}
+			if len(errs) != 1 {
+				t.Fatalf("wanted 1 error but got %d errors: %v", len(errs), errs)
+			}
+			if !strings.Contains(errs[0].Error(), tc.want) {
+				t.Fatalf("error %q does not contain %q", errs[0].Error(), tc.want)
+			}
+		})
+	}
+}
+
+func TestRuleMatrixMaxParallel(t *testing.T) {
+	testCases := []struct {
+		what     string
+		strategy string
+		want     string
+	}{
+		{
+			what:     "zero",
+			strategy: "max-parallel: 0\n      matrix:\n        os: [ubuntu-latest, macos-latest]",
+			want:     `:6:21: "max-parallel" must be a positive integer but got 0`,
+		},
+		{
+			what:     "negative",
+			strategy: "max-parallel: -2\n      matrix:\n        os: [ubuntu-latest, macos-latest]",
+			want:     `:6:21: "max-parallel" must be a positive integer but got negative value -2`,
+		},
+		{
+			what:     "zero without matrix",
+			strategy: "max-parallel: 0",
+			want:     `:6:21: "max-parallel" must be a positive integer but got 0`,
+		},
+		{
+			what:     "exceeds matrix size",
+			strategy: "max-parallel: 4\n      matrix:\n        os: [ubuntu-latest, macos-latest]\n        include:\n          - os: windows-latest",
+			want:     `:6:21: "max-parallel" is 4 but the matrix generates only 3 jobs`,
+		},
+		{
+			what:     "exceeds matrix size with exclude",
+			strategy: "max-parallel: 4\n      matrix:\n        os: [ubuntu-latest, macos-latest]\n        node: [16, 18]\n        exclude:\n          - os: macos-latest\n            node: 16",
+			want:     `:6:21: "max-parallel" is 4 but the matrix generates only 3 jobs`,
+		},
+		{
+			what:     "equals matrix size",
+			strategy: "max-parallel: 2\n      matrix:\n        os: [ubuntu-latest, macos-latest]",
+		},
+		{
+			what:     "less than matrix size",
+			strategy: "max-parallel: 1\n      matrix:\n        os: [ubuntu-latest, macos-latest]",
+		},
+		{
+			what:     "matrix with expression",
+			strategy: "max-parallel: 4\n      matrix:\n        os: ${{ fromJSON(inputs.os) }}",
+		},
+		{
+			what:     "row with expression",
+			strategy: "max-parallel: 4\n      matrix:\n        os: [ubuntu-latest]\n        node: ${{ fromJSON(inputs.node) }}",
+		},
+		{
+			what:     "max-parallel with expression",
+			strategy: "max-parallel: ${{ inputs.max }}\n      matrix:\n        os: [ubuntu-latest]",
+		},
+		{
+			what:     "without matrix",
+			strategy: "max-parallel: 4",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			src := "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    strategy:\n      " + tc.strategy + "\n    steps:\n      - run: echo\n"
+			w, errs := Parse([]byte(src))
+			if len(errs) > 0 {
+				t.Fatal(errs)
+			}
+
+			r := NewRuleMatrix()
+			v := NewVisitor()
+			v.AddPass(r)
+			if err := v.Visit(w); err != nil {
+				t.Fatal(err)
+			}
+
+			errs = r.Errs()
+			if tc.want == "" {
+				if len(errs) > 0 {
+					t.Fatalf("wanted no error but got %v", errs)
+				}
+				return
+			}
+			if len(errs) != 1 {
+				t.Fatalf("wanted 1 error but got %v", errs)
+			}
+			if msg := errs[0].Error(); !strings.Contains(msg, tc.want) {
+				t.Fatalf("error %q does not contain %q", msg, tc.want)
+			}
+		})
+	}
+}
+
+func TestRuleMatrixEnvironment(t *testing.T) {
+	testCases := []struct {
+		what string
+		job  string
+		want []string
+	}{
+		{
+			what: "static environment name",
+			job: `
+    strategy:
+      matrix:
+        region: [us, eu]
+    environment: production`,
+			want: []string{`:3:3: all jobs generated by the matrix of job "deploy" deploy to the same environment "production" in parallel`},
+		},
+		{
+			what: "static environment name in object form",
+			job: `
+    strategy:
+      matrix:
+        region: [us, eu]
+    environment:
+      name: production
+      url: https://example.com`,
+			want: []string{`same environment "production" in parallel`},
+		},
+		{
+			what: "environment name with other context",
+			job: `
+    strategy:
+      matrix:
+        region: [us, eu]
+    environment: ${{ github.ref_name }}`,
+			want: []string{`same environment "${{ github.ref_name }}" in parallel`},
+		},
+		{
+			what: "matrix constructed with expression",
+			job: `
+    strategy:
+      matrix: ${{ fromJSON(needs.prepare.outputs.matrix) }}
+    environment: production`,
+			want: []string{`same environment "production" in parallel`},
+		},
+		{
+			what: "environment name varied by matrix",
+			job: `
+    strategy:
+      matrix:
+        env: [staging, production]
+    environment: ${{ matrix.env }}`,
+		},
+		{
+			what: "environment name partially varied by matrix",
+			job: `
+    strategy:
+      matrix:
+        region: [us, eu]
+    environment:
+      name: production-${{ matrix.region }}`,
+		},
+		{
+			what: "matrix in function call",
+			job: `
+    strategy:
+      matrix:
+        region: [us, eu]
+    environment: ${{ format('prod-{0}', matrix.region) }}`,
+		},
+		{
+			what: "matrix in second placeholder",
+			job: `
+    strategy:
+      matrix:
+        region: [us, eu]
+        stage: [a, b]
+    environment: ${{ github.ref_name }}-${{ matrix.stage }}`,
+		},
+		{
+			what: "single combination",
+			job: `
+    strategy:
+      matrix:
+        region: [us]
+    environment: production`,
+		},
+		{
+			what: "max-parallel is 1",
+			job: `
+    strategy:
+      max-parallel: 1
+      matrix:
+        region: [us, eu]
+    environment: production`,
+		},
+		{
+			what: "concurrency group",
+			job: `
+    strategy:
+      matrix:
+        region: [us, eu]
+    concurrency: production
+    environment: production`,
+		},
+		{
+			what: "no matrix",
+			job: `
+    environment: production`,
+		},
+		{
+			what: "no environment",
+			job: `
+    strategy:
+      matrix:
+        region: [us, eu]`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			src := "on: push\njobs:\n  deploy:\n    runs-on: ubuntu-latest" + tc.job + "\n    steps:\n      - run: echo\n"
+			w, errs := Parse([]byte(src))
+			if len(errs) > 0 {
+				t.Fatal(errs)
+			}
+
+			testOptionalCheck(t, "matrix-environment", SeverityWarning, tc.want, func(cfg *Config) []*Error {
+				return visitWithRule(t, w, NewRuleMatrix(), cfg)
+			})
+		})
+	}
+}
@@ -1,5 +1,10 @@
 package actionlint
 
+import (
+	"fmt"
+	"strings"
+)
+
 var allPermissionScopes = map[string]struct{}{
 	"actions":             {},
 	"checks":              {},
@@ -16,31 +21,253 @@ var allPermissionScopes = map[string]struct{}{
 	"statuses":            {},
 }
 
+// actionRequiredPermissions is a map from popular action names to permissions of GITHUB_TOKEN
+// which are required by the actions.
+var actionRequiredPermissions = map[string][]string{
+	"actions/checkout":                       {"contents: read"},
+	"actions/dependency-review-action":       {"contents: read"},
+	"actions/deploy-pages":                   {"pages: write", "id-token: write"},
+	"actions/first-interaction":              {"issues: write", "pull-requests: write"},
+	"actions/labeler":                        {"contents: read", "pull-requests: write"},
+	"actions/stale":                          {"issues: write", "pull-requests: write"},
+	"amannn/action-semantic-pull-request":    {"pull-requests: read"},
+	"dependabot/fetch-metadata":              {"pull-requests: read"},
+	"github/codeql-action/analyze":           {"security-events: write"},
+	"github/codeql-action/upload-sarif":      {"security-events: write"},
+	"marocchino/sticky-pull-request-comment": {"pull-requests: write"},
+	"peter-evans/create-pull-request":        {"contents: write", "pull-requests: write"},
+	"softprops/action-gh-release":            {"contents: write"},
+	"stefanzweifel/git-auto-commit-action":   {"contents: write"},
+}
+
 // RulePermissions is a rule checker to check permission configurations in a workflow.
 // https://docs.github.com/en/actions/security-guides/automatic-token-authentication#permissions-for-the-github_token
 type RulePermissions struct {
 	RuleBase
+	workflowPerms *Permissions
+	jobPerms      *Permissions
+	// Actions which require permissions in the current job for "empty-permissions" and
+	// "pull-requests-permission" optional checks
+	tokenActions []*ExecAction
 }
 
 // NewRulePermissions creates new RulePermissions instance.
 func NewRulePermissions() *RulePermissions {
 	return &RulePermissions{
-		RuleBase: RuleBase{name: "permissions"},
+		RuleBase:      RuleBase{name: "permissions"},
+		workflowPerms: nil,
+		jobPerms:      nil,
+		tokenActions:  nil,
 	}
 }
 
 // VisitJobPre is callback when visiting Job node before visiting its children.
 func (rule *RulePermissions) VisitJobPre(n *Job) error {
 	rule.checkPermissions(n.Permissions)
+	rule.jobPerms = n.Permissions
+	if rule.jobPerms == nil {
+		rule.jobPerms = rule.workflowPerms
+	}
+	rule.tokenActions = nil
+	return nil
+}
+
+// VisitJobPost is callback when visiting Job node after visiting its children.
+func (rule *RulePermissions) VisitJobPost(n *Job) error {
+	if rule.isCheckEnabled("empty-permissions") {
+		rule.checkEmptyPermissions(n)
+	}
+	if rule.isCheckEnabled("pull-requests-permission") {
+		rule.checkPullRequestsPermission(n)
+	}
+	rule.jobPerms = nil
+	rule.tokenActions = nil
+	return nil
+}
+
+// VisitStep is callback when visiting Step node.
+func (rule *RulePermissions) VisitStep(n *Step) error {
+	e, ok := n.Exec.(*ExecAction)
+	if !ok || e.Uses == nil {
+		return nil
+	}
+	if perms := rule.requiredPermissionsOfAction(e.Uses.Value); len(perms) == 0 {
+		return nil
+	}
+	// When other token is given explicitly, the action does not use GITHUB_TOKEN
+	for _, i := range []string{"token", "github-token", "repo-token"} {
+		if _, ok := e.Inputs[i]; ok {
+			return nil
+		}
+	}
+	rule.tokenActions = append(rule.tokenActions, e)
 	return nil
 }
 
 // VisitWorkflowPre is callback when visiting Workflow node before visiting its children.
 func (rule *RulePermissions) VisitWorkflowPre(n *Workflow) error {
 	rule.checkPermissions(n.Permissions)
+	rule.workflowPerms = n.Permissions
+	if rule.isCheckEnabled("missing-permissions") {
+		rule.checkMissingPermissions(n)
+	}
+	return nil
+}
+
+// VisitWorkflowPost is callback when visiting Workflow node after visiting its children.
+func (rule *RulePermissions) VisitWorkflowPost(n *Workflow) error {
+	rule.workflowPerms = nil
 	return nil
 }
 
+// checkMissingPermissions checks "permissions" section exists at top level of the workflow. When it
+// is omitted, jobs without their own "permissions" section are granted the default permissions,
+// which may be permissive depending on the repository settings. Note that "permissions: {}" is not
+// reported since it explicitly grants no permission. This is an optional check enabled by
+// "missing-permissions".
+func (rule *RulePermissions) checkMissingPermissions(n *Workflow) {
+	if n.Permissions != nil {
+		return
+	}
+
+	ids := []string{}
+	for _, j := range n.Jobs {
+		if j.Permissions == nil && j.ID != nil {
+			ids = append(ids, j.ID.Value)
+		}
+	}
+	if len(ids) == 0 {
+		return // All jobs configure their own permissions
+	}
+
+	rule.warnf(
+		&Pos{Line: 1, Col: 1},
+		"\"permissions\" section is missing at top level of the workflow so the default permissions of GITHUB_TOKEN are granted to jobs %s. add \"permissions\" section with the least privileges like \"permissions: { contents: read }\", or \"permissions: {}\" to grant no permission",
+		sortedQuotes(ids),
+	)
+}
+
+// checkEmptyPermissions checks the job whose permissions are empty like "permissions: {}" uses
+// actions which require some permissions of GITHUB_TOKEN. Whether the permissions are required
+// depends on visibility of the repository. For example, actions/checkout does not require
+// "contents: read" in public repositories. This is an optional check enabled by
+// "empty-permissions".
+func (rule *RulePermissions) checkEmptyPermissions(job *Job) {
+	if len(rule.tokenActions) == 0 || job.ID == nil || !isEmptyPermissions(rule.jobPerms) {
+		return
+	}
+
+	reqs := make([]string, 0, len(rule.tokenActions))
+	for _, e := range rule.tokenActions {
+		perms := rule.requiredPermissionsOfAction(e.Uses.Value)
+		reqs = append(reqs, fmt.Sprintf("%q requires %s", e.Uses.Value, quotes(perms)))
+	}
+
+	rule.warnf(
+		job.ID.Pos,
+		"permissions of GITHUB_TOKEN are empty in job %q but some actions in the job require permissions: %s. add the required permissions to \"permissions\" section",
+		job.ID.Value,
+		strings.Join(reqs, ", "),
+	)
+}
+
+// checkPullRequestsPermission checks the job grants "pull-requests: write" permission to
+// GITHUB_TOKEN when actions in the job require it. Actions to comment on pull requests or to add
+// labels to them require the permission and it is often forgotten. When no permission is
+// configured, the default permissions are used and they may be read-only depending on the
+// repository settings. This is an optional check enabled by "pull-requests-permission".
+func (rule *RulePermissions) checkPullRequestsPermission(job *Job) {
+	if len(rule.tokenActions) == 0 || job.ID == nil {
+		return
+	}
+	p := rule.jobPerms
+	if isEmptyPermissions(p) && rule.isCheckEnabled("empty-permissions") {
+		return // Already reported by "empty-permissions" check
+	}
+	if grantsPermission(p, "pull-requests", "write") {
+		return
+	}
+
+	actions := []string{}
+	for _, e := range rule.tokenActions {
+		for _, r := range rule.requiredPermissionsOfAction(e.Uses.Value) {
+			if r == "pull-requests: write" {
+				actions = append(actions, e.Uses.Value)
+				break
+			}
+		}
+	}
+	if len(actions) == 0 {
+		return
+	}
+
+	if p == nil {
+		rule.warnf(
+			job.ID.Pos,
+			"permissions of GITHUB_TOKEN are not configured in job %q so the default permissions are used. actions %s require \"pull-requests: write\" permission but the default permissions may be read-only. add \"pull-requests: write\" to \"permissions\" section",
+			job.ID.Value,
+			quotes(actions),
+		)
+		return
+	}
+	rule.warnf(
+		job.ID.Pos,
+		"\"pull-requests: write\" permission is not granted to GITHUB_TOKEN in job %q but actions %s require it. add \"pull-requests: write\" to \"permissions\" section",
+		job.ID.Value,
+		quotes(actions),
+	)
+}
+
+// isEmptyPermissions returns true when no permission is given like "permissions: {}".
+func isEmptyPermissions(p *Permissions) bool {
+	return p != nil && p.All == nil && len(p.Scopes) == 0
+}
+
+// grantsPermission returns true when the permissions explicitly grant the level of permission to
+// the scope. It returns false when the permissions are not configured.
+func grantsPermission(p *Permissions, scope, level string) bool {
+	if p == nil {
+		return false
+	}
+	if p.All != nil {
+		return p.All.Value == "write-all" || p.All.Value == "read-all" && level == "read"
+	}
+	for _, s := range p.Scopes {
+		if s.Name == nil || s.Value == nil || s.Name.Value != scope {
+			continue
+		}
+		return s.Value.Value == "write" || s.Value.Value == "read" && level == "read"
+	}
+	return false
+}
+
+// requiredPermissionsOfAction returns permissions of GITHUB_TOKEN required by the action. The
+// permissions configured at "permissions.actions" in user configuration take precedence over the
+// built-in table.
+func (rule *RulePermissions) requiredPermissionsOfAction(spec string) []string {
+	idx := strings.IndexRune(spec, '@')
+	if idx == -1 {
+		return nil
+	}
+	name := strings.ToLower(spec[:idx])
+	if rule.config != nil {
+		for a, ps := range rule.config.Permissions.Actions {
+			if strings.ToLower(a) != name {
+				continue
+			}
+			ret := make([]string, 0, len(ps))
+			for _, p := range ps {
+				if i := strings.IndexRune(p, ':'); i >= 0 {
+					p = strings.TrimSpace(p[:i]) + ": " + strings.TrimSpace(p[i+1:])
+				}
+				ret = append(ret, p)
+			}
+			return ret
+		}
+	}
+	return actionRequiredPermissions[name]
+}
+
 func (rule *RulePermissions) checkPermissions(p *Permissions) {
 	if p == nil {
 		return
@@ -63,7 +290,11 @@ func (rule *RulePermissions) checkPermissions(p *Permissions) {
 			for s := range allPermissionScopes {
 				ss = append(ss, s)
 			}
-			rule.errorf(p.Name.Pos, "unknown permission scope %q. all available permission scopes are %s", n, sortedQuotes(ss))
+			if similar := findSimilarStrings(n, ss); len(similar) > 0 {
+				rule.errorf(p.Name.Pos, "unknown permission scope %q. did you mean %s? all available permission scopes are %s", n, quotes(similar), sortedQuotes(ss))
+			} else {
+				rule.errorf(p.Name.Pos, "unknown permission scope %q. all available permission scopes are %s", n, sortedQuotes(ss))
+			}
 		}
 		switch p.Value.Value {
 		case "read", "write", "none":
@@ -0,0 +1,398 @@
+package actionlint
+
+import "testing"
+
+func TestRulePermissionsEmptyPermissions(t *testing.T) {
+	testCases := []struct {
+		what  string
+		input string
+		want  []string
+	}{
+		{
+			what: "empty permissions at job with checkout",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    permissions: {}
+    steps:
+      - uses: actions/checkout@v3`,
+			want: []string{
+				`:3:3: permissions of GITHUB_TOKEN are empty in job "test" but some actions in the job require permissions: "actions/checkout@v3" requires "contents: read"`,
+			},
+		},
+		{
+			what: "empty permissions at workflow with checkout",
+			input: `
+permissions: {}
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v3`,
+			want: []string{
+				`:4:3: permissions of GITHUB_TOKEN are empty in job "test"`,
+			},
+		},
+		{
+			what: "multiple actions require permissions",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    permissions: {}
+    steps:
+      - uses: actions/checkout@v3
+      - uses: actions/setup-node@v3
+      - uses: peter-evans/create-pull-request@v4`,
+			want: []string{
+				`"actions/checkout@v3" requires "contents: read", "peter-evans/create-pull-request@v4" requires "contents: write", "pull-requests: write"`,
+			},
+		},
+		{
+			what: "job permissions override empty workflow permissions",
+			input: `
+permissions: {}
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    permissions:
+      contents: read
+    steps:
+      - uses: actions/checkout@v3`,
+		},
+		{
+			what: "no permissions",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v3`,
+		},
+		{
+			what: "read-all permissions",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    permissions: read-all
+    steps:
+      - uses: actions/checkout@v3`,
+		},
+		{
+			what: "token is given explicitly",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    permissions: {}
+    steps:
+      - uses: actions/checkout@v3
+        with:
+          token: ${{ secrets.PAT }}`,
+		},
+		{
+			what: "actions not requiring permissions",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    permissions: {}
+    steps:
+      - uses: actions/setup-node@v3
+      - run: npm test`,
+		},
+		{
+			what: "empty permissions only in other job",
+			input: `
+jobs:
+  test1:
+    runs-on: ubuntu-latest
+    permissions: {}
+    steps:
+      - run: echo
+  test2:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v3`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			w, errs := Parse([]byte("on: push" + tc.input + "\n"))
+			if len(errs) > 0 {
+				t.Fatal(errs)
+			}
+
+			testOptionalCheck(t, "empty-permissions", SeverityWarning, tc.want, func(cfg *Config) []*Error {
+				return visitWithRule(t, w, NewRulePermissions(), cfg)
+			})
+		})
+	}
+}
+
+func TestRulePermissionsPullRequestsPermission(t *testing.T) {
+	testCases := []struct {
+		what    string
+		input   string
+		actions map[string][]string
+		want    []string
+	}{
+		{
+			what: "labeler without pull-requests permission",
+			input: `
+jobs:
+  label:
+    runs-on: ubuntu-latest
+    permissions:
+      contents: read
+    steps:
+      - uses: actions/labeler@v4`,
+			want: []string{
+				`:3:3: "pull-requests: write" permission is not granted to GITHUB_TOKEN in job "label" but actions "actions/labeler@v4" require it`,
+			},
+		},
+		{
+			what: "labeler with read permission",
+			input: `
+permissions:
+  pull-requests: read
+jobs:
+  label:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/labeler@v4`,
+			want: []string{
+				`:5:3: "pull-requests: write" permission is not granted to GITHUB_TOKEN in job "label"`,
+			},
+		},
+		{
+			what: "no permissions configured",
+			input: `
+jobs:
+  comment:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/labeler@v4
+      - uses: marocchino/sticky-pull-request-comment@v2`,
+			want: []string{
+				`:3:3: permissions of GITHUB_TOKEN are not configured in job "comment" so the default permissions are used. actions "actions/labeler@v4", "marocchino/sticky-pull-request-comment@v2" require "pull-requests: write" permission`,
+			},
+		},
+		{
+			what: "read-all permissions",
+			input: `
+permissions: read-all
+jobs:
+  label:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/labeler@v4`,
+			want: []string{
+				`:4:3: "pull-requests: write" permission is not granted`,
+			},
+		},
+		{
+			what: "labeler with write permission at job",
+			input: `
+jobs:
+  label:
+    runs-on: ubuntu-latest
+    permissions:
+      contents: read
+      pull-requests: write
+    steps:
+      - uses: actions/labeler@v4`,
+		},
+		{
+			what: "labeler with write permission at workflow",
+			input: `
+permissions:
+  pull-requests: write
+jobs:
+  label:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/labeler@v4`,
+		},
+		{
+			what: "write-all permissions",
+			input: `
+permissions: write-all
+jobs:
+  label:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/labeler@v4`,
+		},
+		{
+			what: "token is given explicitly",
+			input: `
+jobs:
+  label:
+    runs-on: ubuntu-latest
+    permissions:
+      contents: read
+    steps:
+      - uses: actions/labeler@v4
+        with:
+          repo-token: ${{ secrets.PAT }}`,
+		},
+		{
+			what: "actions not requiring pull-requests permission",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v3
+      - uses: softprops/action-gh-release@v1`,
+		},
+		{
+			what: "action added by configuration",
+			input: `
+jobs:
+  comment:
+    runs-on: ubuntu-latest
+    permissions:
+      contents: read
+    steps:
+      - uses: owner/pr-comment@v1`,
+			actions: map[string][]string{"owner/pr-comment": {"pull-requests:write"}},
+			want: []string{
+				`:3:3: "pull-requests: write" permission is not granted to GITHUB_TOKEN in job "comment" but actions "owner/pr-comment@v1" require it`,
+			},
+		},
+		{
+			what: "action overridden by configuration",
+			input: `
+jobs:
+  label:
+    runs-on: ubuntu-latest
+    permissions:
+      contents: read
+    steps:
+      - uses: actions/labeler@v4`,
+			actions: map[string][]string{"actions/labeler": {"contents: read"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			w, errs := Parse([]byte("on: push" + tc.input + "\n"))
+			if len(errs) > 0 {
+				t.Fatal(errs)
+			}
+
+			testOptionalCheck(t, "pull-requests-permission", SeverityWarning, tc.want, func(cfg *Config) []*Error {
+				cfg.Permissions.Actions = tc.actions
+				return visitWithRule(t, w, NewRulePermissions(), cfg)
+			})
+		})
+	}
+}
+
+func TestRulePermissionsMissingPermissions(t *testing.T) {
+	testCases := []struct {
+		what  string
+		input string
+		want  []string
+	}{
+		{
+			what: "omitted permissions",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo
+  lint:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo`,
+			want: []string{
+				`:1:1: "permissions" section is missing at top level of the workflow so the default permissions of GITHUB_TOKEN are granted to jobs "lint", "test"`,
+			},
+		},
+		{
+			what: "empty permissions",
+			input: `
+permissions: {}
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo`,
+		},
+		{
+			what: "populated permissions",
+			input: `
+permissions:
+  contents: read
+  pull-requests: write
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo`,
+		},
+		{
+			what: "scalar permissions",
+			input: `
+permissions: read-all
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo`,
+		},
+		{
+			what: "some jobs have their own permissions",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    permissions: {}
+    steps:
+      - run: echo
+  lint:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo`,
+			want: []string{
+				`are granted to jobs "lint". add "permissions" section with the least privileges`,
+			},
+		},
+		{
+			what: "all jobs have their own permissions",
+			input: `
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    permissions: {}
+    steps:
+      - run: echo
+  lint:
+    runs-on: ubuntu-latest
+    permissions:
+      contents: read
+    steps:
+      - run: echo`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			w, errs := Parse([]byte("on: push" + tc.input + "\n"))
+			if len(errs) > 0 {
+				t.Fatal(errs)
+			}
+
+			testOptionalCheck(t, "missing-permissions", SeverityWarning, tc.want, func(cfg *Config) []*Error {
+				return visitWithRule(t, w, NewRulePermissions(), cfg)
+			})
+		})
+	}
+}
@@ -0,0 +1,284 @@
+package actionlint
+
+import (
+	"regexp"
+	"strings"
+)
+
+// RuleRunScript is a rule to check scripts at "run:" of steps with heuristics. All checks of this
+// rule are optional since finding commands in scripts without shell parser may cause false
+// positives.
+type RuleRunScript struct {
+	RuleBase
+	// Repository name in "owner/name" format for "hardcoded-repository" optional check. Empty means
+	// the name is unknown.
+	repository   string
+	reRepository *regexp.Regexp
+}
+
+// NewRuleRunScript creates new RuleRunScript instance. The repo parameter is the name of the
+// repository in "owner/name" format. It can be empty when the repository is unknown.
+func NewRuleRunScript(repo string) *RuleRunScript {
+	var re *regexp.Regexp
+	if repo != "" {
+		re = regexp.MustCompile(`(?i)(?:^|[^\w.-])` + regexp.QuoteMeta(repo) + `(?:$|[^\w.-]|\.git\b|\.(?:$|[^\w-]))`)
+	}
+	return &RuleRunScript{
+		RuleBase:     RuleBase{name: "run-script"},
+		repository:   repo,
+		reRepository: re,
+	}
+}
+
+// VisitStep is callback when visiting Step node.
+func (rule *RuleRunScript) VisitStep(n *Step) error {
+	e, ok := n.Exec.(*ExecRun)
+	if !ok || e.Run == nil {
+		return nil
+	}
+
+	if rule.isCheckEnabled("frozen-lockfile") {
+		rule.checkFrozenLockfile(e.Run)
+	}
+	if rule.isCheckEnabled("hardcoded-repository") {
+		rule.checkHardcodedRepository(e.Run)
+	}
+	if rule.isCheckEnabled("multiline-output") {
+		rule.checkMultilineOutput(e.Run)
+	}
+	return nil
+}
+
+// checkHardcodedRepository checks the name of the repository is hard-coded in the script like
+// `gh release list --repo owner/name`. Such scripts break when the repository is forked or
+// renamed. This is an optional check enabled by "hardcoded-repository".
+func (rule *RuleRunScript) checkHardcodedRepository(run *String) {
+	if rule.reRepository == nil {
+		rule.debug("Skip checking hard-coded repository name at %s since the repository name is unknown", run.Pos)
+		return
+	}
+	for i, line := range strings.Split(run.Value, "\n") {
+		if j := strings.IndexByte(line, '#'); j >= 0 {
+			line = line[:j]
+		}
+		if !rule.reRepository.MatchString(line) {
+			continue
+		}
+		rule.warnf(
+			run.Pos,
+			"repository name %q is hard-coded at line %d of the script. it breaks when the repository is forked or renamed. use \"${{ github.repository }}\" or $GITHUB_REPOSITORY instead",
+			rule.repository,
+			i+1,
+		)
+	}
+}
+
+// checkFrozenLockfile checks commands to install dependencies of Node.js packages which may
+// update the lockfile. Such commands make builds on CI non-reproducible. This is an optional check
+// enabled by "frozen-lockfile".
+// https://docs.npmjs.com/cli/commands/npm-ci
+func (rule *RuleRunScript) checkFrozenLockfile(run *String) {
+	for i, line := range strings.Split(run.Value, "\n") {
+		if j := strings.IndexByte(line, '#'); j >= 0 {
+			line = line[:j]
+		}
+		for _, cmd := range reShellCommandSep.Split(line, -1) {
+			args := commandArgs(cmd)
+			alt := lockfileRespectingInstall(args)
+			if alt == "" {
+				continue
+			}
+			rule.warnf(
+				run.Pos,
+				"%q at line %d of the script may update the lockfile and make the build non-reproducible. use %q instead to install dependencies exactly as locked in the lockfile",
+				strings.Join(args, " "),
+				i+1,
+				alt,
+			)
+		}
+	}
+}
+
+// commandArgs splits the command into arguments. Variable assignments and "sudo" before the
+// command name are skipped.
+func commandArgs(cmd string) []string {
+	args := strings.Fields(cmd)
+	for len(args) > 0 && (args[0] == "sudo" || strings.Contains(args[0], "=")) {
+		args = args[1:]
+	}
+	return args
+}
+
+// lockfileRespectingInstall returns the command which installs dependencies without updating the
+// lockfile when the arguments run the package manager's install command which may update the
+// lockfile. Otherwise it returns an empty string. Commands to install specific packages like
+// `npm install -g typescript` are not target since they don't install dependencies of the project.
+func lockfileRespectingInstall(args []string) string {
+	if len(args) == 0 {
+		return ""
+	}
+
+	var sub string
+	opts := []string{}
+	for _, a := range args[1:] {
+		if strings.HasPrefix(a, "-") {
+			opts = append(opts, a)
+			continue
+		}
+		if sub != "" {
+			return "" // Package names are specified
+		}
+		sub = a
+	}
+
+	for _, o := range opts {
+		switch o {
+		case "-g", "--global", "--frozen-lockfile", "--immutable", "-h", "--help":
+			return ""
+		}
+	}
+
+	switch args[0] {
+	case "npm":
+		if sub == "install" || sub == "i" {
+			return "npm ci"
+		}
+	case "yarn":
+		// `yarn` without subcommand also installs dependencies
+		if sub == "install" || sub == "" && len(opts) == 0 {
+			return "yarn install --frozen-lockfile"
+		}
+	case "pnpm":
+		if sub == "install" || sub == "i" {
+			return "pnpm install --frozen-lockfile"
+		}
+	}
+	return ""
+}
+
+var (
+	reGitHubOutputWrite = regexp.MustCompile(`>>?\s*"?\$(?:GITHUB_OUTPUT\b|\{GITHUB_OUTPUT\})`)
+	reOutputAssignment  = regexp.MustCompile(`^(?:echo|printf)\s+(?:-[a-zA-Z]+\s+)*["']?([a-zA-Z_][a-zA-Z0-9_-]*)=(.*)$`)
+)
+
+// checkMultilineOutput checks outputs written to $GITHUB_OUTPUT in "name=value" form whose values
+// are command substitutions of commands which usually print multiple lines. Such values corrupt
+// the output file. Multiline values must be written with delimiter like "name<<EOF". This is an
+// optional check enabled by "multiline-output".
+func (rule *RuleRunScript) checkMultilineOutput(run *String) {
+	for i, line := range strings.Split(run.Value, "\n") {
+		if !reGitHubOutputWrite.MatchString(line) {
+			continue
+		}
+		m := reOutputAssignment.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			continue
+		}
+		for _, cmd := range commandSubstitutions(m[2]) {
+			if !isMultilineCommand(cmd) {
+				continue
+			}
+			rule.warnf(
+				run.Pos,
+				"output %q is set to the result of command %q at line %d of the script. the value may contain newlines and break $GITHUB_OUTPUT. use delimiter like `echo \"%s<<EOF\" >> $GITHUB_OUTPUT` for multiline values: https://docs.github.com/en/actions/using-workflows/workflow-commands-for-github-actions#multiline-strings",
+				m[1],
+				cmd,
+				i+1,
+				m[1],
+			)
+			break
+		}
+	}
+}
+
+// commandSubstitutions returns commands in $(...) and `...` command substitutions in the string.
+// Nested command substitutions are not extracted.
+func commandSubstitutions(s string) []string {
+	ret := []string{}
+	for {
+		i := strings.IndexAny(s, "$`")
+		if i < 0 || i == len(s)-1 {
+			return ret
+		}
+
+		if s[i] == '`' {
+			s = s[i+1:]
+			j := strings.IndexByte(s, '`')
+			if j < 0 {
+				return ret
+			}
+			ret = append(ret, strings.TrimSpace(s[:j]))
+			s = s[j+1:]
+			continue
+		}
+
+		s = s[i+1:]
+		if s[0] != '(' || strings.HasPrefix(s, "((") {
+			continue // Not a command substitution like $FOO or $((1 + 2))
+		}
+
+		depth := 0
+		end := -1
+	Loop:
+		for j, c := range s {
+			switch c {
+			case '(':
+				depth++
+			case ')':
+				depth--
+				if depth == 0 {
+					end = j
+					break Loop
+				}
+			}
+		}
+		if end < 0 {
+			return ret
+		}
+		ret = append(ret, strings.TrimSpace(s[1:end]))
+		s = s[end+1:]
+	}
+}
+
+var (
+	multilineCommands    = []string{"cat", "ls", "find", "grep", "sort", "uniq", "tree", "diff"}
+	multilineGitCommands = []string{"log", "diff", "show", "status", "ls-files", "branch", "tag"}
+	reSingleLineHeadTail = regexp.MustCompile(`\s-(?:n\s*|-lines=)?1(?:\s|$)`)
+)
+
+// isMultilineCommand returns if the command likely prints multiple lines. When the command is
+// a pipeline, the last command in it is checked since it prints the result.
+func isMultilineCommand(cmd string) bool {
+	if i := strings.LastIndexByte(cmd, '|'); i >= 0 {
+		cmd = cmd[i+1:]
+	}
+	args := strings.Fields(cmd)
+	if len(args) == 0 {
+		return false
+	}
+
+	switch args[0] {
+	case "git":
+		for _, a := range args[1:] {
+			if strings.HasPrefix(a, "-") {
+				continue
+			}
+			for _, c := range multilineGitCommands {
+				if a == c {
+					return true
+				}
+			}
+			return false
+		}
+		return false
+	case "head", "tail":
+		return !reSingleLineHeadTail.MatchString(cmd)
+	default:
+		for _, c := range multilineCommands {
+			if args[0] == c {
+				return true
+			}
+		}
+		return false
+	}
+}
@@ -0,0 +1,294 @@
+package actionlint
+
+import "testing"
+
+func TestRuleRunScriptFrozenLockfile(t *testing.T) {
+	testCases := []struct {
+		what string
+		run  string
+		want []string
+	}{
+		{
+			what: "npm install",
+			run:  "npm install",
+			want: []string{`"npm install" at line 1 of the script may update the lockfile and make the build non-reproducible. use "npm ci" instead`},
+		},
+		{
+			what: "npm i with options",
+			run:  "npm i --no-audit",
+			want: []string{`"npm i --no-audit" at line 1 of the script may update the lockfile and make the build non-reproducible. use "npm ci" instead`},
+		},
+		{
+			what: "yarn install",
+			run:  "yarn install",
+			want: []string{`"yarn install" at line 1 of the script may update the lockfile and make the build non-reproducible. use "yarn install --frozen-lockfile" instead`},
+		},
+		{
+			what: "yarn without subcommand",
+			run:  "yarn",
+			want: []string{`"yarn" at line 1 of the script may update the lockfile and make the build non-reproducible. use "yarn install --frozen-lockfile" instead`},
+		},
+		{
+			what: "pnpm install",
+			run:  "pnpm install",
+			want: []string{`"pnpm install" at line 1 of the script may update the lockfile and make the build non-reproducible. use "pnpm install --frozen-lockfile" instead`},
+		},
+		{
+			what: "pnpm i",
+			run:  "pnpm i",
+			want: []string{`"pnpm i" at line 1 of the script may update the lockfile and make the build non-reproducible. use "pnpm install --frozen-lockfile" instead`},
+		},
+		{
+			what: "multiple commands in multiple lines",
+			run:  "|\n          cd app && npm install\n          echo hello\n          CI=true sudo yarn install; yarn build",
+			want: []string{
+				`"npm install" at line 1 of the script`,
+				`"yarn install" at line 3 of the script`,
+			},
+		},
+		{
+			what: "npm ci",
+			run:  "npm ci",
+		},
+		{
+			what: "yarn with frozen lockfile",
+			run:  "yarn install --frozen-lockfile",
+		},
+		{
+			what: "yarn with immutable",
+			run:  "yarn install --immutable",
+		},
+		{
+			what: "pnpm with frozen lockfile",
+			run:  "pnpm install --frozen-lockfile",
+		},
+		{
+			what: "install specific package",
+			run:  "npm install typescript",
+		},
+		{
+			what: "install global package",
+			run:  "npm install -g",
+		},
+		{
+			what: "add package with yarn",
+			run:  "yarn add typescript",
+		},
+		{
+			what: "other yarn command",
+			run:  "yarn --version",
+		},
+		{
+			what: "comment",
+			run:  "echo hello # npm install",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			src := "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: " + tc.run + "\n"
+			w, errs := Parse([]byte(src))
+			if len(errs) > 0 {
+				t.Fatal(errs)
+			}
+
+			errs = testOptionalCheck(t, "frozen-lockfile", SeverityWarning, tc.want, func(cfg *Config) []*Error {
+				return visitWithRule(t, w, NewRuleRunScript(""), cfg)
+			})
+			for _, err := range errs {
+				if err.Line != 6 || err.Column != 14 {
+					t.Errorf("error should be reported at \"run:\" at line:6,col:14 but got %s", err)
+				}
+			}
+		})
+	}
+}
+
+func TestRuleRunScriptHardcodedRepository(t *testing.T) {
+	testCases := []struct {
+		what string
+		run  string
+		want []string
+	}{
+		{
+			what: "repository name as argument",
+			run:  "gh release view --repo rhysd/actionlint",
+			want: []string{`:6:14: repository name "rhysd/actionlint" is hard-coded at line 1 of the script`},
+		},
+		{
+			what: "repository URL",
+			run:  "git clone https://github.com/rhysd/actionlint.git",
+			want: []string{`repository name "rhysd/actionlint" is hard-coded at line 1`},
+		},
+		{
+			what: "repository name in different case",
+			run:  "gh pr list -R Rhysd/ActionLint",
+			want: []string{`repository name "rhysd/actionlint" is hard-coded at line 1`},
+		},
+		{
+			what: "multiple lines",
+			run:  "|\n          echo 'rhysd/actionlint'\n          echo hello\n          curl https://api.github.com/repos/rhysd/actionlint/releases",
+			want: []string{
+				`is hard-coded at line 1 of the script`,
+				`is hard-coded at line 3 of the script`,
+			},
+		},
+		{
+			what: "github.repository context",
+			run:  "gh release view --repo ${{ github.repository }}",
+		},
+		{
+			what: "other repository",
+			run:  "gh release view --repo rhysd/actionlint-action",
+		},
+		{
+			what: "other owner",
+			run:  "gh release view --repo not-rhysd/actionlint",
+		},
+		{
+			what: "repository name with other suffix",
+			run:  "gh release view --repo rhysd/actionlint.js",
+		},
+		{
+			what: "comment",
+			run:  "echo hello # from rhysd/actionlint",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.what, func(t *testing.T) {
+			src := "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: " + tc.run + "\n"
+			w, errs := Parse([]byte(src))
+			if len(errs) > 0 {
+				t.Fatal(errs)
+			}
+
+			testOptionalCheck(t, "hardcoded-repository", SeverityWarning, tc.want, func(cfg *Config) []*Error {
+				return visitWithRule(t, w, NewRuleRunScript("rhysd/actionlint"), cfg)
+			})
+		})
+	}
+}
+
+func TestRuleRunScriptHardcodedRepositoryUnknown(t *testing.T) {
+	src := "on: push\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: gh release view --repo rhysd/actionlint\n"
+	w, errs := Parse([]byte(src))
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+
+	cfg := &Config{EnableChecks: []string{"hardcoded-repository"}}
+	if errs := visitWithRule(t, w, NewRuleRunScript(""), cfg); len(errs) > 0 {
+		t.Fatalf("errors were reported though the repository name is unknown: %v", errs)
+	}
+}
+
+func TestRuleRunScriptMultilineOutput(t *testing.T) {
+	tests := []struct {
+		what string
+		run  string
+		want []string
+	}{
+		{
+			what: "cat file",
+			run:  `echo "content=$(cat file.txt)" >> $GITHUB_OUTPUT`,
+			want: []string{`output "content" is set to the result of command "cat file.txt" at line 1 of the script`},
+		},
+		{
+			what: "backquote",
+			run:  "echo \"files=`ls`\" >> \"$GITHUB_OUTPUT\"",
+			want: []string{`output "files" is set to the result of command "ls" at line 1`},
+		},
+		{
+			what: "braced variable",
+			run:  `echo "log=$(git log --oneline)" >> "${GITHUB_OUTPUT}"`,
+			want: []string{`output "log" is set to the result of command "git log --oneline"`},
+		},
+		{
+			what: "last command in pipeline",
+			run:  `echo "files=$(git diff --name-only | grep 'src/')" >> $GITHUB_OUTPUT`,
+			want: []string{`output "files" is set to the result of command "git diff --name-only | grep 'src/'"`},
+		},
+		{
+			what: "printf",
+			run:  `printf 'files=%s\n' "$(find . -name '*.json')" >> $GITHUB_OUTPUT`,
+			want: []string{`output "files" is set to the result of command "find . -name '*.json'"`},
+		},
+		{
+			what: "printf with value in format string",
+			run:  `printf "json=$(cat data.json)\n" >> $GITHUB_OUTPUT`,
+			want: []string{`output "json" is set to the result of command "cat data.json"`},
+		},
+		{
+			what: "line number in script",
+			run:  "set -e\ncd dir\necho \"tree=$(tree)\" >> $GITHUB_OUTPUT",
+			want: []string{`output "tree" is set to the result of command "tree" at line 3 of the script`},
+		},
+		{
+			what: "multiple outputs",
+			run:  "echo \"a=$(ls)\" >> $GITHUB_OUTPUT\necho \"b=$(cat b)\" >> $GITHUB_OUTPUT",
+			want: []string{`output "a"`, `output "b"`},
+		},
+		{
+			what: "single line command",
+			run:  `echo "sha=$(git rev-parse HEAD)" >> $GITHUB_OUTPUT`,
+			want: []string{},
+		},
+		{
+			what: "converted into single line",
+			run:  `echo "files=$(ls | tr '\n' ' ')" >> $GITHUB_OUTPUT`,
+			want: []string{},
+		},
+		{
+			what: "head one line",
+			run:  `echo "first=$(ls | head -n 1)" >> $GITHUB_OUTPUT`,
+			want: []string{},
+		},
+		{
+			what: "head multiple lines",
+			run:  `echo "first=$(ls | head -n 10)" >> $GITHUB_OUTPUT`,
+			want: []string{`output "first" is set to the result of command "ls | head -n 10"`},
+		},
+		{
+			what: "output of jq is not checked",
+			run:  `echo "json=$(jq -r .name data.json)" >> $GITHUB_OUTPUT`,
+			want: []string{},
+		},
+		{
+			what: "arithmetic expansion",
+			run:  `echo "n=$((1 + 2))" >> $GITHUB_OUTPUT`,
+			want: []string{},
+		},
+		{
+			what: "delimiter syntax",
+			run:  "echo 'content<<EOF' >> $GITHUB_OUTPUT\necho \"$(cat file.txt)\" >> $GITHUB_OUTPUT\necho 'EOF' >> $GITHUB_OUTPUT",
+			want: []string{},
+		},
+		{
+			what: "not written to output",
+			run:  `echo "content=$(cat file.txt)" >> $GITHUB_ENV`,
+			want: []string{},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.what, func(t *testing.T) {
+			s := &Step{
+				Exec: &ExecRun{
+					Run: &String{
+						Value: tc.run,
+						Pos:   &Pos{Line: 1, Col: 1},
+					},
+				},
+			}
+			testOptionalCheck(t, "multiline-output", SeverityWarning, tc.want, func(cfg *Config) []*Error {
+				r := NewRuleRunScript("")
+				r.SetConfig(cfg)
+				if err := r.VisitStep(s); err != nil {
+					t.Fatal(err)
+				}
+				return r.Errs()
+			})
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package actionlint
 
 import (
+	"fmt"
+	"path"
 	"strings"
 )
 
@@ -127,33 +129,35 @@ func (rule *RuleRunnerLabel) VisitJobPre(n *Job) error {
 // https://docs.github.com/en/actions/using-github-hosted-runners/about-github-hosted-runners
 func (rule *RuleRunnerLabel) checkLabelAndConflict(label *String, m *Matrix) {
 	if l := label.Value; strings.Contains(l, "${{") {
-		ls := rule.tryToGetLabelsInMatrix(l, m)
+		ls, axis := rule.tryToGetLabelsInMatrix(l, m)
 		cs := make([]runnerOSCompat, 0, len(ls))
 		for _, l := range ls {
-			comp := rule.verifyRunnerLabel(l)
+			comp := rule.verifyRunnerLabel(l, axis)
 			cs = append(cs, comp)
 		}
 		rule.checkCombiCompat(cs, ls)
 		return
 	}
 
-	comp := rule.verifyRunnerLabel(label)
+	comp := rule.verifyRunnerLabel(label, "")
 	rule.checkCompat(comp, label)
 }
 
 func (rule *RuleRunnerLabel) checkLabel(label *String, m *Matrix) {
 	if l := label.Value; strings.Contains(l, "${{") {
-		ls := rule.tryToGetLabelsInMatrix(l, m)
+		ls, axis := rule.tryToGetLabelsInMatrix(l, m)
 		for _, l := range ls {
-			rule.verifyRunnerLabel(l)
+			rule.verifyRunnerLabel(l, axis)
 		}
 		return
 	}
 
-	rule.verifyRunnerLabel(label)
+	rule.verifyRunnerLabel(label, "")
 }
 
-func (rule *RuleRunnerLabel) verifyRunnerLabel(label *String) runnerOSCompat {
+// verifyRunnerLabel verifies the label is known. The axis parameter is a name of matrix axis when
+// the label is a value of the matrix axis used at "runs-on:". Otherwise it is empty.
+func (rule *RuleRunnerLabel) verifyRunnerLabel(label *String, axis string) runnerOSCompat {
 	l := label.Value
 	if c, ok := defaultRunnerOSCompats[strings.ToLower(l)]; ok {
 		return c
@@ -166,15 +170,33 @@ func (rule *RuleRunnerLabel) verifyRunnerLabel(label *String) runnerOSCompat {
 	}
 
 	for _, k := range rule.knownLabels {
+		// Configured labels can be glob patterns like "gpu-*". Labels are case-insensitive
 		if strings.EqualFold(l, k) {
 			return compatInvalid
 		}
+		if ok, _ := path.Match(strings.ToLower(k), strings.ToLower(l)); ok {
+			return compatInvalid
+		}
+	}
+
+	var note string
+	if axis != "" {
+		note = fmt.Sprintf(" the label is a value of matrix %q used at \"runs-on:\".", axis)
+	}
+	cands := make([]string, 0, len(allGitHubHostedRunnerLabels)+len(selfHostedRunnerPresetOtherLabels)+len(selfHostedRunnerPresetOSLabels)+len(rule.knownLabels))
+	cands = append(cands, allGitHubHostedRunnerLabels...)
+	cands = append(cands, selfHostedRunnerPresetOtherLabels...)
+	cands = append(cands, selfHostedRunnerPresetOSLabels...)
+	cands = append(cands, rule.knownLabels...)
+	if ss := findSimilarStrings(l, cands); len(ss) > 0 {
+		note += fmt.Sprintf(" did you mean %s?", quotes(ss))
 	}
 
 	rule.errorf(
 		label.Pos,
-		"label %q is unknown. available labels are %s. if it is a custom label for self-hosted runner, set list of labels in actionlint.yaml config file",
+		"label %q is unknown.%s available labels are %s. if it is a custom label for self-hosted runner, set list of labels in actionlint.yaml config file",
 		label.Value,
+		note,
 		quotesAll(
 			allGitHubHostedRunnerLabels,
 			selfHostedRunnerPresetOtherLabels,
@@ -186,33 +208,35 @@ func (rule *RuleRunnerLabel) verifyRunnerLabel(label *String) runnerOSCompat {
 	return compatInvalid
 }
 
-func (rule *RuleRunnerLabel) tryToGetLabelsInMatrix(l string, m *Matrix) []*String {
+// tryToGetLabelsInMatrix returns literal values of the matrix axis used in the "${{ matrix.xxx }}"
+// label and the name of the axis.
+func (rule *RuleRunnerLabel) tryToGetLabelsInMatrix(l string, m *Matrix) ([]*String, string) {
 	if m == nil {
-		return nil
+		return nil, ""
 	}
 	l = strings.TrimSpace(l)
 
 	// Only when the form of "${{...}}", evaluate the expression
 	if strings.Count(l, "${{") != 1 || !strings.HasPrefix(l, "${{") || !strings.HasSuffix(l, "}}") {
-		return nil
+		return nil, ""
 	}
 
 	p := NewExprParser()
 	expr, err := p.Parse(NewExprLexer(l[3:])) // 3 means omit first "${{"
 	if err != nil {
-		return nil
+		return nil, ""
 	}
 
 	deref, ok := expr.(*ObjectDerefNode)
 	if !ok {
-		return nil
+		return nil, ""
 	}
 	recv, ok := deref.Receiver.(*VariableNode)
 	if !ok {
-		return nil
+		return nil, ""
 	}
 	if recv.Name != "matrix" {
-		return nil
+		return nil, ""
 	}
 
 	prop := deref.Property
@@ -242,7 +266,7 @@ func (rule *RuleRunnerLabel) tryToGetLabelsInMatrix(l string, m *Matrix) []*Stri
 		}
 	}
 
-	return labels
+	return labels, prop
 }
 
 func (rule *RuleRunnerLabel) checkConflict(comp runnerOSCompat, label *String) bool {
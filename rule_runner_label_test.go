@@ -63,6 +63,27 @@ func TestRuleRunnerLabelCheckLabels(t *testing.T) {
 			labels: []string{"self-hosted", "foo", "bar", "linux"},
 			known:  []string{"foo", "bar"},
 		},
+		{
+			what:   "user-defined label pattern with *",
+			labels: []string{"self-hosted", "gpu-a100", "linux"},
+			known:  []string{"gpu-*"},
+		},
+		{
+			what:   "user-defined label pattern with ?",
+			labels: []string{"self-hosted", "linux-x2"},
+			known:  []string{"linux-x?"},
+		},
+		{
+			what:   "user-defined label pattern ignores case",
+			labels: []string{"self-hosted", "GPU-A100"},
+			known:  []string{"gpu-*"},
+		},
+		{
+			what:   "user-defined label pattern with matrix",
+			labels: []string{"self-hosted", "${{matrix.os}}"},
+			matrix: []string{"gpu-a100", "gpu-v100"},
+			known:  []string{"gpu-*"},
+		},
 		{
 			what:   "matrix",
 			labels: []string{"${{matrix.os}}"},
@@ -166,6 +187,24 @@ func TestRuleRunnerLabelCheckLabels(t *testing.T) {
 			known:  []string{"foo"},
 			errs:   []string{`label "windows-latest" conflicts with label "ubuntu-latest"`},
 		},
+		{
+			what:   "user-defined label pattern does not match",
+			labels: []string{"self-hosted", "tpu-v4"},
+			known:  []string{"gpu-*"},
+			errs:   []string{`"tpu-v4" is unknown`},
+		},
+		{
+			what:   "user-defined label pattern with ? matches only one character",
+			labels: []string{"self-hosted", "linux-x16"},
+			known:  []string{"linux-x?"},
+			errs:   []string{`"linux-x16" is unknown`},
+		},
+		{
+			what:   "user-defined literal label matches exactly",
+			labels: []string{"self-hosted", "foo-bar"},
+			known:  []string{"foo"},
+			errs:   []string{`"foo-bar" is unknown`},
+		},
 		{
 			what:   "GH-hosted labels conflict ignore case",
 			labels: []string{"macOS-latest", "Windows-latest"},
@@ -195,6 +234,29 @@ func TestRuleRunnerLabelCheckLabels(t *testing.T) {
 				`label "macos-latest" conflicts with label "windows-latest"`,
 			},
 		},
+		{
+			what:   "typo in matrix value",
+			labels: []string{"${{matrix.os}}"},
+			matrix: []string{"ubuntu-latest", "ubutu-latest"},
+			errs:   []string{`label "ubutu-latest" is unknown. the label is a value of matrix "os" used at "runs-on:". did you mean "ubuntu-latest"?`},
+		},
+		{
+			what:   "typo in matrix value at second label",
+			labels: []string{"self-hosted", "${{matrix.os}}"},
+			matrix: []string{"linux", "windos"},
+			errs:   []string{`label "windos" is unknown. the label is a value of matrix "os" used at "runs-on:". did you mean "windows"?`},
+		},
+		{
+			what:   "typo in label",
+			labels: []string{"macos-lastest"},
+			errs:   []string{`label "macos-lastest" is unknown. did you mean "macos-latest"?`},
+		},
+		{
+			what:   "typo in user-defined label",
+			labels: []string{"self-hosted", "my-runer"},
+			known:  []string{"my-runner"},
+			errs:   []string{`label "my-runer" is unknown. did you mean "my-runner"?`},
+		},
 		// TODO: Add error tests for 'include:'
 	}
 
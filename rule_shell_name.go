@@ -1,6 +1,7 @@
 package actionlint
 
 import (
+	"regexp"
 	"strings"
 )
 
@@ -12,8 +13,10 @@ const (
 	platformKindWindows
 )
 
-// RuleShellName is a rule to check 'shell' field. For more details, see
+// RuleShellName is a rule to check 'shell' field and 'working-directory' field of 'defaults.run'
+// section. For more details, see
 // https://docs.github.com/en/actions/learn-github-actions/workflow-syntax-for-github-actions#using-a-specific-shell
+// https://docs.github.com/en/actions/using-workflows/workflow-syntax-for-github-actions#defaultsrun
 type RuleShellName struct {
 	RuleBase
 	platform platformKind
@@ -43,6 +46,7 @@ func (rule *RuleShellName) VisitJobPre(n *Job) error {
 	rule.platform = rule.getPlatformFromRunner(n.RunsOn)
 	if n.Defaults != nil && n.Defaults.Run != nil {
 		rule.checkShellName(n.Defaults.Run.Shell)
+		rule.checkWorkingDirectory(n.Defaults.Run.WorkingDirectory)
 	}
 	return nil
 }
@@ -57,6 +61,7 @@ func (rule *RuleShellName) VisitJobPost(n *Job) error {
 func (rule *RuleShellName) VisitWorkflowPre(n *Workflow) error {
 	if n.Defaults != nil && n.Defaults.Run != nil {
 		rule.checkShellName(n.Defaults.Run.Shell)
+		rule.checkWorkingDirectory(n.Defaults.Run.WorkingDirectory)
 	}
 	return nil
 }
@@ -111,6 +116,59 @@ func (rule *RuleShellName) checkShellName(node *String) {
 	)
 }
 
+var (
+	reWorkingDirEnvVar     = regexp.MustCompile(`\$\{?[A-Za-z_][A-Za-z0-9_]*\}?|%[A-Za-z_][A-Za-z0-9_]*%`)
+	reWorkingDirWindowsAbs = regexp.MustCompile(`^[A-Za-z]:[\\/]`)
+)
+
+// checkWorkingDirectory checks 'working-directory' at 'defaults.run'. The path is not processed by
+// shell so "~" and environment variables in it are not expanded. And absolute paths for other
+// platforms don't exist on the runner.
+func (rule *RuleShellName) checkWorkingDirectory(node *String) {
+	if node == nil || strings.Contains(node.Value, "${{") {
+		return
+	}
+	p := node.Value
+
+	if p == "~" || strings.HasPrefix(p, "~/") || strings.HasPrefix(p, "~\\") {
+		rule.warnf(
+			node.Pos,
+			"\"~\" in working-directory %q is not expanded to home directory since the path is not processed by shell. the directory will not exist on the runner. use a path relative to the workspace instead",
+			p,
+		)
+		return
+	}
+
+	if v := reWorkingDirEnvVar.FindString(p); v != "" {
+		rule.warnf(
+			node.Pos,
+			"environment variable %q in working-directory %q is not expanded since the path is not processed by shell. the directory will not exist on the runner. use a path relative to the workspace instead",
+			v,
+			p,
+		)
+		return
+	}
+
+	switch rule.platform {
+	case platformKindMacOrLinux:
+		if reWorkingDirWindowsAbs.MatchString(p) {
+			rule.warnf(
+				node.Pos,
+				"working-directory %q is an absolute path on Windows but the job runs on macOS or Linux. the directory will not exist on the runner",
+				p,
+			)
+		}
+	case platformKindWindows:
+		if strings.HasPrefix(p, "/") {
+			rule.warnf(
+				node.Pos,
+				"working-directory %q is an absolute path on macOS or Linux but the job runs on Windows. the directory will not exist on the runner",
+				p,
+			)
+		}
+	}
+}
+
 func getAvailableShellNames(kind platformKind) []string {
 	// https://docs.github.com/en/actions/learn-github-actions/workflow-syntax-for-github-actions#using-a-specific-shell
 	switch kind {
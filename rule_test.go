@@ -0,0 +1,70 @@
+package actionlint
+
+import (
+	"strings"
+	"testing"
+)
+
+// lintOptionalCheck runs the lint function with the optional check disabled and then enabled, and
+// returns errors reported only while the check is enabled in order of their positions. Errors not
+// related to the check must be reported regardless of the config.
+func lintOptionalCheck(t *testing.T, check string, lint func(cfg *Config) []*Error) []*Error {
+	t.Helper()
+
+	others := map[string]int{}
+	for _, err := range lint(&Config{}) {
+		others[err.Error()]++
+	}
+
+	errs := []*Error{}
+	for _, err := range lint(&Config{EnableChecks: []string{check}}) {
+		if msg := err.Error(); others[msg] > 0 {
+			others[msg]--
+		} else {
+			errs = append(errs, err)
+		}
+	}
+	for msg, n := range others {
+		if n > 0 {
+			t.Fatalf("error was reported only while %q check was disabled: %s", check, msg)
+		}
+	}
+
+	SortErrors(errs)
+	return errs
+}
+
+// testOptionalCheck checks that the errors reported by the optional check contain the wanted
+// messages in order and have the given severity. The reported errors are returned for further
+// checks.
+func testOptionalCheck(t *testing.T, check string, sev Severity, want []string, lint func(cfg *Config) []*Error) []*Error {
+	t.Helper()
+
+	errs := lintOptionalCheck(t, check, lint)
+	if len(errs) != len(want) {
+		t.Fatalf("wanted %d errors but got %d errors: %v", len(want), len(errs), errs)
+	}
+	for i, err := range errs {
+		if msg := err.Error(); !strings.Contains(msg, want[i]) {
+			t.Errorf("error %q does not contain %q", msg, want[i])
+		}
+		if err.Severity != sev {
+			t.Errorf("severity of error should be %s but got %s: %s", sev, err.Severity, err)
+		}
+	}
+	return errs
+}
+
+// visitWithRule visits the workflow with the rule configured by the config and returns errors
+// reported by the rule.
+func visitWithRule(t *testing.T, w *Workflow, r Rule, cfg *Config) []*Error {
+	t.Helper()
+
+	r.SetConfig(cfg)
+	v := NewVisitor()
+	v.AddPass(r)
+	if err := v.Visit(w); err != nil {
+		t.Fatal(err)
+	}
+	return r.Errs()
+}
@@ -2,6 +2,7 @@ package actionlint
 
 import (
 	"fmt"
+	"path"
 	"strings"
 )
 
@@ -53,16 +54,22 @@ func (rule *RuleWorkflowCall) VisitJobPre(n *Job) error {
 		return nil
 	}
 
-	if isWorkflowCallUsesLocalFormat(u.Value) {
-		rule.checkWorkflowCallUsesLocal(n.WorkflowCall)
-		return nil
+	var reason string
+	local := strings.HasPrefix(u.Value, "./")
+	if local {
+		reason = checkWorkflowCallUsesLocalFormat(u.Value)
+	} else {
+		reason = checkWorkflowCallUsesRepoFormat(u.Value)
 	}
 
-	if isWorkflowCallUsesRepoFormat(u.Value) {
+	if reason == "" {
+		if local {
+			rule.checkWorkflowCallUsesLocal(n.WorkflowCall)
+		}
 		return nil
 	}
 
-	if strings.HasPrefix(u.Value, "./") {
+	if local {
 		// When the specification is invalid and it is local reusable workflow call, remember it caused
 		// an error by setting `nil` to cache. This can prevent redundant 'could not read workflow call'
 		// error.
@@ -71,8 +78,9 @@ func (rule *RuleWorkflowCall) VisitJobPre(n *Job) error {
 
 	rule.errorf(
 		u.Pos,
-		"reusable workflow call %q at \"uses\" is not following the format \"owner/repo/path/to/workflow.yml@ref\" nor \"./path/to/workflow.yml\". see https://docs.github.com/en/actions/learn-github-actions/reusing-workflows for more details",
+		"reusable workflow call %q at \"uses\" is not following the format \"owner/repo/path/to/workflow.yml@ref\" nor \"./path/to/workflow.yml\": %s. see https://docs.github.com/en/actions/learn-github-actions/reusing-workflows for more details",
 		u.Value,
+		reason,
 	)
 	return nil
 }
@@ -146,48 +154,74 @@ func (rule *RuleWorkflowCall) checkWorkflowCallUsesLocal(call *WorkflowCall) {
 	rule.debug("Validated reusable workflow %q", u.Value)
 }
 
-// Parse ./{path/{filename}
+// checkWorkflowCallUsesLocalFormat checks ./{path/{filename} and returns the reason why the format
+// is invalid. An empty string is returned when it is valid.
 // https://docs.github.com/en/actions/learn-github-actions/reusing-workflows#calling-a-reusable-workflow
-func isWorkflowCallUsesLocalFormat(u string) bool {
-	if !strings.HasPrefix(u, "./") {
-		return false
+func checkWorkflowCallUsesLocalFormat(u string) string {
+	p := strings.TrimPrefix(u, "./")
+
+	// Cannot contain a ref
+	if idx := strings.IndexRune(p, '@'); idx >= 0 {
+		return fmt.Sprintf("ref %q cannot be specified for local reusable workflow", p[idx+1:])
 	}
-	u = strings.TrimPrefix(u, "./")
 
-	// Cannot container a ref
-	idx := strings.IndexRune(u, '@')
-	if idx > 0 {
-		return false
+	if p == "" {
+		return "path to workflow file is empty"
 	}
 
-	return len(u) > 0
+	return checkWorkflowCallFileExt(p)
 }
 
-// Parse {owner}/{repo}/{path to workflow.yml}@{ref}
+// checkWorkflowCallUsesRepoFormat checks {owner}/{repo}/{path to workflow.yml}@{ref} and returns the
+// reason why the format is invalid. An empty string is returned when it is valid.
 // https://docs.github.com/en/actions/learn-github-actions/reusing-workflows#calling-a-reusable-workflow
-func isWorkflowCallUsesRepoFormat(u string) bool {
+func checkWorkflowCallUsesRepoFormat(u string) string {
 	// Repo reference must start with owner
+	if strings.HasPrefix(u, "/") {
+		return "absolute path is not allowed. path to local workflow file must start with \"./\""
+	}
 	if strings.HasPrefix(u, ".") {
-		return false
+		return "path to local workflow file must start with \"./\""
 	}
 
-	idx := strings.IndexRune(u, '/')
-	if idx <= 0 {
-		return false
+	p, ref, hasRef := u, "", false
+	if idx := strings.IndexRune(u, '@'); idx >= 0 {
+		p, ref, hasRef = u[:idx], u[idx+1:], true // Note: ref can contain '@' and '/'
 	}
-	u = u[idx+1:] // Eat owner
 
-	idx = strings.IndexRune(u, '/')
-	if idx <= 0 {
-		return false
+	ss := strings.SplitN(p, "/", 3)
+	if len(ss) < 3 {
+		if checkWorkflowCallFileExt(ss[len(ss)-1]) == "" {
+			return "owner or repository name is missing before path to workflow file"
+		}
+		return "path to workflow file is missing after \"owner/repo\""
+	}
+	if ss[0] == "" {
+		return "owner is empty"
+	}
+	if ss[1] == "" {
+		return "repository name is empty"
+	}
+	if ss[2] == "" {
+		return "path to workflow file is empty"
+	}
+	if r := checkWorkflowCallFileExt(ss[2]); r != "" {
+		return r
 	}
-	u = u[idx+1:] // Eat repo
 
-	idx = strings.IndexRune(u, '@')
-	if idx <= 0 {
-		return false
+	if !hasRef {
+		return "ref is missing. branch name, tag, or commit SHA must be specified after \"@\" like \"owner/repo/path/to/workflow.yml@main\""
+	}
+	if ref == "" {
+		return "ref after \"@\" is empty"
 	}
-	u = u[idx+1:] // Eat workflow path
 
-	return len(u) > 0
+	return ""
+}
+
+func checkWorkflowCallFileExt(p string) string {
+	if strings.HasSuffix(p, ".yml") || strings.HasSuffix(p, ".yaml") {
+		return ""
+	}
+	return fmt.Sprintf("file name %q of workflow must end with \".yml\" or \".yaml\"", path.Base(p))
 }
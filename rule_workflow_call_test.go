@@ -13,24 +13,35 @@ import (
 func TestRuleWorkflowCallCheckWorkflowCallUsesFormat(t *testing.T) {
 	tests := []struct {
 		uses string
-		ok   bool
+		want string // Empty string means no error
 	}{
-		{"owner/repo/x.yml@ref", true},
-		{"owner/repo/x.yml@@", true},
-		{"owner/repo/x.yml@release/v1", true},
-		{"./path/to/x.yml", true},
-		{"${{ env.FOO }}", true},
-		{"./path/to/x.yml@ref", false},
-		{"/path/to/x.yml@ref", false},
-		{"./", false},
-		{".", false},
-		{"owner/x.yml@ref", false},
-		{"owner/repo@ref", false},
-		{"owner/repo/x.yml", false},
-		{"/repo/x.yml@ref", false},
-		{"owner//x.yml@ref", false},
-		{"owner/repo/@ref", false},
-		{"owner/repo/x.yml@", false},
+		{"owner/repo/x.yml@ref", ""},
+		{"owner/repo/x.yaml@ref", ""},
+		{"owner/repo/x.yml@@", ""},
+		{"owner/repo/x.yml@release/v1", ""},
+		{"owner/repo/path/to/x.yml@ref", ""},
+		{"./path/to/x.yml", ""},
+		{"./path/to/x.yaml", ""},
+		{"${{ env.FOO }}", ""},
+		{"./path/to/x.yml@ref", "ref \"ref\" cannot be specified for local reusable workflow"},
+		{"./@ref", "ref \"ref\" cannot be specified for local reusable workflow"},
+		{"./path/to/x", "file name \"x\" of workflow must end with \".yml\" or \".yaml\""},
+		{"./path/to/x.json", "file name \"x.json\" of workflow must end with \".yml\" or \".yaml\""},
+		{"/path/to/x.yml@ref", "absolute path is not allowed"},
+		{"../path/to/x.yml", "path to local workflow file must start with \"./\""},
+		{"./", "path to workflow file is empty"},
+		{".", "path to local workflow file must start with \"./\""},
+		{"owner/x.yml@ref", "owner or repository name is missing before path to workflow file"},
+		{"x.yml@ref", "owner or repository name is missing before path to workflow file"},
+		{"owner/repo@ref", "path to workflow file is missing after \"owner/repo\""},
+		{"owner/repo/x.yml", "ref is missing"},
+		{"owner/repo/path/to/x.yml", "ref is missing"},
+		{"/repo/x.yml@ref", "absolute path is not allowed"},
+		{"owner//x.yml@ref", "repository name is empty"},
+		{"owner/repo/@ref", "path to workflow file is empty"},
+		{"owner/repo/x.yml@", "ref after \"@\" is empty"},
+		{"owner/repo/x@ref", "file name \"x\" of workflow must end with \".yml\" or \".yaml\""},
+		{"owner/repo/x.ymll@ref", "file name \"x.ymll\" of workflow must end with \".yml\" or \".yaml\""},
 	}
 
 	for _, tc := range tests {
@@ -50,13 +61,17 @@ func TestRuleWorkflowCallCheckWorkflowCallUsesFormat(t *testing.T) {
 				t.Fatal(err)
 			}
 			errs := r.Errs()
-			if tc.ok && len(errs) > 0 {
-				t.Fatalf("Error occurred: %v", errs)
-			}
-			if !tc.ok {
-				if len(errs) > 2 || len(errs) == 0 {
-					t.Fatalf("Wanted one error but have: %v", errs)
+			if tc.want == "" {
+				if len(errs) > 0 {
+					t.Fatalf("Error occurred: %v", errs)
 				}
+				return
+			}
+			if len(errs) != 1 {
+				t.Fatalf("Wanted one error but have: %v", errs)
+			}
+			if !strings.Contains(errs[0].Message, tc.want) {
+				t.Fatalf("Error message %q does not contain %q", errs[0].Message, tc.want)
 			}
 		})
 	}
@@ -262,6 +277,13 @@ func TestRuleWorkflowCallCheckReusableWorkflowCall(t *testing.T) {
 			inputs:  []string{"input2"},
 			secrets: []string{"secret2"},
 		},
+		{
+			what: "typo in file extension",
+			uses: "./ok.yml", // ok.yaml is defined in testdata/reusable_workflow_metadata/ok.yaml
+			errs: []string{
+				"did you mean \"./ok.yaml\"?",
+			},
+		},
 		{
 			what: "read broken workflow",
 			uses: "./broken.yaml", // Defined in testdata/reusable_workflow_metadata/broken.yaml
@@ -271,11 +293,11 @@ func TestRuleWorkflowCallCheckReusableWorkflowCall(t *testing.T) {
 		},
 		{
 			what: "external workflow call with no input and no secret",
-			uses: "owner/repo/path/to/workflow@main",
+			uses: "owner/repo/path/to/workflow.yml@main",
 		},
 		{
 			what:    "external workflow call with inputs and secrets",
-			uses:    "owner/repo/path/to/workflow@main",
+			uses:    "owner/repo/path/to/workflow.yml@main",
 			inputs:  []string{"aaa", "bbb"},
 			secrets: []string{"xxx", "yyy"},
 		},
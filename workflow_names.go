@@ -0,0 +1,73 @@
+package actionlint
+
+import "sync"
+
+type workflowNameEntry struct {
+	path    string
+	name    *String
+	project *Project
+	cfg     *Config
+}
+
+// workflowNames collects names of workflows across multiple files to detect workflows sharing the
+// same name. Such workflows cannot be distinguished in Actions UI. This is used for
+// "duplicate-workflow-name" optional check. Methods of this struct are thread-safe.
+type workflowNames struct {
+	mu      sync.Mutex
+	entries []*workflowNameEntry
+}
+
+func (ns *workflowNames) add(path string, name *String, project *Project, cfg *Config) {
+	ns.mu.Lock()
+	ns.entries = append(ns.entries, &workflowNameEntry{path, name, project, cfg})
+	ns.mu.Unlock()
+}
+
+type workflowNameErrors struct {
+	errs []*Error
+	cfg  *Config
+}
+
+// duplicateErrors returns errors for workflows whose names are duplicated in the same project.
+// Keys of the returned map are file paths.
+func (ns *workflowNames) duplicateErrors() map[string]*workflowNameErrors {
+	ns.mu.Lock()
+	defer ns.mu.Unlock()
+
+	type key struct {
+		project *Project
+		name    string
+	}
+	groups := map[key][]*workflowNameEntry{}
+	for _, e := range ns.entries {
+		k := key{e.project, e.name.Value}
+		groups[k] = append(groups[k], e)
+	}
+
+	ret := map[string]*workflowNameErrors{}
+	for _, es := range groups {
+		if len(es) < 2 {
+			continue
+		}
+		for _, e := range es {
+			others := make([]string, 0, len(es)-1)
+			for _, o := range es {
+				if o != e {
+					others = append(others, o.path)
+				}
+			}
+
+			err := errorfAt(
+				e.name.Pos,
+				"workflow-name",
+				"workflow name %q is duplicated. the same name is used in %s. workflows with the same name cannot be distinguished in Actions UI",
+				e.name.Value,
+				sortedQuotes(others),
+			)
+			err.Severity = SeverityWarning
+			err.Filepath = e.path
+			ret[e.path] = &workflowNameErrors{[]*Error{err}, e.cfg}
+		}
+	}
+	return ret
+}